@@ -4,20 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"ado-reviewer/.github/tools/skills-go/internal/advisories"
 	"ado-reviewer/.github/tools/skills-go/internal/commits"
 	"ado-reviewer/.github/tools/skills-go/internal/deprecated"
 	"ado-reviewer/.github/tools/skills-go/internal/diffmapper"
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
 	"ado-reviewer/.github/tools/skills-go/internal/files"
 	"ado-reviewer/.github/tools/skills-go/internal/iterations"
 	"ado-reviewer/.github/tools/skills-go/internal/projects"
 	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
 	"ado-reviewer/.github/tools/skills-go/internal/repositories"
 	"ado-reviewer/.github/tools/skills-go/internal/reviews"
+	"ado-reviewer/.github/tools/skills-go/internal/sarif"
+	"ado-reviewer/.github/tools/skills-go/internal/search"
+	"ado-reviewer/.github/tools/skills-go/internal/symboldiff"
 )
 
 const usageGetCommitDiffs = "usage: skills-go get-commit-diffs <organization> <project> <repositoryId> <baseVersion> <targetVersion> [baseVersionType] [targetVersionType]"
@@ -59,10 +65,32 @@ func main() {
 		handleGetPRChangedFiles(os.Args[2:])
 	case "get-pr-threads":
 		handleGetPRThreads(os.Args[2:])
+	case "get-pr-discussion-digest":
+		handleGetPRDiscussionDigest(os.Args[2:])
+	case "get-my-pending-threads":
+		handleGetMyPendingThreads(os.Args[2:])
+	case "export-pr-threads":
+		handleExportPRThreads(os.Args[2:])
 	case "post-pr-comment":
 		handlePostPRComment(os.Args[2:])
+	case "post-pr-suggestion":
+		handlePostPRSuggestion(os.Args[2:])
+	case "submit-review":
+		handleSubmitReview(os.Args[2:])
+	case "import-sarif":
+		handleImportSarif(os.Args[2:])
 	case "update-pr-thread":
 		handleUpdatePRThread(os.Args[2:])
+	case "reconcile-threads":
+		handleReconcileThreads(os.Args[2:])
+	case "edit-pr-comment":
+		handleEditPRComment(os.Args[2:])
+	case "delete-pr-comment":
+		handleDeletePRComment(os.Args[2:])
+	case "list-files":
+		handleListFiles(os.Args[2:])
+	case "search-code":
+		handleSearchCode(os.Args[2:])
 	case "get-multiple-files":
 		handleGetMultipleFiles(os.Args[2:])
 	case "get-github-advisories":
@@ -71,6 +99,8 @@ func main() {
 		handleGetPRDependencyAdvisories(os.Args[2:])
 	case "get-pr-diff-line-mapper":
 		handleGetPRDiffLineMapper(os.Args[2:])
+	case "get-pr-changed-symbols":
+		handleGetPRChangedSymbols(os.Args[2:])
 	case "get-pr-review-bundle":
 		handleGetPRReviewBundle(os.Args[2:])
 	default:
@@ -110,7 +140,7 @@ func handleGetCommitDiffs(args []string) {
 
 func handleGetFileContent(args []string) {
 	if len(args) < 4 {
-		fatalf("usage: skills-go get-file-content <organization> <project> <repositoryId> <path> [version] [versionType]")
+		fatalf("usage: skills-go get-file-content <organization> <project> <repositoryId> <path> [version] [versionType] [maxBytes] [lines]")
 	}
 	version := ""
 	versionType := "branch"
@@ -120,7 +150,23 @@ func handleGetFileContent(args []string) {
 	if len(args) >= 6 {
 		versionType = args[5]
 	}
-	result, err := files.GetContent(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), args[3], version, versionType)
+	maxBytes := files.DefaultMaxContentBytes
+	if len(args) >= 7 {
+		parsed, err := parseMaxContentBytes(args[6])
+		if err != nil {
+			fatalErr(err)
+		}
+		maxBytes = parsed
+	}
+	var ranges []files.LineRange
+	if len(args) >= 8 {
+		parsed, err := files.ParseLineRanges(args[7])
+		if err != nil {
+			fatalErr(err)
+		}
+		ranges = parsed
+	}
+	result, err := files.GetContentRanges(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), args[3], version, versionType, ranges, maxBytes)
 	if err != nil {
 		fatalErr(err)
 	}
@@ -150,68 +196,819 @@ func handleGetPRChangedFiles(args []string) {
 	printJSON(result)
 }
 
+const usageGetPRThreads = "usage: skills-go get-pr-threads <organization> <project> <repositoryId> <pullRequestId> [statusFilter] [excludeSystem] [author] [pathGlob] [iteration] [createdSince] [updatedSince] [hasReplies] [lastCommentBy] [compact] [classification]"
+
 func handleGetPRThreads(args []string) {
+	filter, err := parseThreadFilter(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := pullrequests.GetFilteredThreads(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), filter)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func parseThreadFilter(args []string) (pullrequests.ThreadFilter, error) {
 	if len(args) < 4 {
-		fatalf("usage: skills-go get-pr-threads <organization> <project> <repositoryId> <pullRequestId> [statusFilter] [excludeSystem]")
+		return pullrequests.ThreadFilter{}, fmt.Errorf(usageGetPRThreads)
 	}
-	statusFilter := ""
-	excludeSystem := false
+	optional := func(index int) string {
+		if len(args) > index {
+			if value := strings.TrimSpace(args[index]); value != "-" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	filter := pullrequests.ThreadFilter{
+		Status:        optional(4),
+		ExcludeSystem: strings.EqualFold(optional(5), "true"),
+		Author:        optional(6),
+		PathGlob:      optional(7),
+		Compact:       strings.EqualFold(optional(13), "true"),
+	}
+	if value := optional(8); value != "" {
+		iteration, err := strconv.Atoi(value)
+		if err != nil || iteration < 1 {
+			return pullrequests.ThreadFilter{}, fmt.Errorf("iteration must be a positive integer")
+		}
+		filter.Iteration = iteration
+	}
+	createdSince, err := pullrequests.ParseThreadTime(optional(9))
+	if err != nil {
+		return pullrequests.ThreadFilter{}, fmt.Errorf("createdSince: %w", err)
+	}
+	filter.CreatedSince = createdSince
+	updatedSince, err := pullrequests.ParseThreadTime(optional(10))
+	if err != nil {
+		return pullrequests.ThreadFilter{}, fmt.Errorf("updatedSince: %w", err)
+	}
+	filter.UpdatedSince = updatedSince
+	if value := optional(11); value != "" {
+		hasReplies, err := strconv.ParseBool(value)
+		if err != nil {
+			return pullrequests.ThreadFilter{}, fmt.Errorf("hasReplies must be true or false")
+		}
+		filter.HasReplies = &hasReplies
+	}
+	lastCommentBy, err := pullrequests.NormalizeLastCommentBy(optional(12))
+	if err != nil {
+		return pullrequests.ThreadFilter{}, err
+	}
+	filter.LastCommentBy = lastCommentBy
+	classes, err := pullrequests.ParseThreadClasses(optional(14))
+	if err != nil {
+		return pullrequests.ThreadFilter{}, err
+	}
+	filter.Classes = classes
+	return filter, nil
+}
+
+const usageGetPRDiscussionDigest = "usage: skills-go get-pr-discussion-digest <organization> <project> <repositoryId> <pullRequestId> [format] [excludeSystem] [maxCommentChars]"
+
+func handleGetPRDiscussionDigest(args []string) {
+	format, options, err := parseDigestOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	digest, err := pullrequests.GetDiscussionDigest(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), options)
+	if err != nil {
+		fatalErr(err)
+	}
+	if format == pullrequests.DigestFormatMarkdown {
+		fmt.Print(pullrequests.RenderDiscussionDigest(digest))
+		return
+	}
+	printJSON(digest)
+}
+
+// parseDigestOptions defaults to markdown without system threads; pass "false" as excludeSystem to keep them.
+func parseDigestOptions(args []string) (string, pullrequests.DigestOptions, error) {
+	if len(args) < 4 {
+		return "", pullrequests.DigestOptions{}, fmt.Errorf(usageGetPRDiscussionDigest)
+	}
+	format := pullrequests.DigestFormatMarkdown
 	if len(args) >= 5 {
-		statusFilter = strings.TrimSpace(args[4])
+		normalized, err := pullrequests.NormalizeDigestFormat(args[4])
+		if err != nil {
+			return "", pullrequests.DigestOptions{}, err
+		}
+		format = normalized
 	}
+	options := pullrequests.DigestOptions{ExcludeSystem: true}
 	if len(args) >= 6 {
-		excludeSystem = strings.EqualFold(strings.TrimSpace(args[5]), "true")
+		options.ExcludeSystem = !strings.EqualFold(strings.TrimSpace(args[5]), "false")
 	}
-	result, err := pullrequests.GetThreads(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), statusFilter, excludeSystem)
+	if len(args) >= 7 && strings.TrimSpace(args[6]) != "-" {
+		maxChars, err := strconv.Atoi(strings.TrimSpace(args[6]))
+		if err != nil || maxChars < 1 {
+			return "", pullrequests.DigestOptions{}, fmt.Errorf("maxCommentChars must be a positive integer")
+		}
+		options.MaxCommentChars = maxChars
+	}
+	return format, options, nil
+}
+
+const usageGetMyPendingThreads = "usage: skills-go get-my-pending-threads <organization> <project> [repositoryId] [pullRequestId] [since] [classification]"
+
+func handleGetMyPendingThreads(args []string) {
+	options, err := parsePendingThreadsOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := pullrequests.GetMyPendingThreads(options)
 	if err != nil {
 		fatalErr(err)
 	}
 	printJSON(result)
 }
 
+func parsePendingThreadsOptions(args []string) (pullrequests.PendingThreadsOptions, error) {
+	if len(args) < 2 {
+		return pullrequests.PendingThreadsOptions{}, fmt.Errorf(usageGetMyPendingThreads)
+	}
+	optional := func(index int) string {
+		if len(args) > index {
+			if value := strings.TrimSpace(args[index]); value != "-" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	since, err := pullrequests.ParseThreadTime(optional(4))
+	if err != nil {
+		return pullrequests.PendingThreadsOptions{}, fmt.Errorf("since: %w", err)
+	}
+	classification, err := pullrequests.NormalizePendingClassification(optional(5))
+	if err != nil {
+		return pullrequests.PendingThreadsOptions{}, err
+	}
+	return pullrequests.PendingThreadsOptions{
+		Organization:   strings.TrimSpace(args[0]),
+		Project:        strings.TrimSpace(args[1]),
+		RepositoryID:   optional(2),
+		PullRequestID:  optional(3),
+		Since:          since,
+		Classification: classification,
+	}, nil
+}
+
+const usageExportPRThreads = "usage: skills-go export-pr-threads <organization> <project> <repositoryId> <pullRequestId> [format] [excludeSystem] [status] [from] [to]"
+
+func handleExportPRThreads(args []string) {
+	format, options, err := parseExportThreadsOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	export, err := pullrequests.ExportThreads(options)
+	if err != nil {
+		fatalErr(err)
+	}
+	switch format {
+	case pullrequests.ExportFormatSARIF:
+		printJSON(pullrequests.BuildThreadsSarif(export))
+	case pullrequests.ExportFormatCSV:
+		rendered, err := pullrequests.RenderThreadsCSV(export)
+		if err != nil {
+			fatalErr(err)
+		}
+		fmt.Print(rendered)
+	default:
+		printJSON(export)
+	}
+}
+
+// parseExportThreadsOptions defaults to JSON without system threads. Use "-" as pullRequestId to
+// export every pull request created between from and to; a date-only to includes that whole day.
+func parseExportThreadsOptions(args []string) (string, pullrequests.ExportThreadsOptions, error) {
+	if len(args) < 4 {
+		return "", pullrequests.ExportThreadsOptions{}, fmt.Errorf(usageExportPRThreads)
+	}
+	optional := func(index int) string {
+		if len(args) > index {
+			if value := strings.TrimSpace(args[index]); value != "-" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	format, err := pullrequests.NormalizeExportFormat(optional(4))
+	if err != nil {
+		return "", pullrequests.ExportThreadsOptions{}, err
+	}
+	from, err := pullrequests.ParseThreadTime(optional(7))
+	if err != nil {
+		return "", pullrequests.ExportThreadsOptions{}, fmt.Errorf("from: %w", err)
+	}
+	to, err := pullrequests.ParseThreadTime(optional(8))
+	if err != nil {
+		return "", pullrequests.ExportThreadsOptions{}, fmt.Errorf("to: %w", err)
+	}
+	if len(optional(8)) == len("2006-01-02") {
+		to = to.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	options := pullrequests.ExportThreadsOptions{
+		Organization:  strings.TrimSpace(args[0]),
+		Project:       strings.TrimSpace(args[1]),
+		RepositoryID:  optional(2),
+		PullRequestID: optional(3),
+		From:          from,
+		To:            to,
+		Status:        optional(6),
+		ExcludeSystem: !strings.EqualFold(optional(5), "false"),
+	}
+	if options.PullRequestID == "" && from.IsZero() {
+		return "", pullrequests.ExportThreadsOptions{}, fmt.Errorf("from is required when pullRequestId is -")
+	}
+	return format, options, nil
+}
+
 func handlePostPRComment(args []string) {
+	options, err := parsePostCommentOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := pullrequests.PostComment(options)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func parsePostCommentOptions(args []string) (pullrequests.CommentOptions, error) {
 	if len(args) < 7 {
-		fatalf("usage: skills-go post-pr-comment <organization> <project> <repositoryId> <pullRequestId> <filePath> <line> <comment>")
+		return pullrequests.CommentOptions{}, fmt.Errorf("usage: skills-go post-pr-comment <organization> <project> <repositoryId> <pullRequestId> <filePath> <line> <comment> [positionMode] [iterationId] [side] [onDuplicate] [category] [key]")
+	}
+	// The comment used to take every remaining argument, so an unquoted multi-word comment now spills
+	// into the optional arguments; say so instead of only rejecting the stray word.
+	quoteHint := func(err error) error {
+		return fmt.Errorf("%w (if the comment has several words, pass it as one quoted argument)", err)
+	}
+
+	positionMode := ""
+	if len(args) >= 8 {
+		mode, err := pullrequests.NormalizePositionMode(args[7])
+		if err != nil {
+			return pullrequests.CommentOptions{}, quoteHint(err)
+		}
+		positionMode = mode
+	}
+
+	iterationID := ""
+	if len(args) >= 9 {
+		iterationID = strings.TrimSpace(args[8])
+		if iterationID == "-" {
+			iterationID = ""
+		}
+		if id, err := strconv.Atoi(iterationID); iterationID != "" && (err != nil || id < 1) {
+			return pullrequests.CommentOptions{}, quoteHint(fmt.Errorf("iterationId must be a positive integer or -"))
+		}
+	}
+
+	side := ""
+	if len(args) >= 10 {
+		normalized, err := pullrequests.NormalizeCommentSide(args[9])
+		if err != nil {
+			return pullrequests.CommentOptions{}, quoteHint(err)
+		}
+		side = normalized
+	}
+
+	onDuplicate := ""
+	if len(args) >= 11 {
+		policy, err := pullrequests.NormalizeDuplicatePolicy(args[10])
+		if err != nil {
+			return pullrequests.CommentOptions{}, quoteHint(err)
+		}
+		onDuplicate = policy
+	}
+
+	category := ""
+	if len(args) >= 12 && strings.TrimSpace(args[11]) != "-" {
+		category = strings.TrimSpace(args[11])
+	}
+
+	key := ""
+	if len(args) >= 13 && strings.TrimSpace(args[12]) != "-" {
+		key = strings.TrimSpace(args[12])
+	}
+
+	return pullrequests.CommentOptions{
+		Organization:  strings.TrimSpace(args[0]),
+		Project:       strings.TrimSpace(args[1]),
+		RepositoryID:  strings.TrimSpace(args[2]),
+		PullRequestID: strings.TrimSpace(args[3]),
+		FilePath:      args[4],
+		Line:          args[5],
+		Comment:       args[6],
+		PositionMode:  positionMode,
+		IterationID:   iterationID,
+		Side:          side,
+		Category:      category,
+		OnDuplicate:   onDuplicate,
+		Key:           key,
+	}, nil
+}
+
+func handlePostPRSuggestion(args []string) {
+	options, err := parsePostSuggestionOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := pullrequests.PostSuggestion(options)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func parsePostSuggestionOptions(args []string) (pullrequests.SuggestionOptions, error) {
+	if len(args) < 8 {
+		return pullrequests.SuggestionOptions{}, fmt.Errorf("usage: skills-go post-pr-suggestion <organization> <project> <repositoryId> <pullRequestId> <filePath> <startLine> <endLine> <replacement> [comment] [iterationId] [expectedOriginal] [onDuplicate] [key]")
+	}
+
+	startLine, err := strconv.Atoi(strings.TrimSpace(args[5]))
+	if err != nil || startLine < 1 {
+		return pullrequests.SuggestionOptions{}, fmt.Errorf("startLine must be a positive integer")
+	}
+	endLine, err := strconv.Atoi(strings.TrimSpace(args[6]))
+	if err != nil || endLine < startLine {
+		return pullrequests.SuggestionOptions{}, fmt.Errorf("endLine must be an integer >= startLine")
+	}
+
+	comment := ""
+	if len(args) >= 9 {
+		comment = args[8]
+	}
+
+	iterationID := ""
+	if len(args) >= 10 && strings.TrimSpace(args[9]) != "-" {
+		iterationID = strings.TrimSpace(args[9])
+	}
+
+	var expectedOriginal *string
+	if len(args) >= 11 && args[10] != "-" {
+		expectedOriginal = &args[10]
+	}
+
+	onDuplicate := ""
+	if len(args) >= 12 {
+		policy, err := pullrequests.NormalizeDuplicatePolicy(args[11])
+		if err != nil {
+			return pullrequests.SuggestionOptions{}, err
+		}
+		onDuplicate = policy
+	}
+
+	key := ""
+	if len(args) >= 13 && strings.TrimSpace(args[12]) != "-" {
+		key = strings.TrimSpace(args[12])
+	}
+
+	return pullrequests.SuggestionOptions{
+		Organization:     strings.TrimSpace(args[0]),
+		Project:          strings.TrimSpace(args[1]),
+		RepositoryID:     strings.TrimSpace(args[2]),
+		PullRequestID:    strings.TrimSpace(args[3]),
+		FilePath:         args[4],
+		StartLine:        startLine,
+		EndLine:          endLine,
+		Replacement:      args[7],
+		Comment:          comment,
+		IterationID:      iterationID,
+		ExpectedOriginal: expectedOriginal,
+		OnDuplicate:      onDuplicate,
+		Key:              key,
+	}, nil
+}
+
+const usageSubmitReview = "usage: skills-go submit-review <organization> <project> <repositoryId> <pullRequestId> <findingsFile> [positionMode] [iterationId] [dryRun] [onDuplicate]"
+
+func handleSubmitReview(args []string) {
+	options, findingsFile, err := parseSubmitReviewOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+
+	var data []byte
+	if findingsFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(findingsFile)
+	}
+	if err != nil {
+		fatalErr(fmt.Errorf("read findings document: %w", err))
+	}
+	document, err := pullrequests.ParseReviewDocument(data)
+	if err != nil {
+		fatalErr(err)
+	}
+
+	result, err := pullrequests.SubmitReview(options, document)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+	if status := result["status"]; status == pullrequests.SubmitStatusPartial || status == pullrequests.SubmitStatusAborted {
+		os.Exit(1)
+	}
+}
+
+func parseSubmitReviewOptions(args []string) (pullrequests.SubmitReviewOptions, string, error) {
+	if len(args) < 5 {
+		return pullrequests.SubmitReviewOptions{}, "", fmt.Errorf(usageSubmitReview)
+	}
+	findingsFile := strings.TrimSpace(args[4])
+	if findingsFile == "" {
+		return pullrequests.SubmitReviewOptions{}, "", fmt.Errorf("findingsFile is required (use - to read from stdin)")
+	}
+
+	positionMode := pullrequests.PositionModeValidate
+	if len(args) >= 6 && strings.TrimSpace(args[5]) != "-" && strings.TrimSpace(args[5]) != "" {
+		mode, err := pullrequests.NormalizePositionMode(args[5])
+		if err != nil {
+			return pullrequests.SubmitReviewOptions{}, "", err
+		}
+		positionMode = mode
+	}
+
+	iterationID := ""
+	if len(args) >= 7 {
+		iterationID = strings.TrimSpace(args[6])
+		if iterationID == "-" {
+			iterationID = ""
+		}
 	}
-	comment := strings.Join(args[6:], " ")
-	result, err := pullrequests.PostComment(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), args[4], args[5], comment)
+
+	dryRun := false
+	if len(args) >= 8 {
+		dryRun = strings.EqualFold(strings.TrimSpace(args[7]), "true")
+	}
+
+	onDuplicate := ""
+	if len(args) >= 9 {
+		policy, err := pullrequests.NormalizeDuplicatePolicy(args[8])
+		if err != nil {
+			return pullrequests.SubmitReviewOptions{}, "", err
+		}
+		onDuplicate = policy
+	}
+
+	return pullrequests.SubmitReviewOptions{
+		Organization:  strings.TrimSpace(args[0]),
+		Project:       strings.TrimSpace(args[1]),
+		RepositoryID:  strings.TrimSpace(args[2]),
+		PullRequestID: strings.TrimSpace(args[3]),
+		PositionMode:  positionMode,
+		IterationID:   iterationID,
+		OnDuplicate:   onDuplicate,
+		DryRun:        dryRun,
+	}, findingsFile, nil
+}
+
+const usageImportSarif = "usage: skills-go import-sarif <organization> <project> <repositoryId> <pullRequestId> <sarifFile> [outsideDiff] [iterationId] [dryRun] [onDuplicate] [sourceRoot]"
+
+func handleImportSarif(args []string) {
+	options, sarifFile, err := parseImportSarifOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+
+	var data []byte
+	if sarifFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(sarifFile)
+	}
+	if err != nil {
+		fatalErr(fmt.Errorf("read SARIF file: %w", err))
+	}
+	log, err := sarif.Parse(data)
+	if err != nil {
+		fatalErr(err)
+	}
+
+	result, err := pullrequests.ImportSarif(options, log)
 	if err != nil {
 		fatalErr(err)
 	}
 	printJSON(result)
+	if status := result["status"]; status == pullrequests.SubmitStatusPartial || status == pullrequests.SubmitStatusAborted {
+		os.Exit(1)
+	}
+}
+
+func parseImportSarifOptions(args []string) (pullrequests.ImportSarifOptions, string, error) {
+	if len(args) < 5 {
+		return pullrequests.ImportSarifOptions{}, "", fmt.Errorf(usageImportSarif)
+	}
+	sarifFile := strings.TrimSpace(args[4])
+	if sarifFile == "" {
+		return pullrequests.ImportSarifOptions{}, "", fmt.Errorf("sarifFile is required (use - to read from stdin)")
+	}
+
+	outsideDiff := pullrequests.OutsideDiffSummarize
+	if len(args) >= 6 {
+		policy, err := pullrequests.NormalizeOutsideDiffPolicy(args[5])
+		if err != nil {
+			return pullrequests.ImportSarifOptions{}, "", err
+		}
+		outsideDiff = policy
+	}
+
+	optional := func(index int) string {
+		if len(args) > index {
+			if value := strings.TrimSpace(args[index]); value != "-" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	dryRun := strings.EqualFold(optional(7), "true")
+
+	onDuplicate := ""
+	if len(args) >= 9 {
+		policy, err := pullrequests.NormalizeDuplicatePolicy(args[8])
+		if err != nil {
+			return pullrequests.ImportSarifOptions{}, "", err
+		}
+		onDuplicate = policy
+	}
+
+	return pullrequests.ImportSarifOptions{
+		Organization:  strings.TrimSpace(args[0]),
+		Project:       strings.TrimSpace(args[1]),
+		RepositoryID:  strings.TrimSpace(args[2]),
+		PullRequestID: strings.TrimSpace(args[3]),
+		IterationID:   optional(6),
+		OutsideDiff:   outsideDiff,
+		SourceRoot:    optional(9),
+		OnDuplicate:   onDuplicate,
+		DryRun:        dryRun,
+	}, sarifFile, nil
 }
 
 func handleUpdatePRThread(args []string) {
 	if len(args) < 6 {
-		fatalf("usage: skills-go update-pr-thread <organization> <project> <repositoryId> <pullRequestId> <threadId> [reply] [status]")
+		fatalf("usage: skills-go update-pr-thread <organization> <project> <repositoryId> <pullRequestId> <threadId> [reply] [status] [parentCommentId]")
 	}
 	reply := args[5]
 	status := ""
 	if len(args) >= 7 {
 		status = args[6]
 	}
-	result, err := pullrequests.UpdateThread(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), reply, status)
+	parentCommentID := 0
+	if len(args) >= 8 {
+		parsed, err := parseParentCommentID(args[7])
+		if err != nil {
+			fatalErr(err)
+		}
+		parentCommentID = parsed
+	}
+	result, err := pullrequests.UpdateThread(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), reply, status, parentCommentID)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+// parseParentCommentID parses the comment a reply answers; "-" or empty means the thread's first comment.
+func parseParentCommentID(value string) (int, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "-" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(trimmed)
+	if err != nil || parsed < 1 {
+		return 0, fmt.Errorf("parentCommentId must be a positive integer")
+	}
+	return parsed, nil
+}
+
+const usageReconcileThreads = "usage: skills-go reconcile-threads <organization> <project> <repositoryId> <pullRequestId> [iterationId] [resolve] [reply] [threadIds]"
+
+func handleReconcileThreads(args []string) {
+	options, err := parseReconcileOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := pullrequests.ReconcileThreads(options)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func parseReconcileOptions(args []string) (pullrequests.ReconcileOptions, error) {
+	if len(args) < 4 {
+		return pullrequests.ReconcileOptions{}, fmt.Errorf(usageReconcileThreads)
+	}
+	options := pullrequests.ReconcileOptions{
+		Organization:  strings.TrimSpace(args[0]),
+		Project:       strings.TrimSpace(args[1]),
+		RepositoryID:  strings.TrimSpace(args[2]),
+		PullRequestID: strings.TrimSpace(args[3]),
+	}
+	if len(args) >= 5 && strings.TrimSpace(args[4]) != "-" {
+		options.IterationID = strings.TrimSpace(args[4])
+	}
+	if len(args) >= 6 {
+		options.Resolve = strings.EqualFold(strings.TrimSpace(args[5]), "true")
+	}
+	if len(args) >= 7 {
+		options.Reply = args[6]
+	}
+	if len(args) >= 8 {
+		for _, value := range splitListArg(args[7]) {
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 1 {
+				return pullrequests.ReconcileOptions{}, fmt.Errorf("threadIds must be a comma-separated list of positive integers")
+			}
+			options.ThreadIDs = append(options.ThreadIDs, id)
+		}
+	}
+	return options, nil
+}
+
+func handleEditPRComment(args []string) {
+	if len(args) < 7 {
+		fatalf("usage: skills-go edit-pr-comment <organization> <project> <repositoryId> <pullRequestId> <threadId> <commentId> <content>")
+	}
+	result, err := pullrequests.EditComment(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), strings.TrimSpace(args[5]), args[6])
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func handleDeletePRComment(args []string) {
+	if len(args) < 6 {
+		fatalf("usage: skills-go delete-pr-comment <organization> <project> <repositoryId> <pullRequestId> <threadId> <commentId>")
+	}
+	result, err := pullrequests.DeleteComment(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), strings.TrimSpace(args[5]))
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+const usageListFiles = "usage: skills-go list-files <organization> <project> <repositoryId> [version] [scopePath] [glob] [recursion] [versionType]"
+
+func handleListFiles(args []string) {
+	options, err := parseListFilesOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := files.List(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), options)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func parseListFilesOptions(args []string) (files.ListOptions, error) {
+	if len(args) < 3 {
+		return files.ListOptions{}, fmt.Errorf(usageListFiles)
+	}
+	optional := func(index int) string {
+		if len(args) > index {
+			if value := strings.TrimSpace(args[index]); value != "-" {
+				return value
+			}
+		}
+		return ""
+	}
+	options := files.ListOptions{
+		Version:     optional(3),
+		ScopePath:   optional(4),
+		Glob:        optional(5),
+		Recursion:   optional(6),
+		VersionType: optional(7),
+	}
+	if options.VersionType == "" {
+		options.VersionType = "branch"
+	}
+	return options, nil
+}
+
+const usageSearchCode = "usage: skills-go search-code <organization> <searchText> [project] [repositories] [paths] [branches] [skip] [top] [contextLines]"
+
+func handleSearchCode(args []string) {
+	options, err := parseSearchCodeOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := search.Code(strings.TrimSpace(args[0]), args[1], options)
 	if err != nil {
 		fatalErr(err)
 	}
 	printJSON(result)
 }
 
+func parseSearchCodeOptions(args []string) (search.CodeOptions, error) {
+	if len(args) < 2 {
+		return search.CodeOptions{}, fmt.Errorf(usageSearchCode)
+	}
+
+	options := search.CodeOptions{Top: search.DefaultTop, ContextLines: search.DefaultContextLines}
+	if len(args) >= 3 && strings.TrimSpace(args[2]) != "-" {
+		options.Project = strings.TrimSpace(args[2])
+	}
+	if len(args) >= 4 {
+		options.Repositories = splitListArg(args[3])
+	}
+	if len(args) >= 5 {
+		options.Paths = splitListArg(args[4])
+	}
+	if len(args) >= 6 {
+		options.Branches = splitListArg(args[5])
+	}
+	if len(args) >= 7 && strings.TrimSpace(args[6]) != "-" {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[6]))
+		if err != nil || parsed < 0 {
+			return search.CodeOptions{}, fmt.Errorf("skip must be a non-negative integer")
+		}
+		options.Skip = parsed
+	}
+	if len(args) >= 8 && strings.TrimSpace(args[7]) != "-" {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[7]))
+		if err != nil || parsed <= 0 || parsed > search.MaxTop {
+			return search.CodeOptions{}, fmt.Errorf("top must be between 1 and %d", search.MaxTop)
+		}
+		options.Top = parsed
+	}
+	if len(args) >= 9 && strings.TrimSpace(args[8]) != "-" {
+		value := strings.TrimSpace(args[8])
+		if strings.EqualFold(value, "none") {
+			options.ContextLines = -1
+		} else {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return search.CodeOptions{}, fmt.Errorf("contextLines must be a non-negative integer or none")
+			}
+			options.ContextLines = parsed
+		}
+	}
+	return options, nil
+}
+
+// splitListArg splits a comma-separated argument, dropping blanks; "-" means no values.
+func splitListArg(value string) []string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "-" {
+		return nil
+	}
+	values := make([]string, 0)
+	for _, item := range strings.Split(trimmed, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 func handleGetMultipleFiles(args []string) {
 	if len(args) < 6 {
-		fatalf("usage: skills-go get-multiple-files <organization> <project> <repositoryId> <version> <versionType> '<json_paths_array>'")
+		fatalf("usage: skills-go get-multiple-files <organization> <project> <repositoryId> <version> <versionType> '<json_paths_array>' [maxBytes]")
 	}
-	var paths []string
-	if err := json.Unmarshal([]byte(args[5]), &paths); err != nil {
+	requests, err := files.ParseFileRequests(args[5])
+	if err != nil {
 		fatalErr(fmt.Errorf("invalid json_paths_array: %w", err))
 	}
-	result, err := files.GetMultiple(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), paths)
+	maxBytes := files.DefaultMaxContentBytes
+	if len(args) >= 7 {
+		parsed, err := parseMaxContentBytes(args[6])
+		if err != nil {
+			fatalErr(err)
+		}
+		maxBytes = parsed
+	}
+	result, err := files.GetMultipleRequests(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), requests, maxBytes)
 	if err != nil {
 		fatalErr(err)
 	}
 	printJSON(result)
 }
 
+func parseMaxContentBytes(value string) (int, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "-" {
+		return files.DefaultMaxContentBytes, nil
+	}
+	parsed, err := strconv.Atoi(trimmed)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("maxBytes must be a non-negative integer (0 disables the limit)")
+	}
+	return parsed, nil
+}
+
 func handleGetGitHubAdvisories(args []string) {
 	if len(args) < 2 {
 		fatalf("usage: skills-go get-github-advisories <ecosystem> <package> [version] [severity] [per_page]")
@@ -260,18 +1057,51 @@ func handleGetPRDependencyAdvisories(args []string) {
 
 func handleGetPRDiffLineMapper(args []string) {
 	if len(args) < 5 {
-		fatalf("usage: skills-go get-pr-diff-line-mapper <organization> <project> <repositoryId> <pullRequestId> <iterationId>")
+		fatalf("usage: skills-go get-pr-diff-line-mapper <organization> <project> <repositoryId> <pullRequestId> <iterationId> [whitespaceMode] [ignoreEol]")
 	}
 	if err := diffmapper.ValidateInputs(args[0], args[1], args[2], args[3], args[4]); err != nil {
 		fatalErr(err)
 	}
-	result, err := diffmapper.MapPRDiffLines(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]))
+	options, err := parseDiffMapperOptions(args)
+	if err != nil {
+		fatalf(err.Error())
+	}
+	result, err := diffmapper.MapPRDiffLines(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]), options)
+	if err != nil {
+		fatalErr(err)
+	}
+	printJSON(result)
+}
+
+func handleGetPRChangedSymbols(args []string) {
+	if len(args) < 5 {
+		fatalf("usage: skills-go get-pr-changed-symbols <organization> <project> <repositoryId> <pullRequestId> <iterationId>")
+	}
+	if err := symboldiff.ValidateInputs(args[0], args[1], args[2], args[3], args[4]); err != nil {
+		fatalErr(err)
+	}
+	result, err := symboldiff.GetPRChangedSymbols(strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), strings.TrimSpace(args[2]), strings.TrimSpace(args[3]), strings.TrimSpace(args[4]))
 	if err != nil {
 		fatalErr(err)
 	}
 	printJSON(result)
 }
 
+func parseDiffMapperOptions(args []string) (diffmapper.Options, error) {
+	options := diffmapper.Options{WhitespaceMode: diffmapper.WhitespaceModeNone}
+	if len(args) >= 6 {
+		mode, err := diffmapper.NormalizeWhitespaceMode(args[5])
+		if err != nil {
+			return diffmapper.Options{}, err
+		}
+		options.WhitespaceMode = mode
+	}
+	if len(args) >= 7 {
+		options.IgnoreEOL = strings.EqualFold(strings.TrimSpace(args[6]), "true")
+	}
+	return options, nil
+}
+
 func handleGetPRIterations(args []string) {
 	if len(args) < 4 {
 		fatalf("usage: skills-go get-pr-iterations <organization> <project> <repositoryId> <pullRequestId>")
@@ -346,7 +1176,7 @@ func printJSON(value any) {
 
 func handleGetPRReviewBundle(args []string) {
 	if len(args) < 4 {
-		fatalf("usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap]")
+		fatalf("usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap] [excludeCategories] [tokenBudget] [chunkIndex] [hunkContext] [maxHunkFileBytes] [maxHunkTotalBytes] [includeEnclosingBody]")
 	}
 
 	options, err := parseReviewBundleOptions(args)
@@ -364,7 +1194,7 @@ func handleGetPRReviewBundle(args []string) {
 
 func parseReviewBundleOptions(args []string) (pullrequests.ReviewBundleOptions, error) {
 	if len(args) < 4 {
-		return pullrequests.ReviewBundleOptions{}, fmt.Errorf("usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap]")
+		return pullrequests.ReviewBundleOptions{}, fmt.Errorf("usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap] [excludeCategories] [tokenBudget] [chunkIndex] [hunkContext] [maxHunkFileBytes] [maxHunkTotalBytes] [includeEnclosingBody]")
 	}
 
 	iterationID := ""
@@ -423,6 +1253,67 @@ func parseReviewBundleOptions(args []string) (pullrequests.ReviewBundleOptions,
 		includeLineMap = strings.EqualFold(strings.TrimSpace(args[11]), "true")
 	}
 
+	excludeCategories := map[string]bool{}
+	if len(args) >= 13 {
+		parsed, err := fileclass.ParseCategories(args[12])
+		if err != nil {
+			return pullrequests.ReviewBundleOptions{}, err
+		}
+		excludeCategories = parsed
+	}
+
+	tokenBudget := 0
+	if len(args) >= 14 && strings.TrimSpace(args[13]) != "-" {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[13]))
+		if err != nil || parsed < 0 {
+			return pullrequests.ReviewBundleOptions{}, fmt.Errorf("tokenBudget must be a non-negative integer")
+		}
+		tokenBudget = parsed
+	}
+
+	chunkIndex := 0
+	if len(args) >= 15 {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[14]))
+		if err != nil || parsed < 0 {
+			return pullrequests.ReviewBundleOptions{}, fmt.Errorf("chunkIndex must be a non-negative integer")
+		}
+		chunkIndex = parsed
+	}
+
+	includeHunks := false
+	hunkContext := 0
+	if len(args) >= 16 && strings.TrimSpace(args[15]) != "" && strings.TrimSpace(args[15]) != "-" {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[15]))
+		if err != nil || parsed < 0 {
+			return pullrequests.ReviewBundleOptions{}, fmt.Errorf("hunkContext must be a non-negative integer")
+		}
+		includeHunks = true
+		hunkContext = parsed
+	}
+
+	maxHunkFileBytes := 0
+	if len(args) >= 17 {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[16]))
+		if err != nil || parsed <= 0 {
+			return pullrequests.ReviewBundleOptions{}, fmt.Errorf("maxHunkFileBytes must be a positive integer")
+		}
+		maxHunkFileBytes = parsed
+	}
+
+	maxHunkTotalBytes := 0
+	if len(args) >= 18 {
+		parsed, err := strconv.Atoi(strings.TrimSpace(args[17]))
+		if err != nil || parsed <= 0 {
+			return pullrequests.ReviewBundleOptions{}, fmt.Errorf("maxHunkTotalBytes must be a positive integer")
+		}
+		maxHunkTotalBytes = parsed
+	}
+
+	includeEnclosingBody := false
+	if len(args) >= 19 {
+		includeEnclosingBody = strings.EqualFold(strings.TrimSpace(args[18]), "true")
+	}
+
 	return pullrequests.ReviewBundleOptions{
 		Organization:         strings.TrimSpace(args[0]),
 		Project:              strings.TrimSpace(args[1]),
@@ -436,11 +1327,19 @@ func parseReviewBundleOptions(args []string) (pullrequests.ReviewBundleOptions,
 		ThreadStatusFilter:   statusFilter,
 		ExcludeSystemThreads: excludeSystem,
 		IncludeLineMap:       includeLineMap,
+		ExcludeCategories:    excludeCategories,
+		TokenBudget:          tokenBudget,
+		ChunkIndex:           chunkIndex,
+		IncludeHunks:         includeHunks,
+		HunkContextLines:     hunkContext,
+		MaxHunkFileBytes:     maxHunkFileBytes,
+		MaxHunkTotalBytes:    maxHunkTotalBytes,
+		IncludeEnclosingBody: includeEnclosingBody,
 	}, nil
 }
 
 func printUsageAndExit() {
-	fatalf("usage: skills-go <command> [args]\ncommands:\n  check-deprecated-dependencies <ecosystem> <package> [version]\n  list-projects <organization>\n  list-repositories <organization> <project>\n  get-pr-details <organization> <project> <repositoryId> <pullRequestId>\n  get-pr-iterations <organization> <project> <repositoryId> <pullRequestId>\n  get-pr-changes <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  get-pr-changed-files <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap]\n  get-pr-threads <organization> <project> <repositoryId> <pullRequestId> [statusFilter] [excludeSystem]\n  post-pr-comment <organization> <project> <repositoryId> <pullRequestId> <filePath> <line> <comment>\n  update-pr-thread <organization> <project> <repositoryId> <pullRequestId> <threadId> [reply] [status]\n  get-file-content <organization> <project> <repositoryId> <path> [version] [versionType]\n  get-multiple-files <organization> <project> <repositoryId> <version> <versionType> '<json_paths_array>'\n  get-commit-diffs <organization> <project> <repositoryId> <baseVersion> <targetVersion> [baseVersionType] [targetVersionType]\n  get-github-advisories <ecosystem> <package> [version] [severity] [per_page]\n  get-pr-dependency-advisories <organization> <project> <repositoryId> <pullRequestId> [iterationId] [per_page]\n  get-pr-diff-line-mapper <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  accept-pr <organization> <project> <repositoryId> <pullRequestId>\n  approve-with-suggestions <organization> <project> <repositoryId> <pullRequestId>\n  wait-for-author <organization> <project> <repositoryId> <pullRequestId>\n  reject-pr <organization> <project> <repositoryId> <pullRequestId>\n  reset-feedback <organization> <project> <repositoryId> <pullRequestId>")
+	fatalf("usage: skills-go <command> [args]\ncommands:\n  check-deprecated-dependencies <ecosystem> <package> [version]\n  list-projects <organization>\n  list-repositories <organization> <project>\n  get-pr-details <organization> <project> <repositoryId> <pullRequestId>\n  get-pr-iterations <organization> <project> <repositoryId> <pullRequestId>\n  get-pr-changes <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  get-pr-changed-files <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap] [excludeCategories] [tokenBudget] [chunkIndex] [hunkContext] [maxHunkFileBytes] [maxHunkTotalBytes] [includeEnclosingBody]\n  get-pr-threads <organization> <project> <repositoryId> <pullRequestId> [statusFilter] [excludeSystem] [author] [pathGlob] [iteration] [createdSince] [updatedSince] [hasReplies] [lastCommentBy] [compact] [classification]\n  get-pr-discussion-digest <organization> <project> <repositoryId> <pullRequestId> [format] [excludeSystem] [maxCommentChars]\n  get-my-pending-threads <organization> <project> [repositoryId] [pullRequestId] [since] [classification]\n  export-pr-threads <organization> <project> <repositoryId> <pullRequestId> [format] [excludeSystem] [status] [from] [to]\n  post-pr-comment <organization> <project> <repositoryId> <pullRequestId> <filePath> <line> <comment> [positionMode] [iterationId] [side] [onDuplicate] [category] [key]\n  post-pr-suggestion <organization> <project> <repositoryId> <pullRequestId> <filePath> <startLine> <endLine> <replacement> [comment] [iterationId] [expectedOriginal] [onDuplicate] [key]\n  submit-review <organization> <project> <repositoryId> <pullRequestId> <findingsFile> [positionMode] [iterationId] [dryRun] [onDuplicate]\n  import-sarif <organization> <project> <repositoryId> <pullRequestId> <sarifFile> [outsideDiff] [iterationId] [dryRun] [onDuplicate] [sourceRoot]\n  update-pr-thread <organization> <project> <repositoryId> <pullRequestId> <threadId> [reply] [status] [parentCommentId]\n  reconcile-threads <organization> <project> <repositoryId> <pullRequestId> [iterationId] [resolve] [reply] [threadIds]\n  edit-pr-comment <organization> <project> <repositoryId> <pullRequestId> <threadId> <commentId> <content>\n  delete-pr-comment <organization> <project> <repositoryId> <pullRequestId> <threadId> <commentId>\n  get-file-content <organization> <project> <repositoryId> <path> [version] [versionType] [maxBytes] [lines]\n  list-files <organization> <project> <repositoryId> [version] [scopePath] [glob] [recursion] [versionType]\n  search-code <organization> <searchText> [project] [repositories] [paths] [branches] [skip] [top] [contextLines]\n  get-multiple-files <organization> <project> <repositoryId> <version> <versionType> '<json_paths_array>' [maxBytes]\n  get-commit-diffs <organization> <project> <repositoryId> <baseVersion> <targetVersion> [baseVersionType] [targetVersionType]\n  get-github-advisories <ecosystem> <package> [version] [severity] [per_page]\n  get-pr-dependency-advisories <organization> <project> <repositoryId> <pullRequestId> [iterationId] [per_page]\n  get-pr-diff-line-mapper <organization> <project> <repositoryId> <pullRequestId> <iterationId> [whitespaceMode] [ignoreEol]\n  get-pr-changed-symbols <organization> <project> <repositoryId> <pullRequestId> <iterationId>\n  accept-pr <organization> <project> <repositoryId> <pullRequestId>\n  approve-with-suggestions <organization> <project> <repositoryId> <pullRequestId>\n  wait-for-author <organization> <project> <repositoryId> <pullRequestId>\n  reject-pr <organization> <project> <repositoryId> <pullRequestId>\n  reset-feedback <organization> <project> <repositoryId> <pullRequestId>")
 }
 
 func fatalErr(err error) {
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestParseDigestOptions(t *testing.T) {
+	format, options, err := parseDigestOptions([]string{"org", "proj", "repo", "7"})
+	if err != nil || format != "markdown" || !options.ExcludeSystem || options.MaxCommentChars != 0 {
+		t.Fatalf("unexpected defaults: %q, %#v, %v", format, options, err)
+	}
+
+	format, options, err = parseDigestOptions([]string{"org", "proj", "repo", "7", "json", "false", "200"})
+	if err != nil || format != "json" || options.ExcludeSystem || options.MaxCommentChars != 200 {
+		t.Fatalf("unexpected options: %q, %#v, %v", format, options, err)
+	}
+}
+
+func TestParseDigestOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing pull request", args: []string{"org", "proj", "repo"}, wantErr: usageGetPRDiscussionDigest},
+		{name: "format", args: []string{"org", "proj", "repo", "7", "html"}, wantErr: "format must be one of: markdown, json"},
+		{name: "maxCommentChars", args: []string{"org", "proj", "repo", "7", "-", "-", "0"}, wantErr: "maxCommentChars must be a positive integer"},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := parseDigestOptions(testCase.args)
+			if err == nil || err.Error() != testCase.wantErr {
+				t.Fatalf("expected error %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
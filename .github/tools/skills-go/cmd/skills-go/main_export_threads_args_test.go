@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
+)
+
+func TestParseExportThreadsOptions(t *testing.T) {
+	format, options, err := parseExportThreadsOptions([]string{"org", "proj", "repo", "7"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if format != pullrequests.ExportFormatJSON || !options.ExcludeSystem || options.PullRequestID != "7" || options.RepositoryID != "repo" {
+		t.Fatalf("unexpected defaults: %q, %#v", format, options)
+	}
+
+	format, options, err = parseExportThreadsOptions([]string{"org", "proj", "-", "-", "sarif", "false", "fixed", "2024-05-01", "2024-05-31"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if format != pullrequests.ExportFormatSARIF || options.ExcludeSystem || options.Status != "fixed" || options.RepositoryID != "" || options.PullRequestID != "" {
+		t.Fatalf("unexpected options: %q, %#v", format, options)
+	}
+	if !options.From.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !options.To.Equal(time.Date(2024, 5, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Fatalf("expected the whole of the to date, got %v - %v", options.From, options.To)
+	}
+
+	_, options, err = parseExportThreadsOptions([]string{"org", "proj", "repo", "-", "csv", "-", "-", "2024-05-01T08:00:00Z", "2024-05-02T08:00:00Z"})
+	if err != nil || !options.To.Equal(time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected an RFC 3339 to to be kept, got %v, %v", options.To, err)
+	}
+}
+
+func TestParseExportThreadsOptions_Invalid(t *testing.T) {
+	if _, _, err := parseExportThreadsOptions([]string{"org", "proj", "repo"}); err == nil || err.Error() != usageExportPRThreads {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if _, _, err := parseExportThreadsOptions([]string{"org", "proj", "repo", "7", "xml"}); err == nil || err.Error() != "format must be one of: json, sarif, csv" {
+		t.Fatalf("expected format error, got %v", err)
+	}
+	if _, _, err := parseExportThreadsOptions([]string{"org", "proj", "repo", "-"}); err == nil || err.Error() != "from is required when pullRequestId is -" {
+		t.Fatalf("expected from error, got %v", err)
+	}
+	if _, _, err := parseExportThreadsOptions([]string{"org", "proj", "repo", "-", "-", "-", "-", "May 1"}); err == nil {
+		t.Fatal("expected a from parse error")
+	}
+}
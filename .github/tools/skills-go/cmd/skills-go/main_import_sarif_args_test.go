@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
+)
+
+func TestParseImportSarifOptions(t *testing.T) {
+	options, sarifFile, err := parseImportSarifOptions([]string{"org", "proj", "repo", "7", "results.sarif"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sarifFile != "results.sarif" || options.OutsideDiff != pullrequests.OutsideDiffSummarize || options.IterationID != "" || options.DryRun || options.SourceRoot != "" {
+		t.Fatalf("unexpected defaults: %#v, %q", options, sarifFile)
+	}
+
+	options, sarifFile, err = parseImportSarifOptions([]string{"org", "proj", "repo", "7", "-", "drop", "3", "true", "update", "/home/agent/_work/1/s"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sarifFile != "-" || options.OutsideDiff != pullrequests.OutsideDiffDrop || options.IterationID != "3" || !options.DryRun {
+		t.Fatalf("unexpected options: %#v", options)
+	}
+	if options.OnDuplicate != pullrequests.DuplicateUpdate || options.SourceRoot != "/home/agent/_work/1/s" {
+		t.Fatalf("unexpected options: %#v", options)
+	}
+}
+
+func TestParseImportSarifOptions_Invalid(t *testing.T) {
+	if _, _, err := parseImportSarifOptions([]string{"org", "proj", "repo", "7"}); err == nil || err.Error() != usageImportSarif {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if _, _, err := parseImportSarifOptions([]string{"org", "proj", "repo", "7", "a.sarif", "ignore"}); err == nil || err.Error() != "outsideDiff must be one of: summarize, drop" {
+		t.Fatalf("expected outsideDiff error, got %v", err)
+	}
+	if _, _, err := parseImportSarifOptions([]string{"org", "proj", "repo", "7", "a.sarif", "-", "-", "-", "merge"}); err == nil {
+		t.Fatal("expected onDuplicate error")
+	}
+}
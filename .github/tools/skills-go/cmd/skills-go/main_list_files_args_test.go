@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+)
+
+func TestParseListFilesOptions_RequestedOrder(t *testing.T) {
+	options, err := parseListFilesOptions([]string{"org", "proj", "repo", "main", "/docs", "**/*.md", "oneLevel"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := files.ListOptions{Version: "main", ScopePath: "/docs", Glob: "**/*.md", Recursion: "oneLevel", VersionType: "branch"}
+	if !reflect.DeepEqual(options, want) {
+		t.Fatalf("unexpected options:\n got: %#v\nwant: %#v", options, want)
+	}
+}
+
+func TestParseListFilesOptions_VersionTypeLastAndDashes(t *testing.T) {
+	options, err := parseListFilesOptions([]string{"org", "proj", "repo", "abc123", "-", " README* ", "-", "commit"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := files.ListOptions{Version: "abc123", Glob: "README*", VersionType: "commit"}
+	if !reflect.DeepEqual(options, want) {
+		t.Fatalf("unexpected options:\n got: %#v\nwant: %#v", options, want)
+	}
+
+	options, err = parseListFilesOptions([]string{"org", "proj", "repo"})
+	if err != nil || !reflect.DeepEqual(options, files.ListOptions{VersionType: "branch"}) {
+		t.Fatalf("unexpected defaults: %#v, %v", options, err)
+	}
+}
+
+func TestParseListFilesOptions_MissingArgs(t *testing.T) {
+	if _, err := parseListFilesOptions([]string{"org", "proj"}); err == nil || err.Error() != usageListFiles {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePendingThreadsOptions(t *testing.T) {
+	options, err := parsePendingThreadsOptions([]string{"org", "proj", "repo", "7", "2024-05-01", "awaiting-me"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.RepositoryID != "repo" || options.PullRequestID != "7" || options.Classification != "awaiting-me" {
+		t.Fatalf("unexpected options: %#v", options)
+	}
+	if !options.Since.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected since: %v", options.Since)
+	}
+
+	options, err = parsePendingThreadsOptions([]string{"org", "proj", "-", "-"})
+	if err != nil || options.RepositoryID != "" || options.PullRequestID != "" || !options.Since.IsZero() {
+		t.Fatalf("unexpected defaults: %#v, %v", options, err)
+	}
+}
+
+func TestParsePendingThreadsOptions_Invalid(t *testing.T) {
+	if _, err := parsePendingThreadsOptions([]string{"org"}); err == nil || err.Error() != usageGetMyPendingThreads {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if _, err := parsePendingThreadsOptions([]string{"org", "proj", "-", "-", "soon"}); err == nil {
+		t.Fatalf("expected since error")
+	}
+	if _, err := parsePendingThreadsOptions([]string{"org", "proj", "-", "-", "-", "later"}); err == nil {
+		t.Fatalf("expected classification error")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePostCommentOptions_InsufficientArgs(t *testing.T) {
+	_, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3"})
+	if err == nil {
+		t.Fatalf("expected usage error for insufficient args")
+	}
+}
+
+func TestParsePostCommentOptions_Defaults(t *testing.T) {
+	options, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "Looks off."})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.Comment != "Looks off." || options.FilePath != "/a.go" || options.Line != "3" {
+		t.Fatalf("unexpected comment fields: %#v", options)
+	}
+	if options.PositionMode != "" || options.IterationID != "" {
+		t.Fatalf("expected empty position mode and iteration, got %#v", options)
+	}
+}
+
+func TestParsePostCommentOptions_PositionModeAndIteration(t *testing.T) {
+	options, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "Looks off.", "SNAP", "4"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.PositionMode != "snap" || options.IterationID != "4" {
+		t.Fatalf("unexpected position options: %#v", options)
+	}
+
+	if _, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "Looks off.", "nearest"}); err == nil {
+		t.Fatalf("expected error for invalid position mode")
+	}
+}
+
+func TestParsePostCommentOptions_Side(t *testing.T) {
+	options, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3-9", "Removed guard.", "validate", "-", "base"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.Side != "left" || options.IterationID != "" || options.Line != "3-9" {
+		t.Fatalf("unexpected side options: %#v", options)
+	}
+
+	if _, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "x", "none", "", "middle"}); err == nil {
+		t.Fatalf("expected error for invalid side")
+	}
+}
+
+func TestParsePostCommentOptions_DuplicatePolicyAndCategory(t *testing.T) {
+	options, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "Looks off.", "-", "-", "-", "Reopen", "Security", "unchecked-error"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.OnDuplicate != "reopen" || options.Category != "Security" || options.Key != "unchecked-error" {
+		t.Fatalf("unexpected duplicate options: %#v", options)
+	}
+
+	if _, err := parsePostCommentOptions([]string{"org", "proj", "repo", "1", "/a.go", "3", "Looks off.", "-", "-", "-", "replace"}); err == nil {
+		t.Fatalf("expected error for invalid onDuplicate")
+	}
+}
+
+func TestParsePostCommentOptions_UnquotedComment(t *testing.T) {
+	for _, args := range [][]string{
+		{"org", "proj", "repo", "1", "/a.go", "3", "Please", "check", "this"},
+		{"org", "proj", "repo", "1", "/a.go", "3", "Please", "none", "check", "this"},
+	} {
+		_, err := parsePostCommentOptions(args)
+		if err == nil || !strings.Contains(err.Error(), "pass it as one quoted argument") {
+			t.Fatalf("expected a quoting hint for %q, got %v", args[6:], err)
+		}
+	}
+}
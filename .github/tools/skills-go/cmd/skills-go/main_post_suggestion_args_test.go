@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestParsePostSuggestionOptions(t *testing.T) {
+	options, err := parsePostSuggestionOptions([]string{"org", "proj", "repo", "7", "/src/app.js", "12", "14", "const x = 1;", "Prefer const.", "3"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.StartLine != 12 || options.EndLine != 14 || options.Replacement != "const x = 1;" {
+		t.Fatalf("unexpected suggestion range/replacement: %#v", options)
+	}
+	if options.Comment != "Prefer const." || options.IterationID != "3" || options.ExpectedOriginal != nil {
+		t.Fatalf("unexpected optional fields: %#v", options)
+	}
+
+	options, err = parsePostSuggestionOptions([]string{"org", "proj", "repo", "7", "/src/app.js", "12", "12", "const x = 1;", "-", "-", "var x = 1;"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.IterationID != "" || options.ExpectedOriginal == nil || *options.ExpectedOriginal != "var x = 1;" {
+		t.Fatalf("expected the original text to be parsed, got %#v", options.ExpectedOriginal)
+	}
+
+	options, err = parsePostSuggestionOptions([]string{"org", "proj", "repo", "7", "/src/app.js", "12", "12", "const x = 1;", "-", "-", "-", "Update", "prefer-const"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.ExpectedOriginal != nil || options.OnDuplicate != "update" || options.Key != "prefer-const" {
+		t.Fatalf("unexpected duplicate options: %#v", options)
+	}
+	if _, err := parsePostSuggestionOptions([]string{"org", "proj", "repo", "7", "/src/app.js", "12", "12", "x", "-", "-", "-", "replace"}); err == nil {
+		t.Fatalf("expected error for invalid onDuplicate")
+	}
+}
+
+func TestParsePostSuggestionOptions_InvalidRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing replacement", args: []string{"org", "proj", "repo", "7", "/a.js", "1", "2"}, wantErr: "usage: skills-go post-pr-suggestion <organization> <project> <repositoryId> <pullRequestId> <filePath> <startLine> <endLine> <replacement> [comment] [iterationId] [expectedOriginal] [onDuplicate] [key]"},
+		{name: "zero start", args: []string{"org", "proj", "repo", "7", "/a.js", "0", "2", "x"}, wantErr: "startLine must be a positive integer"},
+		{name: "end before start", args: []string{"org", "proj", "repo", "7", "/a.js", "5", "2", "x"}, wantErr: "endLine must be an integer >= startLine"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parsePostSuggestionOptions(testCase.args)
+			if err == nil || err.Error() != testCase.wantErr {
+				t.Fatalf("expected error %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseReconcileOptions(t *testing.T) {
+	options, err := parseReconcileOptions([]string{"org", "proj", "repo", "7", "-", "true", "Fixed in the latest push.", "3, 5"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.IterationID != "" || !options.Resolve || options.Reply != "Fixed in the latest push." {
+		t.Fatalf("unexpected options: %#v", options)
+	}
+	if len(options.ThreadIDs) != 2 || options.ThreadIDs[0] != 3 || options.ThreadIDs[1] != 5 {
+		t.Fatalf("unexpected thread ids: %#v", options.ThreadIDs)
+	}
+
+	options, err = parseReconcileOptions([]string{"org", "proj", "repo", "7", "4"})
+	if err != nil || options.IterationID != "4" || options.Resolve || options.ThreadIDs != nil {
+		t.Fatalf("unexpected defaults: %#v, %v", options, err)
+	}
+}
+
+func TestParseReconcileOptions_Invalid(t *testing.T) {
+	if _, err := parseReconcileOptions([]string{"org", "proj", "repo"}); err == nil || err.Error() != usageReconcileThreads {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if _, err := parseReconcileOptions([]string{"org", "proj", "repo", "7", "-", "true", "-", "3,x"}); err == nil || err.Error() != "threadIds must be a comma-separated list of positive integers" {
+		t.Fatalf("expected threadIds error, got %v", err)
+	}
+}
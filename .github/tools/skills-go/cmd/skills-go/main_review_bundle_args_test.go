@@ -10,7 +10,7 @@ func TestParseReviewBundleOptions_InsufficientArgs(t *testing.T) {
 		t.Fatalf("expected usage error for insufficient args")
 	}
 
-	wantErr := "usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap]"
+	wantErr := "usage: skills-go get-pr-review-bundle <organization> <project> <repositoryId> <pullRequestId> [iterationId] [fileOffset] [fileLimit] [threadOffset] [threadLimit] [statusFilter] [excludeSystem] [includeLineMap] [excludeCategories] [tokenBudget] [chunkIndex] [hunkContext] [maxHunkFileBytes] [maxHunkTotalBytes] [includeEnclosingBody]"
 	if err.Error() != wantErr {
 		t.Fatalf("expected error %q, got %q", wantErr, err.Error())
 	}
@@ -87,3 +87,64 @@ func TestParseReviewBundleOptions_InvalidNumerics(t *testing.T) {
 		})
 	}
 }
+
+func TestParseReviewBundleOptions_ExcludeCategories(t *testing.T) {
+	args := []string{"org", "proj", "repo", "123", "", "0", "100", "0", "100", "", "true", "false", "generated,vendored"}
+	options, err := parseReviewBundleOptions(args)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !options.ExcludeCategories["generated"] || !options.ExcludeCategories["vendored"] || len(options.ExcludeCategories) != 2 {
+		t.Fatalf("unexpected exclude categories: %#v", options.ExcludeCategories)
+	}
+
+	args[12] = "images"
+	if _, err := parseReviewBundleOptions(args); err == nil {
+		t.Fatalf("expected error for unknown category")
+	}
+}
+
+func TestParseReviewBundleOptions_TokenBudgetAndChunkIndex(t *testing.T) {
+	args := []string{"org", "proj", "repo", "123", "", "0", "100", "0", "100", "", "true", "false", "-", "8000", "2"}
+	options, err := parseReviewBundleOptions(args)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.TokenBudget != 8000 || options.ChunkIndex != 2 {
+		t.Fatalf("unexpected chunk options: budget=%d index=%d", options.TokenBudget, options.ChunkIndex)
+	}
+
+	args[13] = "-5"
+	if _, err := parseReviewBundleOptions(args); err == nil || err.Error() != "tokenBudget must be a non-negative integer" {
+		t.Fatalf("expected tokenBudget error, got %v", err)
+	}
+	args[13] = "8000"
+	args[14] = "x"
+	if _, err := parseReviewBundleOptions(args); err == nil || err.Error() != "chunkIndex must be a non-negative integer" {
+		t.Fatalf("expected chunkIndex error, got %v", err)
+	}
+}
+
+func TestParseReviewBundleOptions_Hunks(t *testing.T) {
+	args := []string{"org", "proj", "repo", "123", "", "0", "100", "0", "100", "", "true", "false", "-", "-", "0", "5", "8000", "50000", "true"}
+	options, err := parseReviewBundleOptions(args)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !options.IncludeHunks || options.HunkContextLines != 5 || options.MaxHunkFileBytes != 8000 || options.MaxHunkTotalBytes != 50000 || !options.IncludeEnclosingBody {
+		t.Fatalf("unexpected hunk options: %+v", options)
+	}
+	if options.TokenBudget != 0 {
+		t.Fatalf("expected '-' tokenBudget to disable chunking, got %d", options.TokenBudget)
+	}
+
+	options, err = parseReviewBundleOptions(args[:15])
+	if err != nil || options.IncludeHunks {
+		t.Fatalf("expected hunks to be off by default, got %+v (%v)", options, err)
+	}
+
+	args[16] = "0"
+	if _, err := parseReviewBundleOptions(args); err == nil || err.Error() != "maxHunkFileBytes must be a positive integer" {
+		t.Fatalf("expected maxHunkFileBytes error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/search"
+)
+
+func TestParseSearchCodeOptions_Defaults(t *testing.T) {
+	options, err := parseSearchCodeOptions([]string{"org", "LoadConfig"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := search.CodeOptions{Top: search.DefaultTop, ContextLines: search.DefaultContextLines}
+	if !reflect.DeepEqual(options, want) {
+		t.Fatalf("unexpected defaults: %#v", options)
+	}
+
+	if _, err := parseSearchCodeOptions([]string{"org"}); err == nil || err.Error() != usageSearchCode {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestParseSearchCodeOptions_ExplicitValues(t *testing.T) {
+	options, err := parseSearchCodeOptions([]string{"org", "LoadConfig", "Platform", "api, web", "/src", "main,release", "50", "25", "none"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := search.CodeOptions{
+		Project:      "Platform",
+		Repositories: []string{"api", "web"},
+		Paths:        []string{"/src"},
+		Branches:     []string{"main", "release"},
+		Skip:         50,
+		Top:          25,
+		ContextLines: -1,
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Fatalf("unexpected options:\n got: %#v\nwant: %#v", options, want)
+	}
+}
+
+func TestParseSearchCodeOptions_InvalidValues(t *testing.T) {
+	testCases := [][]string{
+		{"org", "x", "-", "-", "-", "-", "-1"},
+		{"org", "x", "-", "-", "-", "-", "0", "0"},
+		{"org", "x", "-", "-", "-", "-", "0", "1001"},
+		{"org", "x", "-", "-", "-", "-", "0", "10", "-2"},
+	}
+	for _, args := range testCases {
+		if _, err := parseSearchCodeOptions(args); err == nil {
+			t.Fatalf("expected error for args %v", args)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
+)
+
+func TestParseSubmitReviewOptions(t *testing.T) {
+	if _, _, err := parseSubmitReviewOptions([]string{"org", "proj", "repo", "1"}); err == nil || err.Error() != usageSubmitReview {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+
+	options, file, err := parseSubmitReviewOptions([]string{"org", "proj", "repo", "1", "findings.json"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if file != "findings.json" || options.PositionMode != pullrequests.PositionModeValidate || options.DryRun || options.IterationID != "" {
+		t.Fatalf("unexpected defaults: %#v, %q", options, file)
+	}
+
+	options, file, err = parseSubmitReviewOptions([]string{"org", "proj", "repo", "1", "-", "snap", "4", "true"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if file != "-" || options.PositionMode != pullrequests.PositionModeSnap || options.IterationID != "4" || !options.DryRun {
+		t.Fatalf("unexpected explicit options: %#v, %q", options, file)
+	}
+
+	options, _, err = parseSubmitReviewOptions([]string{"org", "proj", "repo", "1", "f.json", "-", "-", "false", "update"})
+	if err != nil || options.OnDuplicate != pullrequests.DuplicateUpdate || options.PositionMode != pullrequests.PositionModeValidate {
+		t.Fatalf("unexpected duplicate policy options: %#v, %v", options, err)
+	}
+
+	if _, _, err := parseSubmitReviewOptions([]string{"org", "proj", "repo", "1", "f.json", "move"}); err == nil {
+		t.Fatalf("expected invalid positionMode to fail")
+	}
+}
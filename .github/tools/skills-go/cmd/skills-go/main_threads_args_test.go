@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseThreadFilter(t *testing.T) {
+	filter, err := parseThreadFilter([]string{"org", "proj", "repo", "7", "active", "true", "me", "/src/**", "3", "2024-05-01", "-", "false", "author", "true", "human,bot"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if filter.Status != "active" || !filter.ExcludeSystem || filter.Author != "me" || filter.PathGlob != "/src/**" || filter.Iteration != 3 {
+		t.Fatalf("unexpected filter: %#v", filter)
+	}
+	if !filter.CreatedSince.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !filter.UpdatedSince.IsZero() {
+		t.Fatalf("unexpected time bounds: %#v", filter)
+	}
+	if filter.HasReplies == nil || *filter.HasReplies || filter.LastCommentBy != "author" || !filter.Compact {
+		t.Fatalf("unexpected reply filters: %#v", filter)
+	}
+	if len(filter.Classes) != 2 || filter.Classes[0] != "human" || filter.Classes[1] != "bot" {
+		t.Fatalf("unexpected classes: %#v", filter.Classes)
+	}
+}
+
+func TestParseThreadFilter_Defaults(t *testing.T) {
+	filter, err := parseThreadFilter([]string{"org", "proj", "repo", "7"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if filter.Status != "" || filter.ExcludeSystem || filter.HasReplies != nil || filter.Compact {
+		t.Fatalf("unexpected defaults: %#v", filter)
+	}
+}
+
+func TestParseThreadFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing pull request", args: []string{"org", "proj", "repo"}, wantErr: usageGetPRThreads},
+		{name: "iteration", args: []string{"org", "proj", "repo", "7", "-", "-", "-", "-", "0"}, wantErr: "iteration must be a positive integer"},
+		{name: "hasReplies", args: []string{"org", "proj", "repo", "7", "-", "-", "-", "-", "-", "-", "-", "maybe"}, wantErr: "hasReplies must be true or false"},
+		{name: "classification", args: []string{"org", "proj", "repo", "7", "-", "-", "-", "-", "-", "-", "-", "-", "-", "-", "robot"}, wantErr: "classification must be a comma-separated list of: human, system, bot, self"},
+		{name: "lastCommentBy", args: []string{"org", "proj", "repo", "7", "-", "-", "-", "-", "-", "-", "-", "-", "bot"}, wantErr: "lastCommentBy must be one of: author, reviewer"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parseThreadFilter(testCase.args)
+			if err == nil || err.Error() != testCase.wantErr {
+				t.Fatalf("expected error %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
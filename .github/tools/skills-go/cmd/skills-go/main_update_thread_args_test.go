@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestParseParentCommentID(t *testing.T) {
+	for _, value := range []string{"", "-", " - "} {
+		if parsed, err := parseParentCommentID(value); err != nil || parsed != 0 {
+			t.Fatalf("expected default for %q, got %d, %v", value, parsed, err)
+		}
+	}
+	if parsed, err := parseParentCommentID(" 3 "); err != nil || parsed != 3 {
+		t.Fatalf("expected 3, got %d, %v", parsed, err)
+	}
+	for _, value := range []string{"0", "-2", "abc"} {
+		if _, err := parseParentCommentID(value); err == nil || err.Error() != "parentCommentId must be a positive integer" {
+			t.Fatalf("expected error for %q, got %v", value, err)
+		}
+	}
+}
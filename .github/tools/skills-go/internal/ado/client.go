@@ -70,6 +70,20 @@ func (c *Client) PatchJSON(rawURL string, body any, target any) error {
 	return c.doJSON(http.MethodPatch, rawURL, body, target)
 }
 
+func (c *Client) DeleteJSON(rawURL string, target any) error {
+	return c.doJSON(http.MethodDelete, rawURL, nil, target)
+}
+
+// GetBytes fetches a raw response body, for example a blob as application/octet-stream.
+func (c *Client) GetBytes(rawURL, accept string) ([]byte, error) {
+	return c.do(http.MethodGet, rawURL, nil, accept)
+}
+
+// PostBytes sends a JSON body and returns the raw response, for example a zip of blobs.
+func (c *Client) PostBytes(rawURL string, body any, accept string) ([]byte, error) {
+	return c.do(http.MethodPost, rawURL, body, accept)
+}
+
 func (c *Client) GetAuthenticatedUserID() (string, error) {
 	var payload struct {
 		AuthenticatedUser struct {
@@ -115,34 +129,54 @@ func NormalizeADOFilePath(path string) (string, error) {
 }
 
 func (c *Client) doJSON(method, rawURL string, body any, target any) error {
+	payload, err := c.do(method, rawURL, body, "")
+	if err != nil {
+		return err
+	}
+
+	if target == nil || len(payload) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(payload, target); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) do(method, rawURL string, body any, accept string) ([]byte, error) {
 	var reader io.Reader
 	if body != nil {
 		encoded, err := json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		reader = bytes.NewReader(encoded)
 	}
 
 	req, err := http.NewRequest(method, rawURL, reader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header = c.headers.Clone()
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	payload, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
@@ -150,16 +184,8 @@ func (c *Client) doJSON(method, rawURL string, body any, target any) error {
 		if message == "" {
 			message = resp.Status
 		}
-		return fmt.Errorf("request failed: %s", message)
+		return nil, fmt.Errorf("request failed: %s", message)
 	}
 
-	if target == nil || len(payload) == 0 {
-		return nil
-	}
-
-	if err := json.Unmarshal(payload, target); err != nil {
-		return err
-	}
-
-	return nil
+	return payload, nil
 }
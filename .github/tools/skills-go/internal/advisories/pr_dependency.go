@@ -64,7 +64,7 @@ func GetPRDependencyAdvisories(organization, project, repositoryID, pullRequestI
 		if err != nil {
 			continue
 		}
-		content := shared.TrimmedString(filePayload["content"])
+		content, _ := filePayload["content"].(string)
 		for _, dep := range parseDependencies(path, content) {
 			key := dep.Ecosystem + "|" + dep.Package + "|" + dep.Version
 			if seen[key] {
@@ -4,12 +4,62 @@ import (
 	"fmt"
 	"strings"
 
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
 	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
 	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
 	"ado-reviewer/.github/tools/skills-go/internal/shared"
+	"ado-reviewer/.github/tools/skills-go/internal/structdiff"
+	"ado-reviewer/.github/tools/skills-go/internal/symbols"
 )
 
-func MapPRDiffLines(organization, project, repositoryID, pullRequestID, iterationID string) (map[string]any, error) {
+const (
+	WhitespaceModeNone     = "none"
+	WhitespaceModeTrailing = "trailing"
+	WhitespaceModeAll      = "all"
+
+	// LineMapModeDiff maps each hunk from a line diff. LineMapModeSimple is used for files too
+	// large to diff and reports the whole file as one hunk.
+	LineMapModeDiff   = "diff"
+	LineMapModeSimple = "simple"
+
+	hunkContextLines     = 3
+	maxDiffLines         = 20000
+	maxStructuredChanges = 200
+)
+
+type Options struct {
+	WhitespaceMode string
+	IgnoreEOL      bool
+}
+
+func NormalizeWhitespaceMode(mode string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(mode))
+	switch normalized {
+	case "", "-", WhitespaceModeNone:
+		return WhitespaceModeNone, nil
+	case WhitespaceModeTrailing, WhitespaceModeAll:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("whitespaceMode must be one of: none, trailing, all")
+	}
+}
+
+func (o Options) diffOptions() linediff.Options {
+	return linediff.Options{
+		IgnoreWhitespace:         o.WhitespaceMode == WhitespaceModeAll,
+		IgnoreTrailingWhitespace: o.WhitespaceMode == WhitespaceModeTrailing,
+		IgnoreEOL:                o.IgnoreEOL,
+	}
+}
+
+func MapPRDiffLines(organization, project, repositoryID, pullRequestID, iterationID string, options Options) (map[string]any, error) {
+	whitespaceMode, err := NormalizeWhitespaceMode(options.WhitespaceMode)
+	if err != nil {
+		return nil, err
+	}
+	options.WhitespaceMode = whitespaceMode
+
 	prDetails, err := pullrequests.GetDetails(organization, project, repositoryID, pullRequestID)
 	if err != nil {
 		return nil, err
@@ -32,7 +82,7 @@ func MapPRDiffLines(organization, project, repositoryID, pullRequestID, iteratio
 		}
 	}
 
-	contentPaths := make([]string, 0, len(filesRaw))
+	blobs := make([]files.ChangedBlob, 0, len(filesRaw))
 	for _, raw := range filesRaw {
 		fileEntry, ok := raw.(map[string]any)
 		if !ok {
@@ -42,19 +92,17 @@ func MapPRDiffLines(organization, project, repositoryID, pullRequestID, iteratio
 			continue
 		}
 		path := shared.TrimmedString(fileEntry["path"])
-		if path == "" {
+		if path == "" || fileclass.ClassifyPath(path).Binary {
 			continue
 		}
-		contentPaths = append(contentPaths, path)
+		blobs = append(blobs, files.ChangedBlobFromEntry(fileEntry))
 	}
 
 	baseByPath := map[string]string{}
 	prByPath := map[string]string{}
-	if len(contentPaths) > 0 {
-		basePayload, _ := files.GetMultiple(organization, project, repositoryID, targetBranch, "branch", contentPaths)
-		prPayload, _ := files.GetMultiple(organization, project, repositoryID, sourceBranch, "branch", contentPaths)
-		baseByPath = files.ContentByPath(basePayload)
-		prByPath = files.ContentByPath(prPayload)
+	fetchStats := files.FetchStats{}
+	if len(blobs) > 0 {
+		baseByPath, prByPath, fetchStats = files.GetChangedContents(organization, project, repositoryID, blobs, targetBranch, sourceBranch)
 	}
 
 	mapped := make([]map[string]any, 0, len(filesRaw))
@@ -84,27 +132,152 @@ func MapPRDiffLines(organization, project, repositoryID, pullRequestID, iteratio
 			continue
 		}
 
+		pathClass := fileclass.ClassifyPath(path)
+		if pathClass.Binary {
+			changeType := shared.TrimmedString(fileEntry["changeType"])
+			pathClass.Apply(entry)
+			entry["baseExists"] = !strings.Contains(changeType, "add")
+			entry["prExists"] = !strings.Contains(changeType, "delete")
+			entry["lineMap"] = emptyLineMap()
+			entry["lineMapSkipped"] = fileclass.CategoryBinary
+			mapped = append(mapped, entry)
+			continue
+		}
+
 		baseContent, baseExists := baseByPath[path]
 		prContent, prExists := prByPath[path]
 
 		entry["baseExists"] = baseExists
 		entry["prExists"] = prExists
-		entry["lineMap"] = buildSimpleLineMap(baseContent, prContent)
+
+		sniffed := prContent
+		if !prExists {
+			sniffed = baseContent
+		}
+		classification := fileclass.Classify(path, sniffed)
+		classification.Apply(entry)
+		if reason := classification.SkipReason(); reason != "" {
+			entry["lineMap"] = emptyLineMap()
+			entry["lineMapSkipped"] = reason
+			mapped = append(mapped, entry)
+			continue
+		}
+		entry["lineMap"] = buildLineMap(path, baseContent, prContent, options)
+		entry["lineMapMode"] = lineMapMode(baseContent, prContent)
+		for key, value := range whitespaceFlags(baseContent, prContent, baseExists && prExists) {
+			entry[key] = value
+		}
+		if format := structdiff.FormatOf(path); format != "" && baseContent != prContent {
+			if structured, err := buildStructuredDiff(format, baseContent, prContent); err != nil {
+				entry["structuredDiffError"] = err.Error()
+			} else {
+				entry["structuredDiff"] = structured
+			}
+		}
 		mapped = append(mapped, entry)
 	}
 
 	return map[string]any{
-		"pullRequestId": pullRequestID,
-		"iterationId":   iterationID,
-		"sourceBranch":  sourceBranch,
-		"targetBranch":  targetBranch,
-		"count":         len(mapped),
-		"files":         mapped,
+		"pullRequestId":  pullRequestID,
+		"iterationId":    iterationID,
+		"sourceBranch":   sourceBranch,
+		"targetBranch":   targetBranch,
+		"whitespaceMode": options.WhitespaceMode,
+		"ignoreEol":      options.IgnoreEOL,
+		"contentFetch":   fetchStats.ToMap(),
+		"count":          len(mapped),
+		"files":          mapped,
+	}, nil
+}
+
+func lineMapMode(oldContent, newContent string) string {
+	if strings.Count(oldContent, "\n")+strings.Count(newContent, "\n") > maxDiffLines {
+		return LineMapModeSimple
+	}
+	return LineMapModeDiff
+}
+
+func buildLineMap(filePath, oldContent, newContent string, options Options) map[string]any {
+	if lineMapMode(oldContent, newContent) == LineMapModeSimple {
+		return buildSimpleLineMap(oldContent, newContent, options)
+	}
+
+	hunks := linediff.DiffWithOptions(oldContent, newContent, hunkContextLines, options.diffOptions())
+	locator := symbols.NewLocator(filePath, oldContent, newContent)
+	entries := make([]map[string]any, 0, len(hunks))
+	totalAdded, totalDeleted, totalContext := 0, 0, 0
+	for index, hunk := range hunks {
+		added, deleted, context := hunk.AddedLines(), hunk.DeletedLines(), hunk.ContextLines()
+		totalAdded += added
+		totalDeleted += deleted
+		totalContext += context
+		hunkEntry := map[string]any{
+			"index":        index + 1,
+			"oldStart":     hunk.OldStart,
+			"oldLines":     hunk.OldLines,
+			"newStart":     hunk.NewStart,
+			"newLines":     hunk.NewLines,
+			"addedLines":   added,
+			"deletedLines": deleted,
+			"contextLines": context,
+		}
+		if symbol, side, ok := locator.Locate(hunk); ok {
+			enclosing := symbol.ToMap()
+			enclosing["side"] = side
+			hunkEntry["enclosingSymbol"] = enclosing
+		}
+		entries = append(entries, hunkEntry)
+	}
+	return map[string]any{
+		"hunkCount":    len(entries),
+		"totalAdded":   totalAdded,
+		"totalDeleted": totalDeleted,
+		"totalContext": totalContext,
+		"hunks":        entries,
+	}
+}
+
+func buildStructuredDiff(format, oldContent, newContent string) (map[string]any, error) {
+	changes, err := structdiff.CompareContent(format, oldContent, newContent)
+	if err != nil {
+		return nil, err
+	}
+	total := len(changes)
+	truncated := total > maxStructuredChanges
+	if truncated {
+		changes = changes[:maxStructuredChanges]
+	}
+	entries := make([]map[string]any, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, change.ToMap())
+	}
+	return map[string]any{
+		"format":    format,
+		"count":     total,
+		"truncated": truncated,
+		"changes":   entries,
 	}, nil
 }
 
-func buildSimpleLineMap(oldContent, newContent string) map[string]any {
-	if oldContent == newContent {
+func whitespaceFlags(oldContent, newContent string, bothExist bool) map[string]any {
+	baseEnding := linediff.LineEnding(oldContent)
+	prEnding := linediff.LineEnding(newContent)
+	flags := map[string]any{
+		"baseLineEnding": baseEnding,
+		"prLineEnding":   prEnding,
+		"eolChanged":     bothExist && baseEnding != "none" && prEnding != "none" && baseEnding != prEnding,
+		"whitespaceOnly": false,
+	}
+	if bothExist && oldContent != newContent {
+		flags["whitespaceOnly"] = linediff.Equivalent(oldContent, newContent, linediff.Options{IgnoreWhitespace: true, IgnoreEOL: true})
+	}
+	return flags
+}
+
+// buildSimpleLineMap reports a changed file as one hunk spanning both versions. The whitespace
+// options only decide whether the file changed at all: line counts are not diffed.
+func buildSimpleLineMap(oldContent, newContent string, options Options) map[string]any {
+	if oldContent == newContent || linediff.Equivalent(oldContent, newContent, options.diffOptions()) {
 		return emptyLineMap()
 	}
 	oldLines := splitLines(oldContent)
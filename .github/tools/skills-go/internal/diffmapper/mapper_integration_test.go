@@ -24,7 +24,7 @@ func TestMapPRDiffLines_Live(t *testing.T) {
 		iterationID = latest
 	}
 
-	result, err := MapPRDiffLines(org, project, repositoryID, pullRequestID, iterationID)
+	result, err := MapPRDiffLines(org, project, repositoryID, pullRequestID, iterationID, Options{})
 	if err != nil {
 		t.Fatalf("MapPRDiffLines failed: %v", err)
 	}
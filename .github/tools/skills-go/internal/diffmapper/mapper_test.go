@@ -1,9 +1,12 @@
 package diffmapper
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestBuildSimpleLineMap_EqualContentReturnsEmpty(t *testing.T) {
-	result := buildSimpleLineMap("a\nb\n", "a\nb\n")
+	result := buildSimpleLineMap("a\nb\n", "a\nb\n", Options{})
 
 	if result["hunkCount"] != 0 {
 		t.Fatalf("expected hunkCount 0, got %v", result["hunkCount"])
@@ -14,7 +17,7 @@ func TestBuildSimpleLineMap_EqualContentReturnsEmpty(t *testing.T) {
 }
 
 func TestBuildSimpleLineMap_SameLineCountMarksReplace(t *testing.T) {
-	result := buildSimpleLineMap("a\nb\n", "x\ny\n")
+	result := buildSimpleLineMap("a\nb\n", "x\ny\n", Options{})
 
 	if result["hunkCount"] != 1 {
 		t.Fatalf("expected one hunk, got %v", result["hunkCount"])
@@ -33,7 +36,7 @@ func TestBuildSimpleLineMap_SameLineCountMarksReplace(t *testing.T) {
 }
 
 func TestBuildSimpleLineMap_AddedLines(t *testing.T) {
-	result := buildSimpleLineMap("a\n", "a\nb\nc\n")
+	result := buildSimpleLineMap("a\n", "a\nb\nc\n", Options{})
 
 	if result["totalAdded"] != 2 {
 		t.Fatalf("expected totalAdded 2, got %v", result["totalAdded"])
@@ -43,6 +46,28 @@ func TestBuildSimpleLineMap_AddedLines(t *testing.T) {
 	}
 }
 
+func TestBuildSimpleLineMap_AppliesWhitespaceOptions(t *testing.T) {
+	oldContent, newContent := "a\r\n  b\r\n", "a\n\tb\n"
+	if result := buildSimpleLineMap(oldContent, newContent, Options{}); result["hunkCount"] != 1 {
+		t.Fatalf("expected a hunk without whitespace options, got %v", result)
+	}
+	if result := buildSimpleLineMap(oldContent, newContent, Options{WhitespaceMode: WhitespaceModeAll, IgnoreEOL: true}); result["hunkCount"] != 0 {
+		t.Fatalf("expected no hunks when whitespace and line endings are ignored, got %v", result)
+	}
+	if result := buildSimpleLineMap(oldContent, newContent, Options{IgnoreEOL: true}); result["hunkCount"] != 1 {
+		t.Fatalf("expected indentation changes to remain when only line endings are ignored, got %v", result)
+	}
+}
+
+func TestLineMapMode(t *testing.T) {
+	if mode := lineMapMode("a\n", "b\n"); mode != LineMapModeDiff {
+		t.Fatalf("expected diff mode for small files, got %q", mode)
+	}
+	if mode := lineMapMode(strings.Repeat("x\n", maxDiffLines), "y\n"); mode != LineMapModeSimple {
+		t.Fatalf("expected simple mode above %d lines, got %q", maxDiffLines, mode)
+	}
+}
+
 func TestSplitLines_NormalizesCRLF(t *testing.T) {
 	lines := splitLines("a\r\nb\r\n")
 	if len(lines) != 3 {
@@ -61,3 +86,101 @@ func TestValidateInputs(t *testing.T) {
 		t.Fatal("expected error when required input is blank")
 	}
 }
+
+func TestBuildLineMap_ComputesRealHunks(t *testing.T) {
+	oldContent := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	newContent := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+
+	result := buildLineMap("/file.txt", oldContent, newContent, Options{})
+	if result["hunkCount"] != 2 {
+		t.Fatalf("expected two hunks, got %v", result["hunkCount"])
+	}
+	if result["totalAdded"] != 2 || result["totalDeleted"] != 1 {
+		t.Fatalf("unexpected totals: added=%v deleted=%v", result["totalAdded"], result["totalDeleted"])
+	}
+}
+
+func TestBuildLineMap_WhitespaceModes(t *testing.T) {
+	oldContent := "func main() {\n\tcall()\n}\n"
+	newContent := "func main() {  \n    call()\n}\n"
+
+	if result := buildLineMap("/file.txt", oldContent, newContent, Options{WhitespaceMode: WhitespaceModeNone}); result["hunkCount"] != 1 {
+		t.Fatalf("expected whitespace changes to be reported, got %v", result["hunkCount"])
+	}
+	if result := buildLineMap("/file.txt", oldContent, newContent, Options{WhitespaceMode: WhitespaceModeTrailing}); result["totalAdded"] != 1 {
+		t.Fatalf("expected only the re-indented line with trailing mode, got %v", result["totalAdded"])
+	}
+	if result := buildLineMap("/file.txt", oldContent, newContent, Options{WhitespaceMode: WhitespaceModeAll}); result["hunkCount"] != 0 {
+		t.Fatalf("expected no hunks when ignoring all whitespace, got %v", result["hunkCount"])
+	}
+}
+
+func TestBuildLineMap_IgnoreEOL(t *testing.T) {
+	oldContent := "a\r\nb\r\n"
+	newContent := "a\nb\n"
+
+	if result := buildLineMap("/file.txt", oldContent, newContent, Options{}); result["totalAdded"] != 2 {
+		t.Fatalf("expected CRLF to LF to change every line, got %v", result["totalAdded"])
+	}
+	if result := buildLineMap("/file.txt", oldContent, newContent, Options{IgnoreEOL: true}); result["hunkCount"] != 0 {
+		t.Fatalf("expected no hunks when ignoring EOL, got %v", result["hunkCount"])
+	}
+}
+
+func TestWhitespaceFlags(t *testing.T) {
+	flags := whitespaceFlags("a\r\n  b\r\n", "a\nb\n", true)
+	if flags["eolChanged"] != true || flags["whitespaceOnly"] != true {
+		t.Fatalf("expected eol change and whitespace-only flags, got %#v", flags)
+	}
+	if flags["baseLineEnding"] != "crlf" || flags["prLineEnding"] != "lf" {
+		t.Fatalf("unexpected line endings: %#v", flags)
+	}
+
+	flags = whitespaceFlags("a\n", "b\n", true)
+	if flags["eolChanged"] != false || flags["whitespaceOnly"] != false {
+		t.Fatalf("expected real change flags, got %#v", flags)
+	}
+
+	flags = whitespaceFlags("", "b\n", false)
+	if flags["whitespaceOnly"] != false {
+		t.Fatalf("expected added file not to be whitespace-only, got %#v", flags)
+	}
+}
+
+func TestNormalizeWhitespaceMode(t *testing.T) {
+	if mode, err := NormalizeWhitespaceMode(""); err != nil || mode != WhitespaceModeNone {
+		t.Fatalf("expected default none, got %q, %v", mode, err)
+	}
+	if mode, err := NormalizeWhitespaceMode("ALL"); err != nil || mode != WhitespaceModeAll {
+		t.Fatalf("expected all, got %q, %v", mode, err)
+	}
+	if _, err := NormalizeWhitespaceMode("some"); err == nil {
+		t.Fatal("expected error for unknown whitespace mode")
+	}
+}
+
+func TestBuildLineMap_AnnotatesEnclosingSymbol(t *testing.T) {
+	oldContent := "package sample\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Drop() {\n}\n"
+	newContent := "package sample\n\nfunc Add(a, b int) int {\n\treturn b + a\n}\n"
+
+	result := buildLineMap("/sample.go", oldContent, newContent, Options{})
+	hunks, _ := result["hunks"].([]map[string]any)
+	if len(hunks) != 1 {
+		t.Fatalf("expected one hunk, got %#v", result["hunks"])
+	}
+	symbol, ok := hunks[0]["enclosingSymbol"].(map[string]any)
+	if !ok || symbol["name"] != "Add" || symbol["kind"] != "function" || symbol["side"] != "pr" {
+		t.Fatalf("unexpected enclosing symbol: %#v", hunks[0]["enclosingSymbol"])
+	}
+}
+
+func TestBuildStructuredDiff_ReportsKeyPaths(t *testing.T) {
+	result, err := buildStructuredDiff("yaml", "http:\n  timeout: 30\n", "http:\n  timeout: 300\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes, _ := result["changes"].([]map[string]any)
+	if result["count"] != 1 || len(changes) != 1 || changes[0]["path"] != "/http/timeout" || changes[0]["op"] != "changed" {
+		t.Fatalf("unexpected structured diff: %#v", result)
+	}
+}
@@ -0,0 +1,311 @@
+package fileclass
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CategoryGenerated  = "generated"
+	CategoryVendored   = "vendored"
+	CategoryBinary     = "binary"
+	CategoryLFSPointer = "lfsPointer"
+
+	lfsPointerPrefix   = "version https://git-lfs.github.com/spec/v1"
+	sniffLength        = 8000
+	minifiedLineLength = 500
+)
+
+type Classification struct {
+	Language   string
+	Generated  bool
+	Vendored   bool
+	Binary     bool
+	LFSPointer bool
+}
+
+var generatedFileNames = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"go.sum":              true,
+	"cargo.lock":          true,
+	"poetry.lock":         true,
+	"pipfile.lock":        true,
+	"composer.lock":       true,
+	"gemfile.lock":        true,
+	"packages.lock.json":  true,
+}
+
+var generatedSuffixes = []string{
+	".min.js", ".min.css", ".min.mjs", ".js.map", ".css.map",
+	".designer.cs", ".designer.vb", ".g.cs", ".g.i.cs", ".generated.cs",
+	".pb.go", "_pb2.py", "_pb2_grpc.py", ".pb.cc", ".pb.h",
+	"_generated.go", ".generated.ts", ".d.ts.map",
+}
+
+var vendoredSegments = map[string]bool{
+	"vendor":           true,
+	"node_modules":     true,
+	"third_party":      true,
+	"third-party":      true,
+	"thirdparty":       true,
+	"bower_components": true,
+	".yarn":            true,
+}
+
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".ico": true, ".webp": true, ".tif": true, ".tiff": true, ".psd": true,
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".ppt": true, ".pptx": true,
+	".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".7z": true, ".rar": true, ".bz2": true, ".xz": true, ".nupkg": true, ".jar": true, ".war": true,
+	".dll": true, ".exe": true, ".so": true, ".dylib": true, ".a": true, ".lib": true, ".o": true, ".obj": true, ".pdb": true, ".class": true, ".pyc": true, ".wasm": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp3": true, ".mp4": true, ".wav": true, ".ogg": true, ".mov": true, ".avi": true, ".webm": true,
+	".snk": true, ".pfx": true, ".p12": true, ".keystore": true, ".db": true, ".sqlite": true,
+}
+
+var languagesByExtension = map[string]string{
+	".go": "go", ".cs": "csharp", ".vb": "vb", ".fs": "fsharp", ".java": "java", ".kt": "kotlin", ".kts": "kotlin", ".scala": "scala",
+	".js": "javascript", ".mjs": "javascript", ".cjs": "javascript", ".jsx": "javascript", ".ts": "typescript", ".tsx": "typescript",
+	".py": "python", ".rb": "ruby", ".php": "php", ".rs": "rust", ".swift": "swift", ".m": "objective-c",
+	".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".cxx": "cpp", ".hpp": "cpp",
+	".sh": "shell", ".bash": "shell", ".zsh": "shell", ".ps1": "powershell", ".psm1": "powershell", ".bat": "batch", ".cmd": "batch",
+	".sql": "sql", ".html": "html", ".htm": "html", ".css": "css", ".scss": "scss", ".less": "less", ".vue": "vue", ".svelte": "svelte",
+	".json": "json", ".yaml": "yaml", ".yml": "yaml", ".xml": "xml", ".toml": "toml", ".ini": "ini", ".md": "markdown", ".tf": "terraform", ".bicep": "bicep",
+	".csproj": "xml", ".props": "xml", ".targets": "xml", ".resx": "xml", ".proto": "protobuf", ".graphql": "graphql", ".dart": "dart", ".lua": "lua", ".r": "r",
+}
+
+var languagesByFileName = map[string]string{
+	"dockerfile":  "dockerfile",
+	"makefile":    "makefile",
+	"jenkinsfile": "groovy",
+	"go.mod":      "go-module",
+	"go.sum":      "go-module",
+}
+
+// goGeneratedHeader is the Go convention for generated files (https://go.dev/s/generatedcode).
+var goGeneratedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// generatedHeaders are the headers other generators write into a comment near the top of a file.
+var generatedHeaders = []string{
+	"<auto-generated", // .NET tools (T4, resgen, Roslyn source generators)
+	"@generated",      // Meta tooling, Cargo, Relay
+	"Generated by the protocol buffer compiler.", // protoc for C++, Python and others
+	"Autogenerated by Thrift Compiler",           // Apache Thrift
+}
+
+var commentPrefixes = []string{"//", "/*", "*", "#", "<!--", "--", ";", "'"}
+
+func ClassifyPath(filePath string) Classification {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(filePath), "\\", "/"))
+	name := path.Base(normalized)
+	ext := path.Ext(name)
+
+	result := Classification{Language: languagesByFileName[name]}
+	if result.Language == "" {
+		result.Language = languagesByExtension[ext]
+	}
+	if result.Language == "" && strings.HasPrefix(name, "dockerfile") {
+		result.Language = "dockerfile"
+	}
+
+	if generatedFileNames[name] {
+		result.Generated = true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			result.Generated = true
+			break
+		}
+	}
+	for _, segment := range strings.Split(path.Dir(normalized), "/") {
+		if vendoredSegments[segment] {
+			result.Vendored = true
+			break
+		}
+	}
+	if binaryExtensions[ext] {
+		result.Binary = true
+	}
+	return result
+}
+
+func Classify(filePath, content string) Classification {
+	result := ClassifyPath(filePath)
+	if content == "" {
+		return result
+	}
+
+	if strings.HasPrefix(content, lfsPointerPrefix) {
+		result.LFSPointer = true
+		return result
+	}
+	if LooksBinary(content) {
+		result.Binary = true
+		return result
+	}
+	if hasGeneratedMarker(content) || looksMinified(filePath, content) {
+		result.Generated = true
+	}
+	return result
+}
+
+func (c Classification) Categories() []string {
+	categories := make([]string, 0, 4)
+	if c.Generated {
+		categories = append(categories, CategoryGenerated)
+	}
+	if c.Vendored {
+		categories = append(categories, CategoryVendored)
+	}
+	if c.Binary {
+		categories = append(categories, CategoryBinary)
+	}
+	if c.LFSPointer {
+		categories = append(categories, CategoryLFSPointer)
+	}
+	return categories
+}
+
+func (c Classification) MatchesAny(categories map[string]bool) bool {
+	for _, category := range c.Categories() {
+		if categories[category] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Classification) Apply(entry map[string]any) {
+	entry["language"] = c.Language
+	entry["generated"] = c.Generated
+	entry["vendored"] = c.Vendored
+	entry["binary"] = c.Binary
+	entry["lfsPointer"] = c.LFSPointer
+}
+
+func (c Classification) SkipReason() string {
+	switch {
+	case c.LFSPointer:
+		return CategoryLFSPointer
+	case c.Binary:
+		return CategoryBinary
+	case c.Generated:
+		return CategoryGenerated
+	case c.Vendored:
+		return CategoryVendored
+	default:
+		return ""
+	}
+}
+
+func ParseCategories(value string) (map[string]bool, error) {
+	categories := map[string]bool{}
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "-" || strings.EqualFold(trimmed, "none") {
+		return categories, nil
+	}
+	for _, raw := range strings.Split(trimmed, ",") {
+		switch strings.ToLower(strings.TrimSpace(raw)) {
+		case "":
+			continue
+		case "generated":
+			categories[CategoryGenerated] = true
+		case "vendored":
+			categories[CategoryVendored] = true
+		case "binary":
+			categories[CategoryBinary] = true
+		case "lfspointer", "lfs":
+			categories[CategoryLFSPointer] = true
+		case "all":
+			categories[CategoryGenerated] = true
+			categories[CategoryVendored] = true
+			categories[CategoryBinary] = true
+			categories[CategoryLFSPointer] = true
+		default:
+			return nil, fmt.Errorf("unknown file category '%s' (supported: generated, vendored, binary, lfsPointer, all)", strings.TrimSpace(raw))
+		}
+	}
+	return categories, nil
+}
+
+// LooksBinary reports whether content has NUL bytes or mostly invalid UTF-8 in its first 8000 bytes.
+func LooksBinary(content string) bool {
+	sample := content
+	if len(sample) > sniffLength {
+		sample = sample[:sniffLength]
+	}
+	if strings.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	if utf8.ValidString(sample) {
+		return false
+	}
+	invalid := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRuneInString(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			invalid++
+		}
+		i += size
+	}
+	return invalid*10 > len(sample)
+}
+
+// hasGeneratedMarker looks for a generator header on the comment lines of the first 8000 bytes. Prose
+// such as "do not edit" in a README or a plain comment does not count.
+func hasGeneratedMarker(content string) bool {
+	header := content
+	if len(header) > sniffLength {
+		header = header[:sniffLength]
+	}
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if goGeneratedHeader.MatchString(line) {
+			return true
+		}
+		if !isCommentLine(line) {
+			continue
+		}
+		for _, marker := range generatedHeaders {
+			if strings.Contains(line, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var minifiableExtensions = map[string]bool{".js": true, ".mjs": true, ".cjs": true, ".css": true}
+
+// looksMinified reports whether a script or stylesheet is packed into a few very long lines. Other
+// file types (one-line JSON, long Markdown paragraphs, SQL) are never treated as minified.
+func looksMinified(filePath, content string) bool {
+	if !minifiableExtensions[strings.ToLower(path.Ext(filePath))] {
+		return false
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) > 5 {
+		return false
+	}
+	for _, line := range lines {
+		if len(line) > minifiedLineLength {
+			return true
+		}
+	}
+	return false
+}
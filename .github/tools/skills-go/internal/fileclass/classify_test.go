@@ -0,0 +1,111 @@
+package fileclass
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClassifyPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Classification
+	}{
+		{path: "/src/app.ts", want: Classification{Language: "typescript"}},
+		{path: "/package-lock.json", want: Classification{Language: "json", Generated: true}},
+		{path: "/web/dist/site.min.js", want: Classification{Language: "javascript", Generated: true}},
+		{path: "/UI/Form1.Designer.cs", want: Classification{Language: "csharp", Generated: true}},
+		{path: "/vendor/github.com/x/y.go", want: Classification{Language: "go", Vendored: true}},
+		{path: "/web/node_modules/lib/index.js", want: Classification{Language: "javascript", Vendored: true}},
+		{path: "/assets/logo.PNG", want: Classification{Binary: true}},
+		{path: "/build/Dockerfile.prod", want: Classification{Language: "dockerfile"}},
+	}
+
+	for _, testCase := range tests {
+		if got := ClassifyPath(testCase.path); got != testCase.want {
+			t.Fatalf("ClassifyPath(%q) = %+v, want %+v", testCase.path, got, testCase.want)
+		}
+	}
+}
+
+func TestClassify_ContentSniffing(t *testing.T) {
+	lfs := "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 123\n"
+	if got := Classify("/assets/model.onnx", lfs); !got.LFSPointer {
+		t.Fatalf("expected LFS pointer, got %+v", got)
+	}
+	if got := Classify("/data/blob.dat", "abc\x00def"); !got.Binary {
+		t.Fatalf("expected binary content, got %+v", got)
+	}
+	if got := Classify("/api/client.go", "// Code generated by mockgen. DO NOT EDIT.\npackage api\n"); !got.Generated {
+		t.Fatalf("expected generated marker detection, got %+v", got)
+	}
+	if got := Classify("/Properties/Resources.Designer.vb", "' <auto-generated>\r\n'     This code was generated by a tool.\r\n"); !got.Generated {
+		t.Fatalf("expected the .NET header to be detected, got %+v", got)
+	}
+	if got := Classify("/gen/user_pb2.py", "# -*- coding: utf-8 -*-\n# Generated by the protocol buffer compiler.  DO NOT EDIT!\n"); !got.Generated {
+		t.Fatalf("expected the protoc header to be detected, got %+v", got)
+	}
+	for _, content := range []string{
+		"# Client\n\nThis file is generated by hand. Do not edit the code generated by the build.\n",
+		"package api\n\n// Code generated files must not be edited by hand; DO NOT EDIT them.\n",
+		"package api\n\nconst doc = \"// Code generated by hand\"\n",
+	} {
+		if got := Classify("/api/notes.go", content); got.Generated {
+			t.Fatalf("expected prose not to mark the file generated: %q", content)
+		}
+	}
+	if got := ClassifyPath("/internal/color_string.go"); got.Generated {
+		t.Fatalf("expected _string.go files not to be generated by path alone, got %+v", got)
+	}
+	if got := Classify("/static/bundle.js", strings.Repeat("a=1;", 200)); !got.Generated {
+		t.Fatalf("expected minified detection, got %+v", got)
+	}
+	if got := Classify("/static/theme.CSS", strings.Repeat("a{b:c}", 100)); !got.Generated {
+		t.Fatalf("expected minified stylesheet detection, got %+v", got)
+	}
+	for _, filePath := range []string{"/config/settings.json", "/docs/intro.md", "/db/seed.sql"} {
+		if got := Classify(filePath, strings.Repeat("word ", 120)+"\n"); got.Generated {
+			t.Fatalf("expected long single-line %s not to count as minified, got %+v", filePath, got)
+		}
+	}
+	if got := Classify("/src/main.go", "package main\n\nfunc main() {}\n"); got.Generated || got.Binary || got.LFSPointer {
+		t.Fatalf("expected hand-written source, got %+v", got)
+	}
+}
+
+func TestParseCategories(t *testing.T) {
+	got, err := ParseCategories("generated, LFS ,binary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]bool{CategoryGenerated: true, CategoryLFSPointer: true, CategoryBinary: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected categories: %#v", got)
+	}
+
+	if all, _ := ParseCategories("all"); len(all) != 4 {
+		t.Fatalf("expected all four categories, got %#v", all)
+	}
+	if none, _ := ParseCategories("-"); len(none) != 0 {
+		t.Fatalf("expected no categories, got %#v", none)
+	}
+	if _, err := ParseCategories("tests"); err == nil {
+		t.Fatal("expected error for unknown category")
+	}
+}
+
+func TestClassification_MatchesAnyAndSkipReason(t *testing.T) {
+	vendoredGenerated := Classification{Generated: true, Vendored: true}
+	if !vendoredGenerated.MatchesAny(map[string]bool{CategoryVendored: true}) {
+		t.Fatal("expected vendored match")
+	}
+	if vendoredGenerated.MatchesAny(map[string]bool{CategoryBinary: true}) {
+		t.Fatal("did not expect binary match")
+	}
+	if vendoredGenerated.SkipReason() != CategoryGenerated {
+		t.Fatalf("unexpected skip reason: %q", vendoredGenerated.SkipReason())
+	}
+	if (Classification{Language: "go"}).SkipReason() != "" {
+		t.Fatal("expected no skip reason for source files")
+	}
+}
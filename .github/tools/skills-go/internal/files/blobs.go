@@ -0,0 +1,253 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const maxBlobBatchSize = 100
+
+// ChangedBlob describes one changed file of a PR iteration with the blob IDs of both versions.
+type ChangedBlob struct {
+	Path             string
+	ChangeType       string
+	ObjectID         string
+	OriginalObjectID string
+}
+
+// FetchStats counts the requests issued by GetChangedContents.
+type FetchStats struct {
+	BatchRequests int
+	BlobRequests  int
+	PathRequests  int
+	Failed        int
+}
+
+func ChangedBlobFromEntry(entry map[string]any) ChangedBlob {
+	return ChangedBlob{
+		Path:             shared.TrimmedString(entry["path"]),
+		ChangeType:       strings.ToLower(shared.TrimmedString(entry["changeType"])),
+		ObjectID:         strings.ToLower(shared.TrimmedString(entry["objectId"])),
+		OriginalObjectID: strings.ToLower(shared.TrimmedString(entry["originalObjectId"])),
+	}
+}
+
+func (b ChangedBlob) hasBase() bool {
+	return !strings.Contains(b.ChangeType, "add")
+}
+
+func (b ChangedBlob) hasPR() bool {
+	return !strings.Contains(b.ChangeType, "delete")
+}
+
+func (b ChangedBlob) baseObjectID() string {
+	if !b.hasBase() {
+		return ""
+	}
+	if b.OriginalObjectID != "" {
+		return b.OriginalObjectID
+	}
+	if !b.hasPR() {
+		return b.ObjectID
+	}
+	return ""
+}
+
+func (b ChangedBlob) prObjectID() string {
+	if !b.hasPR() {
+		return ""
+	}
+	return b.ObjectID
+}
+
+func (s FetchStats) ToMap() map[string]any {
+	return map[string]any{
+		"batchRequests": s.BatchRequests,
+		"blobRequests":  s.BlobRequests,
+		"pathRequests":  s.PathRequests,
+		"failed":        s.Failed,
+	}
+}
+
+// GetChangedContents loads the base and PR versions of changed files. Blobs are fetched by object ID
+// through the blobs batch endpoint; blobs missing from the batch are fetched one by one, and files
+// without an object ID fall back to an untruncated path lookup at baseVersion/prVersion (branch names).
+func GetChangedContents(organization, project, repositoryID string, blobs []ChangedBlob, baseVersion, prVersion string) (map[string]string, map[string]string, FetchStats) {
+	stats := FetchStats{}
+	base := map[string]string{}
+	pr := map[string]string{}
+
+	objectIDs := make([]string, 0, len(blobs)*2)
+	seen := map[string]bool{}
+	for _, blob := range blobs {
+		for _, objectID := range []string{blob.baseObjectID(), blob.prObjectID()} {
+			if objectID != "" && !seen[objectID] {
+				seen[objectID] = true
+				objectIDs = append(objectIDs, objectID)
+			}
+		}
+	}
+
+	contentByID := map[string][]byte{}
+	client, clientErr := ado.NewClient(organization)
+	if clientErr == nil {
+		for _, batch := range chunkObjectIDs(objectIDs, maxBlobBatchSize) {
+			stats.BatchRequests++
+			fetched, err := fetchBlobBatch(client, project, repositoryID, batch)
+			if err != nil {
+				continue
+			}
+			for objectID, content := range fetched {
+				contentByID[objectID] = content
+			}
+		}
+
+		missing := make([]string, 0)
+		for _, objectID := range objectIDs {
+			if _, ok := contentByID[objectID]; !ok {
+				missing = append(missing, objectID)
+			}
+		}
+		stats.BlobRequests = len(missing)
+		for objectID, content := range fetchBlobsIndividually(client, project, repositoryID, missing) {
+			contentByID[objectID] = content
+		}
+	}
+
+	basePaths := make([]string, 0)
+	prPaths := make([]string, 0)
+	for _, blob := range blobs {
+		if blob.hasBase() {
+			if content, ok := contentByID[blob.baseObjectID()]; ok && blob.baseObjectID() != "" {
+				base[blob.Path] = blobText(content)
+			} else if baseVersion != "" {
+				basePaths = append(basePaths, blob.Path)
+			}
+		}
+		if blob.hasPR() {
+			if content, ok := contentByID[blob.prObjectID()]; ok && blob.prObjectID() != "" {
+				pr[blob.Path] = blobText(content)
+			} else if prVersion != "" {
+				prPaths = append(prPaths, blob.Path)
+			}
+		}
+	}
+
+	stats.PathRequests = len(basePaths) + len(prPaths)
+	if len(basePaths) > 0 {
+		for filePath, content := range getTextContents(organization, project, repositoryID, baseVersion, "branch", basePaths) {
+			base[filePath] = content
+		}
+	}
+	if len(prPaths) > 0 {
+		for filePath, content := range getTextContents(organization, project, repositoryID, prVersion, "branch", prPaths) {
+			pr[filePath] = content
+		}
+	}
+
+	for _, blob := range blobs {
+		if _, ok := base[blob.Path]; blob.hasBase() && !ok {
+			stats.Failed++
+		}
+		if _, ok := pr[blob.Path]; blob.hasPR() && !ok {
+			stats.Failed++
+		}
+	}
+	return base, pr, stats
+}
+
+// blobText decodes a text blob; binary blobs keep their raw bytes so content sniffing still flags them.
+func blobText(content []byte) string {
+	decoded := Decode(content, 0)
+	if decoded.IsBinary {
+		return string(content)
+	}
+	return decoded.Text
+}
+
+func fetchBlobBatch(client *ado.Client, project, repositoryID string, objectIDs []string) (map[string][]byte, error) {
+	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/blobs?$format=zip&api-version=7.2-preview", client.EncodedOrg, url.PathEscape(strings.TrimSpace(project)), url.PathEscape(strings.TrimSpace(repositoryID)))
+	archive, err := client.PostBytes(apiURL, objectIDs, "application/zip")
+	if err != nil {
+		return nil, err
+	}
+	return readBlobArchive(archive)
+}
+
+func fetchBlobsIndividually(client *ado.Client, project, repositoryID string, objectIDs []string) map[string][]byte {
+	results := make([][]byte, len(objectIDs))
+	semaphore := make(chan struct{}, maxParallelContentRequests)
+	var wg sync.WaitGroup
+	for index, objectID := range objectIDs {
+		index := index
+		objectID := objectID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/blobs/%s?$format=octetstream&api-version=7.2-preview", client.EncodedOrg, url.PathEscape(strings.TrimSpace(project)), url.PathEscape(strings.TrimSpace(repositoryID)), url.PathEscape(objectID))
+			content, err := client.GetBytes(apiURL, "application/octet-stream")
+			if err == nil {
+				results[index] = content
+			}
+		}()
+	}
+	wg.Wait()
+
+	fetched := map[string][]byte{}
+	for index, content := range results {
+		if content != nil {
+			fetched[objectIDs[index]] = content
+		}
+	}
+	return fetched
+}
+
+// readBlobArchive maps the entries of a blobs zip, which are named after their object IDs.
+func readBlobArchive(archive []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	blobs := map[string][]byte{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		opened, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(opened)
+		opened.Close()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(path.Base(file.Name))
+		blobs[strings.TrimSuffix(name, path.Ext(name))] = content
+	}
+	return blobs, nil
+}
+
+func chunkObjectIDs(objectIDs []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(objectIDs)+size-1)/size)
+	for start := 0; start < len(objectIDs); start += size {
+		end := start + size
+		if end > len(objectIDs) {
+			end = len(objectIDs)
+		}
+		chunks = append(chunks, objectIDs[start:end])
+	}
+	return chunks
+}
@@ -0,0 +1,70 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestChangedBlob_ObjectIDsBySide(t *testing.T) {
+	edit := ChangedBlobFromEntry(map[string]any{"path": "/a.go", "changeType": "edit", "objectId": "BBB", "originalObjectId": "aaa"})
+	if edit.baseObjectID() != "aaa" || edit.prObjectID() != "bbb" {
+		t.Fatalf("unexpected edit object IDs: %+v", edit)
+	}
+
+	added := ChangedBlobFromEntry(map[string]any{"path": "/b.go", "changeType": "add", "objectId": "ccc"})
+	if added.hasBase() || added.baseObjectID() != "" || added.prObjectID() != "ccc" {
+		t.Fatalf("unexpected add object IDs: %+v", added)
+	}
+
+	deleted := ChangedBlobFromEntry(map[string]any{"path": "/c.go", "changeType": "delete", "objectId": "ddd"})
+	if deleted.hasPR() || deleted.baseObjectID() != "ddd" || deleted.prObjectID() != "" {
+		t.Fatalf("unexpected delete object IDs: %+v", deleted)
+	}
+
+	editWithoutOriginal := ChangedBlobFromEntry(map[string]any{"path": "/d.go", "changeType": "edit", "objectId": "eee"})
+	if editWithoutOriginal.baseObjectID() != "" {
+		t.Fatalf("expected edit without originalObjectId to fall back to a path lookup")
+	}
+}
+
+func TestReadBlobArchive(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, content := range map[string]string{"abc123": "first\n", "DEF456": "  second"} {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	blobs, err := readBlobArchive(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(blobs["abc123"]) != "first\n" || string(blobs["def456"]) != "  second" {
+		t.Fatalf("unexpected blobs: %#v", blobs)
+	}
+
+	if _, err := readBlobArchive([]byte("not a zip")); err == nil {
+		t.Fatalf("expected error for invalid archive")
+	}
+}
+
+func TestChunkObjectIDs(t *testing.T) {
+	chunks := chunkObjectIDs([]string{"a", "b", "c", "d", "e"}, 2)
+	want := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("unexpected chunks: %#v", chunks)
+	}
+	if len(chunkObjectIDs(nil, 2)) != 0 {
+		t.Fatalf("expected no chunks for empty input")
+	}
+}
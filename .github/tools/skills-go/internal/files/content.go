@@ -6,9 +6,24 @@ import (
 	"strings"
 
 	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
 )
 
+// GetContent fetches the full decoded content of a file, for callers that need every line, such as
+// comment range validation. Commands that print content use GetContentRanges with a byte limit.
 func GetContent(organization, project, repositoryID, path, version, versionType string) (map[string]any, error) {
+	return GetContentLimited(organization, project, repositoryID, path, version, versionType, 0)
+}
+
+// GetContentLimited fetches a file and replaces its content with exact decoded text (see Decode),
+// adding encoding, size, isBinary and truncated. maxBytes <= 0 disables the size limit.
+func GetContentLimited(organization, project, repositoryID, path, version, versionType string, maxBytes int) (map[string]any, error) {
+	return getItemContent(organization, project, repositoryID, path, version, versionType, maxBytes, false)
+}
+
+// getItemContent fetches and decodes a file. With blobSize, size is replaced by the byte size of the
+// stored blob; the decoded size is kept, with sizeError, when that lookup fails.
+func getItemContent(organization, project, repositoryID, path, version, versionType string, maxBytes int, blobSize bool) (map[string]any, error) {
 	client, err := ado.NewClient(organization)
 	if err != nil {
 		return nil, err
@@ -31,7 +46,7 @@ func GetContent(organization, project, repositoryID, path, version, versionType
 		return nil, fmt.Errorf("path is required")
 	}
 
-	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/items?path=%s&includeContent=true&api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), url.QueryEscape(normalizedPath))
+	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/items?path=%s&includeContent=true&includeContentMetadata=true&api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), url.QueryEscape(normalizedPath))
 	if strings.TrimSpace(version) != "" {
 		if strings.TrimSpace(versionType) == "" {
 			versionType = "branch"
@@ -43,5 +58,46 @@ func GetContent(organization, project, repositoryID, path, version, versionType
 	if err := client.GetJSON(apiURL, &response); err != nil {
 		return nil, err
 	}
+	decodeItemContent(response, maxBytes)
+	if objectID := shared.TrimmedString(response["objectId"]); blobSize && objectID != "" {
+		var blob struct {
+			Size *int `json:"size"`
+		}
+		blobURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/blobs/%s?$format=json&api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), url.PathEscape(objectID))
+		if err := client.GetJSON(blobURL, &blob); err != nil {
+			response["sizeError"] = err.Error()
+		} else if blob.Size != nil {
+			response["size"] = *blob.Size
+		}
+	}
+	return response, nil
+}
+
+// GetContentRanges fetches a file and, when ranges are given, returns numbered snippets for those
+// lines instead of the full content (see ApplySnippets). maxBytes only limits full-content results.
+func GetContentRanges(organization, project, repositoryID, path, version, versionType string, ranges []LineRange, maxBytes int) (map[string]any, error) {
+	if len(ranges) > 0 {
+		maxBytes = 0
+	}
+	response, err := getItemContent(organization, project, repositoryID, path, version, versionType, maxBytes, true)
+	if err != nil {
+		return nil, err
+	}
+	ApplySnippets(response, ranges)
 	return response, nil
 }
+
+func decodeItemContent(response map[string]any, maxBytes int) {
+	content, _ := response["content"].(string)
+	decoded := Decode([]byte(content), maxBytes)
+	if metadata, ok := response["contentMetadata"].(map[string]any); ok {
+		if isBinary, _ := metadata["isBinary"].(bool); isBinary {
+			decoded = DecodedContent{Encoding: EncodingBinary, Size: len(content), IsBinary: true}
+		} else if codePage, ok := metadata["encoding"].(float64); ok && decoded.Encoding == EncodingUTF8 {
+			if encoding := EncodingForCodePage(int(codePage)); encoding != "" {
+				decoded.Encoding = encoding
+			}
+		}
+	}
+	decoded.Apply(response)
+}
@@ -23,16 +23,21 @@ func TestContentByPath(t *testing.T) {
 		"results": []any{
 			map[string]any{"path": "/ok.txt", "status": "ok", "content": "hello"},
 			map[string]any{"path": "/err.txt", "status": "error", "error": "boom"},
+			map[string]any{"path": "/image.png", "status": "ok", "content": "", "isBinary": true},
+			map[string]any{"path": "/spaced.txt", "status": "ok", "content": "\n  first\n"},
 		},
 	}
 
 	content := ContentByPath(payload)
-	if len(content) != 1 {
-		t.Fatalf("expected 1 successful entry, got %d", len(content))
+	if len(content) != 2 {
+		t.Fatalf("expected 2 text entries, got %d", len(content))
 	}
 	if content["/ok.txt"] != "hello" {
 		t.Fatalf("expected /ok.txt content to be hello, got %q", content["/ok.txt"])
 	}
+	if content["/spaced.txt"] != "\n  first\n" {
+		t.Fatalf("expected whitespace to be preserved, got %q", content["/spaced.txt"])
+	}
 }
 
 func TestGetMultiple_EmptyPaths(t *testing.T) {
@@ -0,0 +1,154 @@
+package files
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+)
+
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingLatin1  = "latin-1"
+	EncodingBinary  = "binary"
+
+	DefaultMaxContentBytes = 1 << 20
+)
+
+var codePageEncodings = map[int]string{
+	65001: EncodingUTF8,
+	1200:  EncodingUTF16LE,
+	1201:  EncodingUTF16BE,
+	28591: EncodingLatin1,
+	1252:  EncodingLatin1,
+}
+
+type DecodedContent struct {
+	Text      string
+	Encoding  string
+	Size      int
+	IsBinary  bool
+	Truncated bool
+}
+
+// Decode turns raw file bytes into text without altering line content: BOMs are removed, UTF-16
+// (with or without BOM) is converted to UTF-8, and invalid UTF-8 is read as Latin-1. Binary content
+// yields empty Text. When maxBytes is positive, Text is cut to at most maxBytes on a rune boundary.
+func Decode(raw []byte, maxBytes int) DecodedContent {
+	decoded := DecodedContent{Size: len(raw), Encoding: EncodingUTF8}
+	switch {
+	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
+		decoded.Encoding = EncodingUTF8BOM
+		decoded.Text = string(raw[3:])
+	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
+		decoded.Encoding = EncodingUTF16LE
+		decoded.Text = decodeUTF16(raw[2:], false)
+	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}):
+		decoded.Encoding = EncodingUTF16BE
+		decoded.Text = decodeUTF16(raw[2:], true)
+	default:
+		if bigEndian, ok := looksUTF16(raw); ok {
+			decoded.Encoding = EncodingUTF16LE
+			if bigEndian {
+				decoded.Encoding = EncodingUTF16BE
+			}
+			decoded.Text = decodeUTF16(raw, bigEndian)
+			break
+		}
+		text := string(raw)
+		if fileclass.LooksBinary(text) {
+			decoded.Encoding = EncodingBinary
+			decoded.IsBinary = true
+			return decoded
+		}
+		if !utf8.ValidString(text) {
+			decoded.Encoding = EncodingLatin1
+			text = decodeLatin1(raw)
+		}
+		decoded.Text = text
+	}
+
+	decoded.Text, decoded.Truncated = truncateText(decoded.Text, maxBytes)
+	return decoded
+}
+
+// truncateText cuts text to at most maxBytes on a rune boundary; maxBytes <= 0 means no limit.
+func truncateText(text string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut], true
+}
+
+// EncodingForCodePage maps a Windows code page reported by Azure DevOps content metadata to an encoding name.
+func EncodingForCodePage(codePage int) string {
+	return codePageEncodings[codePage]
+}
+
+// Apply writes the decoded text and its metadata into a file result entry.
+func (d DecodedContent) Apply(entry map[string]any) {
+	entry["content"] = d.Text
+	entry["encoding"] = d.Encoding
+	entry["size"] = d.Size
+	entry["isBinary"] = d.IsBinary
+	entry["truncated"] = d.Truncated
+}
+
+// looksUTF16 detects BOM-less UTF-16 text by the NUL bytes that ASCII characters leave in every other byte.
+func looksUTF16(raw []byte) (bool, bool) {
+	sample := raw
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	if len(sample) < 4 || len(sample)%2 != 0 {
+		return false, false
+	}
+	evenZeros, oddZeros := 0, 0
+	for index, value := range sample {
+		if value != 0 {
+			continue
+		}
+		if index%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case oddZeros*10 >= pairs*7 && evenZeros == 0:
+		return false, true
+	case evenZeros*10 >= pairs*7 && oddZeros == 0:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func decodeUTF16(raw []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(raw)/2)
+	for index := 0; index+1 < len(raw); index += 2 {
+		if bigEndian {
+			units = append(units, uint16(raw[index])<<8|uint16(raw[index+1]))
+		} else {
+			units = append(units, uint16(raw[index+1])<<8|uint16(raw[index]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+func decodeLatin1(raw []byte) string {
+	runes := make([]rune, len(raw))
+	for index, value := range raw {
+		runes[index] = rune(value)
+	}
+	return string(runes)
+}
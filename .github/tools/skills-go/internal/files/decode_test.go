@@ -0,0 +1,77 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		name         string
+		raw          []byte
+		wantText     string
+		wantEncoding string
+		wantBinary   bool
+	}{
+		{name: "plain utf-8 keeps leading blank lines", raw: []byte("\n\n  indented\n"), wantText: "\n\n  indented\n", wantEncoding: EncodingUTF8},
+		{name: "utf-8 bom stripped", raw: []byte("\xEF\xBB\xBFhello"), wantText: "hello", wantEncoding: EncodingUTF8BOM},
+		{name: "utf-16le with bom", raw: []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, wantText: "hi", wantEncoding: EncodingUTF16LE},
+		{name: "utf-16be with bom", raw: []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, wantText: "hi", wantEncoding: EncodingUTF16BE},
+		{name: "utf-16le without bom", raw: []byte{'a', 0, 'b', 0, '\n', 0}, wantText: "ab\n", wantEncoding: EncodingUTF16LE},
+		{name: "utf-16be without bom", raw: []byte{0, 'a', 0, 'b', 0, '\n'}, wantText: "ab\n", wantEncoding: EncodingUTF16BE},
+		{name: "latin-1 fallback", raw: []byte("Le caf\xE9 est tr\xE8s bon aujourd'hui."), wantText: "Le café est très bon aujourd'hui.", wantEncoding: EncodingLatin1},
+		{name: "binary", raw: []byte{0x89, 'P', 'N', 'G', 0, 0, 0, 0x0D, 0x01}, wantText: "", wantEncoding: EncodingBinary, wantBinary: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			decoded := Decode(testCase.raw, 0)
+			if decoded.Text != testCase.wantText {
+				t.Fatalf("expected text %q, got %q", testCase.wantText, decoded.Text)
+			}
+			if decoded.Encoding != testCase.wantEncoding {
+				t.Fatalf("expected encoding %q, got %q", testCase.wantEncoding, decoded.Encoding)
+			}
+			if decoded.IsBinary != testCase.wantBinary {
+				t.Fatalf("expected isBinary %v, got %v", testCase.wantBinary, decoded.IsBinary)
+			}
+			if decoded.Size != len(testCase.raw) {
+				t.Fatalf("expected size %d, got %d", len(testCase.raw), decoded.Size)
+			}
+		})
+	}
+}
+
+func TestDecode_TruncatesOnRuneBoundary(t *testing.T) {
+	decoded := Decode([]byte("ab"+strings.Repeat("é", 4)), 5)
+	if !decoded.Truncated {
+		t.Fatalf("expected truncated content")
+	}
+	if decoded.Text != "abé" {
+		t.Fatalf("expected cut before a split rune, got %q", decoded.Text)
+	}
+
+	if full := Decode([]byte("abc"), 3); full.Truncated || full.Text != "abc" {
+		t.Fatalf("expected content at the limit to be kept whole, got %#v", full)
+	}
+}
+
+func TestDecodeItemContent_UsesMetadata(t *testing.T) {
+	response := map[string]any{
+		"content":         "GIF89a",
+		"contentMetadata": map[string]any{"isBinary": true},
+	}
+	decodeItemContent(response, 0)
+	if response["isBinary"] != true || response["content"] != "" || response["encoding"] != EncodingBinary {
+		t.Fatalf("expected metadata to mark content binary, got %#v", response)
+	}
+
+	response = map[string]any{
+		"content":         "plain",
+		"contentMetadata": map[string]any{"encoding": float64(1252)},
+	}
+	decodeItemContent(response, 0)
+	if response["encoding"] != EncodingLatin1 || response["content"] != "plain" {
+		t.Fatalf("expected code page encoding to be reported, got %#v", response)
+	}
+}
@@ -0,0 +1,88 @@
+package files
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Glob matches repository paths against a pattern. "*", "?" and "[...]" match within one path
+// segment, "**" matches any number of segments and "{a,b}" alternatives are expanded. A pattern
+// without "/" is matched against the file name at any depth, so "*.md" finds Markdown files anywhere.
+type Glob struct {
+	alternatives [][]string
+	baseNameOnly bool
+}
+
+func CompileGlob(pattern string) (*Glob, error) {
+	trimmed := strings.Trim(strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/")), "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("glob pattern is required")
+	}
+
+	glob := &Glob{baseNameOnly: !strings.Contains(trimmed, "/")}
+	for _, expanded := range expandBraces(trimmed) {
+		segments := strings.Split(expanded, "/")
+		for _, segment := range segments {
+			if segment == "**" {
+				continue
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
+		}
+		glob.alternatives = append(glob.alternatives, segments)
+	}
+	return glob, nil
+}
+
+func (g *Glob) Match(filePath string) bool {
+	trimmed := strings.Trim(strings.ReplaceAll(filePath, "\\", "/"), "/")
+	if g.baseNameOnly {
+		trimmed = path.Base(trimmed)
+	}
+	segments := strings.Split(trimmed, "/")
+	for _, alternative := range g.alternatives {
+		if matchSegments(alternative, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchSegments(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], segments[0])
+	return matched && matchSegments(pattern[1:], segments[1:])
+}
+
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+	closing := strings.IndexByte(pattern[open:], '}')
+	if closing < 0 {
+		return []string{pattern}
+	}
+	closing += open
+
+	expanded := make([]string, 0)
+	for _, option := range strings.Split(pattern[open+1:closing], ",") {
+		expanded = append(expanded, expandBraces(pattern[:open]+option+pattern[closing+1:])...)
+	}
+	return expanded
+}
@@ -0,0 +1,45 @@
+package files
+
+import "testing"
+
+func TestGlobMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "CONTRIBUTING.md", path: "/CONTRIBUTING.md", want: true},
+		{pattern: "CONTRIBUTING.md", path: "/docs/CONTRIBUTING.md", want: true},
+		{pattern: "*.md", path: "/docs/adr/0001.md", want: true},
+		{pattern: "*.md", path: "/docs/adr", want: false},
+		{pattern: ".eslintrc*", path: "/web/.eslintrc.json", want: true},
+		{pattern: "*.{yml,yaml}", path: "/ci/build.yaml", want: true},
+		{pattern: "*.{yml,yaml}", path: "/ci/build.json", want: false},
+		{pattern: "docs/**/*.md", path: "/docs/guide.md", want: true},
+		{pattern: "docs/**/*.md", path: "/docs/adr/deep/0001.md", want: true},
+		{pattern: "docs/**/*.md", path: "/src/docs/guide.md", want: false},
+		{pattern: "**/docs/*.md", path: "/src/docs/guide.md", want: true},
+		{pattern: "src/*/README.md", path: "/src/api/README.md", want: true},
+		{pattern: "src/*/README.md", path: "/src/api/v1/README.md", want: false},
+		{pattern: "/src/?.go", path: "/src/a.go", want: true},
+	}
+
+	for _, testCase := range testCases {
+		glob, err := CompileGlob(testCase.pattern)
+		if err != nil {
+			t.Fatalf("CompileGlob(%q) unexpected error: %v", testCase.pattern, err)
+		}
+		if got := glob.Match(testCase.path); got != testCase.want {
+			t.Fatalf("glob %q match %q = %v, want %v", testCase.pattern, testCase.path, got, testCase.want)
+		}
+	}
+}
+
+func TestCompileGlob_Invalid(t *testing.T) {
+	if _, err := CompileGlob(" "); err == nil {
+		t.Fatalf("expected empty pattern to fail")
+	}
+	if _, err := CompileGlob("src/[a-"); err == nil {
+		t.Fatalf("expected malformed character class to fail")
+	}
+}
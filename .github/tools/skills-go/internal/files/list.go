@@ -0,0 +1,187 @@
+package files
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+var recursionLevels = map[string]string{
+	"none":     "None",
+	"onelevel": "OneLevel",
+	"full":     "Full",
+}
+
+// ParseRecursion maps a recursion argument (none, oneLevel, full; case-insensitive) to the items API
+// recursionLevel. "-" and "" default to Full.
+func ParseRecursion(value string) (string, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(value))
+	if trimmed == "" || trimmed == "-" {
+		return "Full", nil
+	}
+	level, ok := recursionLevels[trimmed]
+	if !ok {
+		return "", fmt.Errorf("recursion must be one of: none, oneLevel, full")
+	}
+	return level, nil
+}
+
+// ListOptions selects what List returns. Empty or "-" fields use the defaults: the default branch,
+// versionType branch, the repository root, no glob and full recursion.
+type ListOptions struct {
+	Version     string
+	VersionType string
+	ScopePath   string
+	Glob        string
+	Recursion   string
+}
+
+// List returns the items under options.ScopePath at a version, optionally filtered by a glob (see CompileGlob).
+// Sizes come from the Git trees API; when that lookup fails the items are still returned with sizeError set.
+func List(organization, project, repositoryID string, options ListOptions) (map[string]any, error) {
+	version, versionType, scopePath, globPattern, recursion := options.Version, options.VersionType, options.ScopePath, options.Glob, options.Recursion
+	client, err := ado.NewClient(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	projectName := strings.TrimSpace(project)
+	repo := strings.TrimSpace(repositoryID)
+	if projectName == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("repositoryId is required")
+	}
+
+	scope, err := ado.NormalizeADOFilePath(scopePath)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" || scope == "-" {
+		scope = "/"
+	}
+	level, err := ParseRecursion(recursion)
+	if err != nil {
+		return nil, err
+	}
+	var glob *Glob
+	if pattern := strings.TrimSpace(globPattern); pattern != "" && pattern != "-" {
+		if glob, err = CompileGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	repoBaseURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo))
+	apiURL := fmt.Sprintf("%s/items?scopePath=%s&recursionLevel=%s&api-version=7.2-preview", repoBaseURL, url.QueryEscape(scope), level)
+	if trimmedVersion := strings.TrimSpace(version); trimmedVersion != "" && trimmedVersion != "-" {
+		if strings.TrimSpace(versionType) == "" || strings.TrimSpace(versionType) == "-" {
+			versionType = "branch"
+		}
+		apiURL += fmt.Sprintf("&versionDescriptor.version=%s&versionDescriptor.versionType=%s", url.QueryEscape(trimmedVersion), url.QueryEscape(strings.TrimSpace(versionType)))
+	}
+
+	var response struct {
+		Value []map[string]any `json:"value"`
+	}
+	if err := client.GetJSON(apiURL, &response); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{
+		"scopePath":  scope,
+		"recursion":  level,
+		"totalItems": len(response.Value),
+	}
+	if glob != nil {
+		result["glob"] = strings.TrimSpace(globPattern)
+	}
+
+	items := projectListItems(response.Value, scope, glob)
+	if treeID := scopeTreeID(response.Value, scope); treeID != "" && hasFiles(items) {
+		var trees struct {
+			TreeEntries []map[string]any `json:"treeEntries"`
+		}
+		treeURL := fmt.Sprintf("%s/trees/%s?recursive=%t&api-version=7.2-preview", repoBaseURL, url.PathEscape(treeID), level == "Full")
+		if err := client.GetJSON(treeURL, &trees); err != nil {
+			result["sizeError"] = err.Error()
+		} else {
+			applyTreeSizes(items, trees.TreeEntries)
+		}
+	}
+
+	result["count"] = len(items)
+	result["items"] = items
+	return result, nil
+}
+
+// projectListItems keeps path, folder flag and ids, drops the scope folder itself and applies the glob.
+func projectListItems(values []map[string]any, scope string, glob *Glob) []map[string]any {
+	items := make([]map[string]any, 0, len(values))
+	for _, value := range values {
+		itemPath := shared.TrimmedString(value["path"])
+		if itemPath == "" {
+			continue
+		}
+		isFolder, _ := value["isFolder"].(bool)
+		if isFolder && itemPath == scope {
+			continue
+		}
+		if glob != nil && !glob.Match(itemPath) {
+			continue
+		}
+
+		item := map[string]any{
+			"path":     itemPath,
+			"isFolder": isFolder,
+			"objectId": shared.TrimmedString(value["objectId"]),
+		}
+		if commitID := shared.TrimmedString(value["commitId"]); commitID != "" {
+			item["commitId"] = commitID
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func scopeTreeID(values []map[string]any, scope string) string {
+	for _, value := range values {
+		if isFolder, _ := value["isFolder"].(bool); isFolder && shared.TrimmedString(value["path"]) == scope {
+			return shared.TrimmedString(value["objectId"])
+		}
+	}
+	return ""
+}
+
+func hasFiles(items []map[string]any) bool {
+	for _, item := range items {
+		if isFolder, _ := item["isFolder"].(bool); !isFolder {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTreeSizes sets size on file items by matching object ids from a trees API response.
+func applyTreeSizes(items []map[string]any, treeEntries []map[string]any) {
+	sizes := make(map[string]any, len(treeEntries))
+	for _, entry := range treeEntries {
+		if shared.TrimmedString(entry["gitObjectType"]) != "blob" {
+			continue
+		}
+		if size, ok := entry["size"]; ok {
+			sizes[strings.ToLower(shared.TrimmedString(entry["objectId"]))] = size
+		}
+	}
+	for _, item := range items {
+		if isFolder, _ := item["isFolder"].(bool); isFolder {
+			continue
+		}
+		if size, ok := sizes[strings.ToLower(shared.TrimmedString(item["objectId"]))]; ok {
+			item["size"] = size
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package files
+
+import "testing"
+
+func TestList_Validation(t *testing.T) {
+	t.Setenv("ADO_PAT_testorg", "token")
+
+	if _, err := List("testorg", "", "repo", ListOptions{}); err == nil || err.Error() != "project is required" {
+		t.Fatalf("expected project validation error, got: %v", err)
+	}
+	if _, err := List("testorg", "project", "", ListOptions{}); err == nil || err.Error() != "repositoryId is required" {
+		t.Fatalf("expected repositoryId validation error, got: %v", err)
+	}
+	if _, err := List("testorg", "project", "repo", ListOptions{Recursion: "deep"}); err == nil {
+		t.Fatalf("expected recursion validation error")
+	}
+}
+
+func TestParseRecursion(t *testing.T) {
+	for input, want := range map[string]string{"": "Full", "-": "Full", "none": "None", "oneLevel": "OneLevel", "FULL": "Full"} {
+		got, err := ParseRecursion(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseRecursion(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestProjectListItemsAndSizes(t *testing.T) {
+	values := []map[string]any{
+		{"path": "/docs", "isFolder": true, "objectId": "tree1"},
+		{"path": "/docs/adr", "isFolder": true, "objectId": "tree2"},
+		{"path": "/docs/guide.md", "objectId": "AAA", "commitId": "c1"},
+		{"path": "/docs/adr/0001.md", "objectId": "bbb", "commitId": "c1"},
+		{"path": "/docs/logo.png", "objectId": "ccc", "commitId": "c1"},
+	}
+	glob, err := CompileGlob("*.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if scopeTreeID(values, "/docs") != "tree1" {
+		t.Fatalf("expected scope tree id to be found")
+	}
+
+	items := projectListItems(values, "/docs", glob)
+	if len(items) != 2 || items[0]["path"] != "/docs/guide.md" || items[1]["path"] != "/docs/adr/0001.md" {
+		t.Fatalf("unexpected filtered items: %#v", items)
+	}
+
+	applyTreeSizes(items, []map[string]any{
+		{"objectId": "aaa", "gitObjectType": "blob", "size": float64(120)},
+		{"objectId": "tree2", "gitObjectType": "tree"},
+	})
+	if items[0]["size"] != float64(120) {
+		t.Fatalf("expected size to match object id case-insensitively, got %#v", items[0])
+	}
+	if _, ok := items[1]["size"]; ok {
+		t.Fatalf("expected no size for an object missing from the tree, got %#v", items[1])
+	}
+
+	all := projectListItems(values, "/docs", nil)
+	if len(all) != 4 || !hasFiles(all) {
+		t.Fatalf("expected all items except the scope folder, got %#v", all)
+	}
+}
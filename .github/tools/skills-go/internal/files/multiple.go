@@ -9,35 +9,37 @@ import (
 const maxParallelContentRequests = 6
 
 func GetMultiple(organization, project, repositoryID, version, versionType string, paths []string) (map[string]any, error) {
-	results := make([]map[string]any, len(paths))
-	semaphore := make(chan struct{}, maxParallelContentRequests)
-	var wg sync.WaitGroup
+	return GetMultipleLimited(organization, project, repositoryID, version, versionType, paths, DefaultMaxContentBytes)
+}
 
+func GetMultipleLimited(organization, project, repositoryID, version, versionType string, paths []string, maxBytes int) (map[string]any, error) {
+	requests := make([]FileRequest, len(paths))
 	for index, path := range paths {
-		index := index
-		path := path
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+		requests[index] = FileRequest{Path: path}
+	}
+	return GetMultipleRequests(organization, project, repositoryID, version, versionType, requests, maxBytes)
+}
 
-			content, err := GetContent(organization, project, repositoryID, path, version, versionType)
-			entry := map[string]any{"path": path}
-			if err != nil {
-				entry["status"] = "error"
-				entry["error"] = err.Error()
-			} else {
-				entry["status"] = "ok"
-				entry["content"] = shared.TrimmedString(content["content"])
-				entry["commitId"] = shared.TrimmedString(content["commitId"])
-				entry["objectId"] = shared.TrimmedString(content["objectId"])
-			}
-			results[index] = entry
-		}()
+// GetMultipleRequests fetches each distinct path once and returns one result per request, in order.
+// Requests with line ranges get numbered snippets instead of full content; maxBytes only limits
+// full-content results.
+func GetMultipleRequests(organization, project, repositoryID, version, versionType string, requests []FileRequest, maxBytes int) (map[string]any, error) {
+	uniquePaths := make([]string, 0, len(requests))
+	fetchIndex := make(map[string]int)
+	for _, request := range requests {
+		if _, seen := fetchIndex[request.Path]; !seen {
+			fetchIndex[request.Path] = len(uniquePaths)
+			uniquePaths = append(uniquePaths, request.Path)
+		}
 	}
 
-	wg.Wait()
+	fetched, fetchErrors := fetchItemContents(organization, project, repositoryID, version, versionType, uniquePaths, true)
+
+	results := make([]map[string]any, len(requests))
+	for index, request := range requests {
+		position := fetchIndex[request.Path]
+		results[index] = multipleFileEntry(request, fetched[position], fetchErrors[position], maxBytes)
+	}
 
 	succeeded := 0
 	failed := 0
@@ -57,6 +59,76 @@ func GetMultiple(organization, project, repositoryID, version, versionType strin
 	}, nil
 }
 
+// fetchItemContents fetches the full content of each path, a few at a time, with getItemContent.
+func fetchItemContents(organization, project, repositoryID, version, versionType string, paths []string, blobSize bool) ([]map[string]any, []error) {
+	fetched := make([]map[string]any, len(paths))
+	fetchErrors := make([]error, len(paths))
+	semaphore := make(chan struct{}, maxParallelContentRequests)
+	var wg sync.WaitGroup
+
+	for index, path := range paths {
+		index := index
+		path := path
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			fetched[index], fetchErrors[index] = getItemContent(organization, project, repositoryID, path, version, versionType, 0, blobSize)
+		}()
+	}
+
+	wg.Wait()
+	return fetched, fetchErrors
+}
+
+// getTextContents maps paths to their full decoded text for internal diff callers: nothing is
+// truncated and no blob size is looked up. Binary files and failed fetches are left out.
+func getTextContents(organization, project, repositoryID, version, versionType string, paths []string) map[string]string {
+	fetched, fetchErrors := fetchItemContents(organization, project, repositoryID, version, versionType, paths, false)
+	result := make(map[string]string, len(paths))
+	for index, path := range paths {
+		if fetchErrors[index] != nil {
+			continue
+		}
+		if isBinary, _ := fetched[index]["isBinary"].(bool); isBinary {
+			continue
+		}
+		result[path], _ = fetched[index]["content"].(string)
+	}
+	return result
+}
+
+func multipleFileEntry(request FileRequest, content map[string]any, err error, maxBytes int) map[string]any {
+	entry := map[string]any{"path": request.Path}
+	if err != nil {
+		entry["status"] = "error"
+		entry["error"] = err.Error()
+		return entry
+	}
+
+	entry["status"] = "ok"
+	for _, key := range []string{"content", "encoding", "size", "isBinary", "truncated"} {
+		entry[key] = content[key]
+	}
+	if sizeError, ok := content["sizeError"]; ok {
+		entry["sizeError"] = sizeError
+	}
+	entry["commitId"] = shared.TrimmedString(content["commitId"])
+	entry["objectId"] = shared.TrimmedString(content["objectId"])
+	if len(request.Ranges) > 0 {
+		ApplySnippets(entry, request.Ranges)
+	} else if text, ok := entry["content"].(string); ok {
+		if limited, truncated := truncateText(text, maxBytes); truncated {
+			entry["content"] = limited
+			entry["truncated"] = true
+		}
+	}
+	return entry
+}
+
+// ContentByPath maps successfully fetched text files to their content; binary files are left out.
 func ContentByPath(payload map[string]any) map[string]string {
 	result := make(map[string]string)
 
@@ -71,11 +143,14 @@ func ContentByPath(payload map[string]any) map[string]string {
 				if shared.TrimmedString(entry["status"]) != "ok" {
 					continue
 				}
+				if isBinary, _ := entry["isBinary"].(bool); isBinary {
+					continue
+				}
 				path := shared.TrimmedString(entry["path"])
 				if path == "" {
 					continue
 				}
-				result[path] = shared.TrimmedString(entry["content"])
+				result[path], _ = entry["content"].(string)
 			}
 		}
 		return result
@@ -85,11 +160,14 @@ func ContentByPath(payload map[string]any) map[string]string {
 		if shared.TrimmedString(entry["status"]) != "ok" {
 			continue
 		}
+		if isBinary, _ := entry["isBinary"].(bool); isBinary {
+			continue
+		}
 		path := shared.TrimmedString(entry["path"])
 		if path == "" {
 			continue
 		}
-		result[path] = shared.TrimmedString(entry["content"])
+		result[path], _ = entry["content"].(string)
 	}
 
 	return result
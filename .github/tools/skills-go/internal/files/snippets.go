@@ -0,0 +1,218 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive, 1-based range of lines to return from a file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// FileRequest is one entry of a get-multiple-files request: a path and, optionally, the line ranges to return.
+type FileRequest struct {
+	Path   string
+	Ranges []LineRange
+}
+
+// ParseLineRanges parses a comma-separated list of line ranges. Each item is a single line ("42"),
+// an inclusive range ("120-180"), or a line with context ("150+-10" or "150±10" for lines 140-160).
+// Overlapping and adjacent ranges are merged. "-" and "" mean no ranges (the whole file).
+func ParseLineRanges(spec string) ([]LineRange, error) {
+	trimmed := strings.TrimSpace(spec)
+	if trimmed == "" || trimmed == "-" {
+		return nil, nil
+	}
+
+	ranges := make([]LineRange, 0)
+	for _, item := range strings.Split(trimmed, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		lineRange, err := parseLineRange(item)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, lineRange)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("line range %q has no ranges", spec)
+	}
+	return MergeLineRanges(ranges), nil
+}
+
+func parseLineRange(item string) (LineRange, error) {
+	for _, separator := range []string{"+-", "±"} {
+		if center, context, ok := strings.Cut(item, separator); ok {
+			line, err := parseLineNumber(center, item)
+			if err != nil {
+				return LineRange{}, err
+			}
+			radius, err := strconv.Atoi(strings.TrimSpace(context))
+			if err != nil || radius < 0 {
+				return LineRange{}, fmt.Errorf("invalid line range %q: context must be a non-negative integer", item)
+			}
+			start := line - radius
+			if start < 1 {
+				start = 1
+			}
+			return LineRange{Start: start, End: line + radius}, nil
+		}
+	}
+
+	if first, last, ok := strings.Cut(item, "-"); ok {
+		start, err := parseLineNumber(first, item)
+		if err != nil {
+			return LineRange{}, err
+		}
+		end, err := parseLineNumber(last, item)
+		if err != nil {
+			return LineRange{}, err
+		}
+		if end < start {
+			return LineRange{}, fmt.Errorf("invalid line range %q: end is before start", item)
+		}
+		return LineRange{Start: start, End: end}, nil
+	}
+
+	line, err := parseLineNumber(item, item)
+	if err != nil {
+		return LineRange{}, err
+	}
+	return LineRange{Start: line, End: line}, nil
+}
+
+func parseLineNumber(value, item string) (int, error) {
+	line, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || line < 1 {
+		return 0, fmt.Errorf("invalid line range %q: line numbers must be positive integers", item)
+	}
+	return line, nil
+}
+
+// MergeLineRanges sorts ranges and merges overlapping or adjacent ones. ranges must not be empty.
+func MergeLineRanges(ranges []LineRange) []LineRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].Start != ranges[j].Start {
+			return ranges[i].Start < ranges[j].Start
+		}
+		return ranges[i].End < ranges[j].End
+	})
+
+	merged := []LineRange{ranges[0]}
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if next.Start <= last.End+1 {
+			if next.End > last.End {
+				last.End = next.End
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+	return merged
+}
+
+// ParseFileRequests parses the get-multiple-files paths argument. Items are either path strings or
+// objects of the form {"path": "/src/app.go", "lines": "120-180"}.
+func ParseFileRequests(raw string) ([]FileRequest, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, err
+	}
+
+	requests := make([]FileRequest, 0, len(items))
+	for index, item := range items {
+		item = bytes.TrimSpace(item)
+		if len(item) > 0 && item[0] == '"' {
+			var path string
+			if err := json.Unmarshal(item, &path); err != nil {
+				return nil, fmt.Errorf("item %d: %w", index, err)
+			}
+			requests = append(requests, FileRequest{Path: path})
+			continue
+		}
+
+		var object struct {
+			Path  string `json:"path"`
+			Lines string `json:"lines"`
+		}
+		if err := json.Unmarshal(item, &object); err != nil {
+			return nil, fmt.Errorf("item %d must be a path string or an object with path and lines", index)
+		}
+		if strings.TrimSpace(object.Path) == "" {
+			return nil, fmt.Errorf("item %d: path is required", index)
+		}
+		ranges, err := ParseLineRanges(object.Lines)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", index, err)
+		}
+		requests = append(requests, FileRequest{Path: object.Path, Ranges: ranges})
+	}
+	return requests, nil
+}
+
+// ApplySnippets replaces a fetched entry's full content with numbered snippets for the requested
+// ranges. Ranges are clamped to the file length; ranges that start past the end are reported with
+// outOfRange instead of text. Binary entries are left unchanged.
+func ApplySnippets(entry map[string]any, ranges []LineRange) {
+	if len(ranges) == 0 {
+		return
+	}
+	if isBinary, _ := entry["isBinary"].(bool); isBinary {
+		return
+	}
+
+	content, _ := entry["content"].(string)
+	lines := splitContentLines(content)
+	snippets := make([]map[string]any, 0, len(ranges))
+	for _, lineRange := range ranges {
+		snippet := map[string]any{"startLine": lineRange.Start, "endLine": lineRange.End}
+		if lineRange.Start > len(lines) {
+			snippet["outOfRange"] = true
+			snippets = append(snippets, snippet)
+			continue
+		}
+		end := lineRange.End
+		if end > len(lines) {
+			end = len(lines)
+			snippet["endLine"] = end
+		}
+		snippet["text"] = numberLines(lines[lineRange.Start-1:end], lineRange.Start)
+		snippets = append(snippets, snippet)
+	}
+
+	delete(entry, "content")
+	entry["totalLines"] = len(lines)
+	entry["snippets"] = snippets
+}
+
+func splitContentLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for index, line := range lines {
+		lines[index] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func numberLines(lines []string, firstLine int) string {
+	width := len(strconv.Itoa(firstLine + len(lines) - 1))
+	var builder strings.Builder
+	for index, line := range lines {
+		if index > 0 {
+			builder.WriteByte('\n')
+		}
+		fmt.Fprintf(&builder, "%*d: %s", width, firstLine+index, line)
+	}
+	return builder.String()
+}
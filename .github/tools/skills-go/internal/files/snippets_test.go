@@ -0,0 +1,129 @@
+package files
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLineRanges(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		want    []LineRange
+		wantErr bool
+	}{
+		{name: "dash means whole file", spec: "-", want: nil},
+		{name: "single line", spec: "42", want: []LineRange{{Start: 42, End: 42}}},
+		{name: "inclusive range", spec: "120-180", want: []LineRange{{Start: 120, End: 180}}},
+		{name: "context with plus-minus", spec: "150+-10", want: []LineRange{{Start: 140, End: 160}}},
+		{name: "context with symbol clamps to line one", spec: "3±5", want: []LineRange{{Start: 1, End: 8}}},
+		{name: "overlapping and adjacent ranges merge", spec: "20-30, 10-15,25-40,41", want: []LineRange{{Start: 10, End: 15}, {Start: 20, End: 41}}},
+		{name: "end before start", spec: "30-20", wantErr: true},
+		{name: "zero line", spec: "0", wantErr: true},
+		{name: "not a number", spec: "abc", wantErr: true},
+		{name: "negative context", spec: "10+--2", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := ParseLineRanges(testCase.spec)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Fatalf("ParseLineRanges(%q) = %#v, want %#v", testCase.spec, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestParseFileRequests(t *testing.T) {
+	requests, err := ParseFileRequests(`["/a.go", {"path": "/b.go", "lines": "5-6,10+-1"}, {"path": "/c.go"}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []FileRequest{
+		{Path: "/a.go"},
+		{Path: "/b.go", Ranges: []LineRange{{Start: 5, End: 6}, {Start: 9, End: 11}}},
+		{Path: "/c.go"},
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("unexpected requests: %#v", requests)
+	}
+
+	if _, err := ParseFileRequests(`[{"lines": "1-2"}]`); err == nil {
+		t.Fatalf("expected missing path to fail")
+	}
+	if _, err := ParseFileRequests(`[{"path": "/a.go", "lines": "x"}]`); err == nil {
+		t.Fatalf("expected invalid lines to fail")
+	}
+}
+
+func TestApplySnippets(t *testing.T) {
+	entry := map[string]any{"content": "one\r\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"}
+	ApplySnippets(entry, []LineRange{{Start: 2, End: 3}, {Start: 9, End: 12}, {Start: 20, End: 25}})
+
+	if _, ok := entry["content"]; ok {
+		t.Fatalf("expected full content to be replaced by snippets")
+	}
+	if entry["totalLines"] != 10 {
+		t.Fatalf("expected 10 lines, got %v", entry["totalLines"])
+	}
+	snippets := entry["snippets"].([]map[string]any)
+	if len(snippets) != 3 {
+		t.Fatalf("expected 3 snippets, got %d", len(snippets))
+	}
+	if snippets[0]["text"] != "2: two\n3: three" {
+		t.Fatalf("unexpected first snippet: %q", snippets[0]["text"])
+	}
+	if snippets[1]["text"] != " 9: nine\n10: ten" || snippets[1]["endLine"] != 10 {
+		t.Fatalf("expected clamped, aligned snippet, got %#v", snippets[1])
+	}
+	if snippets[2]["outOfRange"] != true || snippets[2]["text"] != nil {
+		t.Fatalf("expected out-of-range snippet, got %#v", snippets[2])
+	}
+
+	binary := map[string]any{"content": "", "isBinary": true}
+	ApplySnippets(binary, []LineRange{{Start: 1, End: 1}})
+	if _, ok := binary["snippets"]; ok {
+		t.Fatalf("expected binary entry to be left unchanged")
+	}
+}
+
+func TestMultipleFileEntry(t *testing.T) {
+	content := map[string]any{"content": "alpha\nbeta\ngamma\n", "encoding": EncodingUTF8, "size": 17, "isBinary": false, "truncated": false, "commitId": "c1", "objectId": "o1"}
+
+	full := multipleFileEntry(FileRequest{Path: "/a.txt"}, content, nil, 8)
+	if full["content"] != "alpha\nbe" || full["truncated"] != true {
+		t.Fatalf("expected full request to honor maxBytes, got %#v", full)
+	}
+	if content["content"] != "alpha\nbeta\ngamma\n" {
+		t.Fatalf("expected shared fetched content to stay intact")
+	}
+
+	ranged := multipleFileEntry(FileRequest{Path: "/a.txt", Ranges: []LineRange{{Start: 3, End: 3}}}, content, nil, 8)
+	snippets := ranged["snippets"].([]map[string]any)
+	if snippets[0]["text"] != "3: gamma" || ranged["truncated"] != false {
+		t.Fatalf("expected untruncated snippet, got %#v", ranged)
+	}
+
+	if _, ok := full["sizeError"]; ok {
+		t.Fatalf("expected no sizeError when the blob size was read, got %#v", full)
+	}
+	withoutSize := multipleFileEntry(FileRequest{Path: "/a.txt"}, map[string]any{"content": "a", "size": 1, "sizeError": "HTTP 500"}, nil, 0)
+	if withoutSize["sizeError"] != "HTTP 500" || withoutSize["size"] != 1 {
+		t.Fatalf("expected the blob size error to be reported, got %#v", withoutSize)
+	}
+
+	failed := multipleFileEntry(FileRequest{Path: "/missing"}, nil, errors.New("HTTP 404"), 8)
+	if failed["status"] != "error" || failed["error"] != "HTTP 404" {
+		t.Fatalf("unexpected error entry: %#v", failed)
+	}
+}
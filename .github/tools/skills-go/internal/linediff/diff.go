@@ -0,0 +1,381 @@
+package linediff
+
+import "strings"
+
+const maxEditDistance = 2000
+
+const (
+	KindContext = "context"
+	KindAdded   = "added"
+	KindDeleted = "deleted"
+)
+
+type Line struct {
+	Kind    string `json:"kind"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+	Text    string `json:"text"`
+}
+
+type Hunk struct {
+	OldStart int    `json:"oldStart"`
+	OldLines int    `json:"oldLines"`
+	NewStart int    `json:"newStart"`
+	NewLines int    `json:"newLines"`
+	Lines    []Line `json:"lines"`
+}
+
+type Options struct {
+	IgnoreWhitespace         bool
+	IgnoreTrailingWhitespace bool
+	IgnoreEOL                bool
+}
+
+func SplitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	normalized = strings.TrimSuffix(normalized, "\n")
+	return strings.Split(normalized, "\n")
+}
+
+func Diff(oldContent, newContent string, context int) []Hunk {
+	return Compute(SplitLines(oldContent), SplitLines(newContent), context)
+}
+
+func DiffWithOptions(oldContent, newContent string, context int, options Options) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+	oldLines := splitRawLines(oldContent)
+	newLines := splitRawLines(newContent)
+	script := editScript(compareKeys(oldLines, options), compareKeys(newLines, options))
+	for i := range script {
+		if script[i].Kind == KindDeleted {
+			script[i].Text = strings.TrimSuffix(oldLines[script[i].OldLine-1], "\r")
+		} else {
+			script[i].Text = strings.TrimSuffix(newLines[script[i].NewLine-1], "\r")
+		}
+	}
+	return groupHunks(script, context)
+}
+
+// Equivalent reports whether two contents have the same lines once options are applied.
+func Equivalent(oldContent, newContent string, options Options) bool {
+	oldKeys := compareKeys(splitRawLines(oldContent), options)
+	newKeys := compareKeys(splitRawLines(newContent), options)
+	if len(oldKeys) != len(newKeys) {
+		return false
+	}
+	for i := range oldKeys {
+		if oldKeys[i] != newKeys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func Compute(oldLines, newLines []string, context int) []Hunk {
+	hunks, _ := ComputeChecked(oldLines, newLines, context)
+	return hunks
+}
+
+// ComputeChecked is Compute that also reports whether the diff is exact. When the changed region
+// needs more than 2000 edits the search stops and the region is reported as deleted and re-added
+// as a whole, so lines that survived inside it are not matched; exact is then false.
+func ComputeChecked(oldLines, newLines []string, context int) ([]Hunk, bool) {
+	if context < 0 {
+		context = 0
+	}
+	script, exact := editScriptChecked(oldLines, newLines)
+	return groupHunks(script, context), exact
+}
+
+func LineEnding(content string) string {
+	crlf := strings.Count(content, "\r\n")
+	lf := strings.Count(content, "\n") - crlf
+	switch {
+	case crlf == 0 && lf == 0:
+		return "none"
+	case crlf == 0:
+		return "lf"
+	case lf == 0:
+		return "crlf"
+	default:
+		return "mixed"
+	}
+}
+
+func splitRawLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func compareKeys(lines []string, options Options) []string {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		key := line
+		if options.IgnoreEOL {
+			key = strings.TrimSuffix(key, "\r")
+		}
+		if options.IgnoreWhitespace {
+			key = strings.Join(strings.Fields(key), " ")
+		} else if options.IgnoreTrailingWhitespace {
+			key = strings.TrimRight(key, " \t\r")
+			if !options.IgnoreEOL && strings.HasSuffix(line, "\r") {
+				key += "\r"
+			}
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+func ChangedLines(hunks []Hunk, side string) []int {
+	lines := make([]int, 0)
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			if side == "left" && line.Kind == KindDeleted {
+				lines = append(lines, line.OldLine)
+			}
+			if side != "left" && line.Kind == KindAdded {
+				lines = append(lines, line.NewLine)
+			}
+		}
+	}
+	return lines
+}
+
+func (h Hunk) AddedLines() int {
+	return h.countKind(KindAdded)
+}
+
+func (h Hunk) DeletedLines() int {
+	return h.countKind(KindDeleted)
+}
+
+func (h Hunk) ContextLines() int {
+	return h.countKind(KindContext)
+}
+
+func (h Hunk) countKind(kind string) int {
+	count := 0
+	for _, line := range h.Lines {
+		if line.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+func editScript(oldLines, newLines []string) []Line {
+	script, _ := editScriptChecked(oldLines, newLines)
+	return script
+}
+
+func editScriptChecked(oldLines, newLines []string) ([]Line, bool) {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix && oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	script := make([]Line, 0, len(oldLines)+len(newLines))
+	for i := 0; i < prefix; i++ {
+		script = append(script, Line{Kind: KindContext, OldLine: i + 1, NewLine: i + 1, Text: newLines[i]})
+	}
+
+	oldMiddle := oldLines[prefix : len(oldLines)-suffix]
+	newMiddle := newLines[prefix : len(newLines)-suffix]
+	middle, exact := myers(oldMiddle, newMiddle)
+	for _, line := range middle {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		script = append(script, line)
+	}
+
+	for i := suffix; i > 0; i-- {
+		oldIndex := len(oldLines) - i
+		newIndex := len(newLines) - i
+		script = append(script, Line{Kind: KindContext, OldLine: oldIndex + 1, NewLine: newIndex + 1, Text: newLines[newIndex]})
+	}
+	return script, exact
+}
+
+// myers returns the shortest edit script, or replaceAll and false when it needs more than
+// maxEditDistance edits.
+func myers(oldLines, newLines []string) ([]Line, bool) {
+	n := len(oldLines)
+	m := len(newLines)
+	if n == 0 || m == 0 {
+		return replaceAll(oldLines, newLines), true
+	}
+
+	max := n + m
+	if max > maxEditDistance {
+		max = maxEditDistance
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0)
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && oldLines[x] == newLines[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll(oldLines, newLines), false
+	}
+
+	reversed := make([]Line, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && previous[offset+k-1] < previous[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := previous[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Kind: KindContext, OldLine: x, NewLine: y, Text: newLines[y-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Kind: KindAdded, NewLine: y, Text: newLines[y-1]})
+		} else {
+			reversed = append(reversed, Line{Kind: KindDeleted, OldLine: x, Text: oldLines[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Line{Kind: KindContext, OldLine: x, NewLine: y, Text: newLines[y-1]})
+		x--
+		y--
+	}
+
+	script := make([]Line, len(reversed))
+	for i := range reversed {
+		script[i] = reversed[len(reversed)-1-i]
+	}
+	return script, true
+}
+
+func replaceAll(oldLines, newLines []string) []Line {
+	script := make([]Line, 0, len(oldLines)+len(newLines))
+	for i, text := range oldLines {
+		script = append(script, Line{Kind: KindDeleted, OldLine: i + 1, Text: text})
+	}
+	for i, text := range newLines {
+		script = append(script, Line{Kind: KindAdded, NewLine: i + 1, Text: text})
+	}
+	return script
+}
+
+func groupHunks(script []Line, context int) []Hunk {
+	hunks := make([]Hunk, 0)
+	changes := make([]int, 0)
+	for i, line := range script {
+		if line.Kind != KindContext {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return hunks
+	}
+
+	start := maxInt(0, changes[0]-context)
+	end := minInt(len(script)-1, changes[0]+context)
+	for _, index := range changes[1:] {
+		if index-context <= end+1 {
+			end = minInt(len(script)-1, index+context)
+			continue
+		}
+		hunks = append(hunks, buildHunk(script, start, end))
+		start = maxInt(0, index-context)
+		end = minInt(len(script)-1, index+context)
+	}
+	hunks = append(hunks, buildHunk(script, start, end))
+	return hunks
+}
+
+func buildHunk(script []Line, start, end int) Hunk {
+	lines := make([]Line, end-start+1)
+	copy(lines, script[start:end+1])
+
+	hunk := Hunk{Lines: lines}
+	oldBefore, newBefore := 0, 0
+	for _, line := range script[:start] {
+		if line.OldLine > 0 {
+			oldBefore = line.OldLine
+		}
+		if line.NewLine > 0 {
+			newBefore = line.NewLine
+		}
+	}
+	for _, line := range lines {
+		if line.Kind != KindAdded {
+			hunk.OldLines++
+		}
+		if line.Kind != KindDeleted {
+			hunk.NewLines++
+		}
+	}
+	hunk.OldStart = oldBefore + 1
+	hunk.NewStart = newBefore + 1
+	if hunk.OldLines == 0 {
+		hunk.OldStart = oldBefore
+	}
+	if hunk.NewLines == 0 {
+		hunk.NewStart = newBefore
+	}
+	return hunk
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+package linediff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSplitLines_DropsTrailingNewline(t *testing.T) {
+	lines := SplitLines("a\r\nb\r\n")
+	if !reflect.DeepEqual(lines, []string{"a", "b"}) {
+		t.Fatalf("unexpected split result: %#v", lines)
+	}
+	if len(SplitLines("")) != 0 {
+		t.Fatalf("expected no lines for empty content")
+	}
+}
+
+func TestDiff_EqualContentHasNoHunks(t *testing.T) {
+	if hunks := Diff("a\nb\n", "a\nb\n", 3); len(hunks) != 0 {
+		t.Fatalf("expected no hunks, got %#v", hunks)
+	}
+}
+
+func TestDiff_SingleReplacementWithContext(t *testing.T) {
+	oldContent := "one\ntwo\nthree\nfour\nfive\n"
+	newContent := "one\ntwo\nTHREE\nfour\nfive\n"
+
+	hunks := Diff(oldContent, newContent, 1)
+	if len(hunks) != 1 {
+		t.Fatalf("expected one hunk, got %d", len(hunks))
+	}
+	hunk := hunks[0]
+	if hunk.OldStart != 2 || hunk.OldLines != 3 || hunk.NewStart != 2 || hunk.NewLines != 3 {
+		t.Fatalf("unexpected hunk range: %+v", hunk)
+	}
+	if hunk.AddedLines() != 1 || hunk.DeletedLines() != 1 || hunk.ContextLines() != 2 {
+		t.Fatalf("unexpected hunk counts: added=%d deleted=%d context=%d", hunk.AddedLines(), hunk.DeletedLines(), hunk.ContextLines())
+	}
+}
+
+func TestDiff_SeparateHunksWhenFarApart(t *testing.T) {
+	oldContent := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	newContent := "A\nb\nc\nd\ne\nf\ng\nH\n"
+
+	hunks := Diff(oldContent, newContent, 1)
+	if len(hunks) != 2 {
+		t.Fatalf("expected two hunks, got %d", len(hunks))
+	}
+	if hunks[1].NewStart != 7 || hunks[1].NewLines != 2 {
+		t.Fatalf("unexpected second hunk: %+v", hunks[1])
+	}
+}
+
+func TestDiff_InsertionIntoEmptyFile(t *testing.T) {
+	hunks := Diff("", "x\ny\n", 3)
+	if len(hunks) != 1 {
+		t.Fatalf("expected one hunk, got %d", len(hunks))
+	}
+	if hunks[0].OldStart != 0 || hunks[0].OldLines != 0 || hunks[0].NewStart != 1 || hunks[0].NewLines != 2 {
+		t.Fatalf("unexpected hunk range: %+v", hunks[0])
+	}
+}
+
+func TestChangedLines_BySide(t *testing.T) {
+	hunks := Diff("a\nb\nc\n", "a\nx\nc\nd\n", 0)
+
+	if right := ChangedLines(hunks, "right"); !reflect.DeepEqual(right, []int{2, 4}) {
+		t.Fatalf("unexpected right-side changed lines: %#v", right)
+	}
+	if left := ChangedLines(hunks, "left"); !reflect.DeepEqual(left, []int{2}) {
+		t.Fatalf("unexpected left-side changed lines: %#v", left)
+	}
+}
+
+func TestDiffWithOptions_KeepsOriginalText(t *testing.T) {
+	hunks := DiffWithOptions("x\r\n", "y\n", 0, Options{})
+	if len(hunks) != 1 || hunks[0].Lines[0].Text != "x" || hunks[0].Lines[1].Text != "y" {
+		t.Fatalf("unexpected hunks: %#v", hunks)
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := map[string]string{"": "none", "a": "none", "a\n": "lf", "a\r\nb\r\n": "crlf", "a\r\nb\n": "mixed"}
+	for content, want := range tests {
+		if got := LineEnding(content); got != want {
+			t.Fatalf("LineEnding(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestComputeChecked_ReportsFallbackBeyondEditLimit(t *testing.T) {
+	small, exact := ComputeChecked([]string{"a", "b", "c"}, []string{"a", "x", "c"}, 1)
+	if !exact || len(small) != 1 {
+		t.Fatalf("expected an exact diff for a small change, got exact=%v hunks=%d", exact, len(small))
+	}
+	if _, exact := ComputeChecked(nil, []string{"a", "b"}, 0); !exact {
+		t.Fatal("expected an insertion into an empty file to be exact")
+	}
+
+	oldLines := make([]string, 0, 1101)
+	newLines := make([]string, 0, 1101)
+	for i := 0; i < 1100; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("old %d", i))
+		newLines = append(newLines, fmt.Sprintf("new %d", i))
+		if i == 550 {
+			oldLines = append(oldLines, "kept")
+			newLines = append(newLines, "kept")
+		}
+	}
+	hunks, exact := ComputeChecked(oldLines, newLines, 0)
+	if exact {
+		t.Fatal("expected more than 2000 edits to be reported as inexact")
+	}
+	for _, line := range hunks[0].Lines {
+		if line.Kind == KindContext {
+			t.Fatalf("expected the fallback to match no lines, got %+v", line)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package pullrequests
+
+import (
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+type bundleContents struct {
+	organization string
+	project      string
+	repositoryID string
+	baseVersion  string
+	prVersion    string
+	base         map[string]string
+	pr           map[string]string
+	loaded       map[string]bool
+	stats        files.FetchStats
+}
+
+func newBundleContents(organization, project, repositoryID, baseVersion, prVersion string) *bundleContents {
+	return &bundleContents{
+		organization: organization,
+		project:      project,
+		repositoryID: repositoryID,
+		baseVersion:  baseVersion,
+		prVersion:    prVersion,
+		base:         map[string]string{},
+		pr:           map[string]string{},
+		loaded:       map[string]bool{},
+	}
+}
+
+func (c *bundleContents) load(entries []map[string]any) {
+	blobs := make([]files.ChangedBlob, 0, len(entries))
+	for _, fileEntry := range entries {
+		path := shared.TrimmedString(fileEntry["path"])
+		if path == "" || c.loaded[path] {
+			continue
+		}
+		if isFolder, _ := fileEntry["isFolder"].(bool); isFolder {
+			continue
+		}
+		if isBinary, _ := fileEntry["binary"].(bool); isBinary {
+			continue
+		}
+		c.loaded[path] = true
+		blobs = append(blobs, files.ChangedBlobFromEntry(fileEntry))
+	}
+	if len(blobs) == 0 {
+		return
+	}
+
+	base, pr, stats := files.GetChangedContents(c.organization, c.project, c.repositoryID, blobs, c.baseVersion, c.prVersion)
+	for path, content := range base {
+		c.base[path] = content
+	}
+	for path, content := range pr {
+		c.pr[path] = content
+	}
+	c.stats.BatchRequests += stats.BatchRequests
+	c.stats.BlobRequests += stats.BlobRequests
+	c.stats.PathRequests += stats.PathRequests
+	c.stats.Failed += stats.Failed
+}
@@ -29,12 +29,24 @@ func ProjectChangedFiles(changes map[string]any, pullRequestID, iterationID stri
 				isFolder = v
 			}
 		}
-		files = append(files, map[string]any{
+		projected := map[string]any{
 			"path":             path,
 			"changeType":       entry["changeType"],
 			"changeTrackingId": entry["changeTrackingId"],
 			"isFolder":         isFolder,
-		})
+		}
+		if originalPath := renamedFromPath(entry); originalPath != "" && originalPath != path {
+			projected["originalPath"] = originalPath
+		}
+		if item != nil {
+			if objectID, ok := item["objectId"].(string); ok && objectID != "" {
+				projected["objectId"] = objectID
+			}
+			if originalObjectID, ok := item["originalObjectId"].(string); ok && originalObjectID != "" {
+				projected["originalObjectId"] = originalObjectID
+			}
+		}
+		files = append(files, projected)
 	}
 
 	return map[string]any{
@@ -44,3 +56,14 @@ func ProjectChangedFiles(changes map[string]any, pullRequestID, iterationID stri
 		"files":         files,
 	}
 }
+
+// renamedFromPath returns the path a renamed or moved file had in the base version: the change
+// entry's originalPath, or its sourceServerItem.
+func renamedFromPath(entry map[string]any) string {
+	for _, key := range []string{"originalPath", "sourceServerItem"} {
+		if path, ok := entry[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+// EditComment replaces the content of a comment authored by the authenticated identity.
+func EditComment(organization, project, repositoryID, pullRequestID, threadID, commentID, content string) (map[string]any, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	client, commentURL, err := ownCommentURL(organization, project, repositoryID, pullRequestID, threadID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]any{}
+	if err := client.PatchJSON(commentURL, map[string]string{"content": content}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteComment deletes a comment authored by the authenticated identity.
+func DeleteComment(organization, project, repositoryID, pullRequestID, threadID, commentID string) (map[string]any, error) {
+	client, commentURL, err := ownCommentURL(organization, project, repositoryID, pullRequestID, threadID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.DeleteJSON(commentURL, nil); err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"threadId":  strings.TrimSpace(threadID),
+		"commentId": strings.TrimSpace(commentID),
+		"deleted":   true,
+	}, nil
+}
+
+// ownCommentURL validates the identifiers, loads the comment and returns its URL only when the
+// authenticated identity wrote it and it is not already deleted.
+func ownCommentURL(organization, project, repositoryID, pullRequestID, threadID, commentID string) (*ado.Client, string, error) {
+	client, err := ado.NewClient(organization)
+	if err != nil {
+		return nil, "", err
+	}
+	projectName := strings.TrimSpace(project)
+	repo := strings.TrimSpace(repositoryID)
+	prID := strings.TrimSpace(pullRequestID)
+	tID := strings.TrimSpace(threadID)
+	cID := strings.TrimSpace(commentID)
+	if projectName == "" || repo == "" || prID == "" || tID == "" || cID == "" {
+		return nil, "", fmt.Errorf("organization, project, repositoryId, pullRequestId, threadId and commentId are required")
+	}
+
+	commentURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%s/threads/%s/comments/%s?api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), prID, url.PathEscape(tID), url.PathEscape(cID))
+	comment := map[string]any{}
+	if err := client.GetJSON(commentURL, &comment); err != nil {
+		return nil, "", err
+	}
+	userID, err := client.GetAuthenticatedUserID()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkOwnComment(comment, userID); err != nil {
+		return nil, "", fmt.Errorf("comment %s in thread %s: %w", cID, tID, err)
+	}
+	return client, commentURL, nil
+}
+
+func checkOwnComment(comment map[string]any, userID string) error {
+	if deleted, _ := comment["isDeleted"].(bool); deleted {
+		return fmt.Errorf("comment is deleted")
+	}
+	author, _ := comment["author"].(map[string]any)
+	authorID := shared.TrimmedString(author["id"])
+	if authorID == "" || !strings.EqualFold(authorID, strings.TrimSpace(userID)) {
+		name := shared.TrimmedString(author["displayName"])
+		if name == "" {
+			name = "another identity"
+		}
+		return fmt.Errorf("refusing to modify a comment authored by %s; only comments by the authenticated identity can be edited or deleted", name)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package pullrequests
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckOwnComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment map[string]any
+		wantErr string
+	}{
+		{
+			name:    "own comment, case-insensitive id",
+			comment: map[string]any{"author": map[string]any{"id": "ABC-123", "displayName": "Me"}},
+		},
+		{
+			name:    "other author",
+			comment: map[string]any{"author": map[string]any{"id": "def-456", "displayName": "Jordan"}},
+			wantErr: "refusing to modify a comment authored by Jordan",
+		},
+		{
+			name:    "missing author",
+			comment: map[string]any{},
+			wantErr: "refusing to modify a comment authored by another identity",
+		},
+		{
+			name:    "deleted",
+			comment: map[string]any{"isDeleted": true, "author": map[string]any{"id": "abc-123"}},
+			wantErr: "comment is deleted",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := checkOwnComment(testCase.comment, "abc-123")
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), testCase.wantErr) {
+				t.Fatalf("expected error starting with %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package pullrequests
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	PositionModeNone     = "none"
+	PositionModeValidate = "validate"
+	PositionModeSnap     = "snap"
+
+	CommentSideRight = "right"
+	CommentSideLeft  = "left"
+
+	maxSuggestedLines = 5
+)
+
+type commentRange struct {
+	Side        string
+	StartLine   int
+	StartOffset int
+	EndLine     int
+	EndOffset   int
+}
+
+type commentPosition struct {
+	Requested        commentRange
+	Range            commentRange
+	Snapped          bool
+	IterationID      string
+	ChangeTrackingID int
+	Lines            []string
+}
+
+func NormalizePositionMode(mode string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(mode))
+	switch normalized {
+	case "", "-", PositionModeNone:
+		return PositionModeNone, nil
+	case PositionModeValidate, PositionModeSnap:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("positionMode must be one of: none, validate, snap")
+	}
+}
+
+func NormalizeCommentSide(side string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(side))
+	switch normalized {
+	case "", "-", CommentSideRight, "pr", "new":
+		return CommentSideRight, nil
+	case CommentSideLeft, "base", "old":
+		return CommentSideLeft, nil
+	default:
+		return "", fmt.Errorf("side must be one of: right, left")
+	}
+}
+
+// parseLineSpec accepts "15", "15-20", "15:4" and "15:4-20:12" (line:offset, 1-based).
+func parseLineSpec(spec string) (commentRange, error) {
+	trimmed := strings.TrimSpace(spec)
+	if trimmed == "" {
+		return commentRange{StartLine: 1, EndLine: 1}, nil
+	}
+
+	startPart, endPart, hasEnd := strings.Cut(trimmed, "-")
+	startLine, startOffset, err := parseLinePosition(startPart)
+	if err != nil {
+		return commentRange{}, fmt.Errorf("invalid line '%s': %w", spec, err)
+	}
+	endLine, endOffset := startLine, startOffset
+	if hasEnd {
+		endLine, endOffset, err = parseLinePosition(endPart)
+		if err != nil {
+			return commentRange{}, fmt.Errorf("invalid line '%s': %w", spec, err)
+		}
+	}
+
+	if startLine < 1 {
+		startLine = 1
+		if !hasEnd {
+			endLine = 1
+		}
+	}
+	if endLine < startLine {
+		return commentRange{}, fmt.Errorf("invalid line '%s': end line must be >= start line", spec)
+	}
+	if endLine == startLine && startOffset > 0 && endOffset > 0 && endOffset < startOffset {
+		return commentRange{}, fmt.Errorf("invalid line '%s': end offset must be >= start offset", spec)
+	}
+	return commentRange{StartLine: startLine, StartOffset: startOffset, EndLine: endLine, EndOffset: endOffset}, nil
+}
+
+func parseLinePosition(value string) (int, int, error) {
+	linePart, offsetPart, hasOffset := strings.Cut(strings.TrimSpace(value), ":")
+	line, err := strconv.Atoi(strings.TrimSpace(linePart))
+	if err != nil {
+		return 0, 0, fmt.Errorf("line must be an integer")
+	}
+	if !hasOffset {
+		return line, 0, nil
+	}
+	offset, err := strconv.Atoi(strings.TrimSpace(offsetPart))
+	if err != nil || offset < 1 {
+		return 0, 0, fmt.Errorf("offset must be a positive integer")
+	}
+	return line, offset, nil
+}
+
+func (r commentRange) isLegacy() bool {
+	return r.Side == CommentSideRight && r.StartLine == r.EndLine && r.StartOffset == 0 && r.EndOffset == 0
+}
+
+func (r commentRange) threadContext(filePath string) map[string]any {
+	startOffset := r.StartOffset
+	if startOffset == 0 {
+		startOffset = 1
+	}
+	endOffset := r.EndOffset
+	if endOffset == 0 {
+		endOffset = 1
+	}
+	context := map[string]any{"filePath": filePath}
+	start := map[string]int{"line": r.StartLine, "offset": startOffset}
+	end := map[string]int{"line": r.EndLine, "offset": endOffset}
+	if r.Side == CommentSideLeft {
+		context["leftFileStart"] = start
+		context["leftFileEnd"] = end
+	} else {
+		context["rightFileStart"] = start
+		context["rightFileEnd"] = end
+	}
+	return context
+}
+
+func (r commentRange) toMap() map[string]any {
+	return map[string]any{
+		"side":        r.Side,
+		"startLine":   r.StartLine,
+		"startOffset": r.StartOffset,
+		"endLine":     r.EndLine,
+		"endOffset":   r.EndOffset,
+	}
+}
+
+func resolveCommentPosition(options CommentOptions, filePath string, requested commentRange, mode string, loadContent bool) (commentPosition, error) {
+	source, err := newPositionSource(options)
+	if err != nil {
+		return commentPosition{}, err
+	}
+	return source.resolve(filePath, requested, mode, loadContent)
+}
+
+// positionSource resolves comment positions against one pull request iteration, fetching the
+// iteration's changes once and each file's base/PR versions at most once.
+type positionSource struct {
+	options   CommentOptions
+	iteration iterationContext
+	changes   map[string]any
+	versions  map[string][2]string
+}
+
+func newPositionSource(options CommentOptions) (*positionSource, error) {
+	iteration, err := resolveIterationContext(options.Organization, options.Project, options.RepositoryID, options.PullRequestID, options.IterationID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := GetChanges(options.Organization, options.Project, options.RepositoryID, options.PullRequestID, iteration.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &positionSource{options: options, iteration: iteration, changes: changes, versions: map[string][2]string{}}, nil
+}
+
+func (s *positionSource) resolve(filePath string, requested commentRange, mode string, loadContent bool) (commentPosition, error) {
+	iteration := s.iteration
+	fileEntry, ok := findChangedFile(s.changes, s.options.PullRequestID, iteration.ID, filePath)
+	if !ok {
+		return commentPosition{}, fmt.Errorf("%s is not changed in iteration %s", filePath, iteration.ID)
+	}
+
+	position := commentPosition{
+		Requested:        requested,
+		Range:            requested,
+		IterationID:      iteration.ID,
+		ChangeTrackingID: toBundleInt(fileEntry["changeTrackingId"]),
+	}
+	if mode == PositionModeNone && !loadContent {
+		return position, nil
+	}
+
+	changeType := shared.TrimmedString(fileEntry["changeType"])
+	if requested.Side == CommentSideLeft && strings.Contains(changeType, "add") {
+		return commentPosition{}, fmt.Errorf("%s was added in this pull request; left-side comments need a base version", filePath)
+	}
+	if requested.Side == CommentSideRight && strings.Contains(changeType, "delete") {
+		return commentPosition{}, fmt.Errorf("%s was deleted in this pull request; use the left side to comment on removed lines", filePath)
+	}
+
+	versions, cached := s.versions[filePath]
+	if !cached {
+		fetch := func(path, commitID string) (string, error) {
+			return fetchFileAtCommit(s.options, path, commitID)
+		}
+		baseContent, prContent, err := loadIterationFileVersions(fetch, fileEntry, iteration)
+		if err != nil {
+			return commentPosition{}, err
+		}
+		versions = [2]string{baseContent, prContent}
+		s.versions[filePath] = versions
+	}
+	baseContent, prContent := versions[0], versions[1]
+	sideLines := linediff.SplitLines(prContent)
+	if requested.Side == CommentSideLeft {
+		sideLines = linediff.SplitLines(baseContent)
+	}
+
+	if mode != PositionModeNone {
+		changed := linediff.ChangedLines(linediff.Diff(baseContent, prContent, 0), requested.Side)
+		if len(changed) == 0 {
+			return commentPosition{}, fmt.Errorf("%s has no changed lines on the %s side in iteration %s", filePath, requested.Side, iteration.ID)
+		}
+		if !rangeTouchesLines(requested, changed) {
+			if mode == PositionModeValidate {
+				return commentPosition{}, fmt.Errorf("line %s is not a changed line in %s (%s side, iteration %s); nearest changed lines: %s", formatLineRange(requested), filePath, requested.Side, iteration.ID, joinLines(nearestChangedLines(requested.StartLine, changed, maxSuggestedLines)))
+			}
+			position.Range = snapRange(requested, nearestChangedLines(requested.StartLine, changed, 1)[0])
+			position.Snapped = true
+		}
+	}
+
+	resolved, err := fitRangeToLines(position.Range, sideLines)
+	if err != nil {
+		return commentPosition{}, fmt.Errorf("%s (%s side, iteration %s): %w", filePath, requested.Side, iteration.ID, err)
+	}
+	position.Range = resolved
+	position.Lines = sideLines
+	return position, nil
+}
+
+// loadIterationFileVersions fetches a changed file's base and PR versions with fetch. The base
+// version of a renamed or moved file is read from its original path.
+func loadIterationFileVersions(fetch func(filePath, commitID string) (string, error), fileEntry map[string]any, iteration iterationContext) (string, string, error) {
+	filePath := shared.TrimmedString(fileEntry["path"])
+	changeType := shared.TrimmedString(fileEntry["changeType"])
+	basePath := shared.TrimmedString(fileEntry["originalPath"])
+	if basePath == "" {
+		basePath = filePath
+	}
+
+	baseContent := ""
+	if !strings.Contains(changeType, "add") {
+		content, err := fetch(basePath, iteration.BaseCommit)
+		if err != nil {
+			return "", "", err
+		}
+		baseContent = content
+	}
+
+	prContent := ""
+	if !strings.Contains(changeType, "delete") {
+		content, err := fetch(filePath, iteration.SourceCommit)
+		if err != nil {
+			return "", "", err
+		}
+		prContent = content
+	}
+	return baseContent, prContent, nil
+}
+
+func fetchFileAtCommit(options CommentOptions, filePath, commitID string) (string, error) {
+	if commitID == "" {
+		return "", fmt.Errorf("iteration commit is not available for %s", filePath)
+	}
+	response, err := files.GetContent(options.Organization, options.Project, options.RepositoryID, filePath, commitID, "commit")
+	if err != nil {
+		return "", err
+	}
+	content, _ := response["content"].(string)
+	return content, nil
+}
+
+func fitRangeToLines(r commentRange, lines []string) (commentRange, error) {
+	if r.EndLine > len(lines) {
+		return commentRange{}, fmt.Errorf("line %s is outside the file (%d lines)", formatLineRange(r), len(lines))
+	}
+	startMax := utf8.RuneCountInString(lines[r.StartLine-1]) + 1
+	endMax := utf8.RuneCountInString(lines[r.EndLine-1]) + 1
+	if r.StartOffset > startMax {
+		return commentRange{}, fmt.Errorf("offset %d is past the end of line %d (max %d)", r.StartOffset, r.StartLine, startMax)
+	}
+	if r.EndOffset > endMax {
+		return commentRange{}, fmt.Errorf("offset %d is past the end of line %d (max %d)", r.EndOffset, r.EndLine, endMax)
+	}
+
+	fitted := r
+	if fitted.StartOffset == 0 {
+		fitted.StartOffset = 1
+	}
+	if fitted.EndOffset == 0 {
+		fitted.EndOffset = endMax
+	}
+	return fitted, nil
+}
+
+func rangeTouchesLines(r commentRange, lines []int) bool {
+	for _, line := range lines {
+		if line >= r.StartLine && line <= r.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+func snapRange(r commentRange, target int) commentRange {
+	shift := 0
+	if target < r.StartLine {
+		shift = target - r.StartLine
+	} else if target > r.EndLine {
+		shift = target - r.EndLine
+	}
+	return commentRange{Side: r.Side, StartLine: r.StartLine + shift, EndLine: r.EndLine + shift}
+}
+
+func formatLineRange(r commentRange) string {
+	if r.StartLine == r.EndLine {
+		return strconv.Itoa(r.StartLine)
+	}
+	return fmt.Sprintf("%d-%d", r.StartLine, r.EndLine)
+}
+
+func nearestChangedLines(line int, changed []int, limit int) []int {
+	candidates := append([]int(nil), changed...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		di := absInt(candidates[i] - line)
+		dj := absInt(candidates[j] - line)
+		if di != dj {
+			return di < dj
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	sort.Ints(candidates)
+	return candidates
+}
+
+func joinLines(lines []int) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts = append(parts, strconv.Itoa(line))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,190 @@
+package pullrequests
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePositionMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: PositionModeNone},
+		{input: "-", want: PositionModeNone},
+		{input: " Validate ", want: PositionModeValidate},
+		{input: "snap", want: PositionModeSnap},
+		{input: "closest", wantErr: true},
+	}
+
+	for _, testCase := range tests {
+		got, err := NormalizePositionMode(testCase.input)
+		if testCase.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", testCase.input)
+			}
+			continue
+		}
+		if err != nil || got != testCase.want {
+			t.Fatalf("NormalizePositionMode(%q) = %q, %v; want %q", testCase.input, got, err, testCase.want)
+		}
+	}
+}
+
+func TestNearestChangedLines_OrdersByDistanceThenLine(t *testing.T) {
+	changed := []int{3, 4, 10, 20, 21}
+
+	if got := nearestChangedLines(8, changed, 2); !reflect.DeepEqual(got, []int{4, 10}) {
+		t.Fatalf("unexpected nearest lines: %#v", got)
+	}
+	if got := nearestChangedLines(7, changed, 1); !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("expected tie to prefer lower line, got %#v", got)
+	}
+}
+
+func TestSelectIterationContext(t *testing.T) {
+	response := map[string]any{
+		"value": []any{
+			map[string]any{"id": float64(1), "sourceRefCommit": map[string]any{"commitId": "s1"}, "commonRefCommit": map[string]any{"commitId": "b1"}},
+			map[string]any{"id": float64(2), "sourceRefCommit": map[string]any{"commitId": "s2"}, "targetRefCommit": map[string]any{"commitId": "t2"}},
+		},
+	}
+
+	latest, err := selectIterationContext(response, "", "42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest.ID != "2" || latest.SourceCommit != "s2" || latest.BaseCommit != "t2" {
+		t.Fatalf("unexpected latest iteration: %#v", latest)
+	}
+
+	first, err := selectIterationContext(response, "1", "42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.BaseCommit != "b1" {
+		t.Fatalf("expected common ref commit as base, got %#v", first)
+	}
+
+	if _, err := selectIterationContext(response, "9", "42"); err == nil {
+		t.Fatalf("expected error for unknown iteration")
+	}
+}
+
+func TestParseLineSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    commentRange
+		wantErr bool
+	}{
+		{spec: "15", want: commentRange{StartLine: 15, EndLine: 15}},
+		{spec: "0", want: commentRange{StartLine: 1, EndLine: 1}},
+		{spec: "10-20", want: commentRange{StartLine: 10, EndLine: 20}},
+		{spec: "10:4-12:9", want: commentRange{StartLine: 10, StartOffset: 4, EndLine: 12, EndOffset: 9}},
+		{spec: "7:3", want: commentRange{StartLine: 7, StartOffset: 3, EndLine: 7, EndOffset: 3}},
+		{spec: "20-10", wantErr: true},
+		{spec: "5:9-5:2", wantErr: true},
+		{spec: "5:0", wantErr: true},
+		{spec: "abc", wantErr: true},
+	}
+
+	for _, testCase := range tests {
+		got, err := parseLineSpec(testCase.spec)
+		if testCase.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", testCase.spec)
+			}
+			continue
+		}
+		if err != nil || got != testCase.want {
+			t.Fatalf("parseLineSpec(%q) = %+v, %v; want %+v", testCase.spec, got, err, testCase.want)
+		}
+	}
+}
+
+func TestFitRangeToLines(t *testing.T) {
+	lines := []string{"package main", "", "func main() {}"}
+
+	fitted, err := fitRangeToLines(commentRange{Side: CommentSideLeft, StartLine: 1, EndLine: 3}, lines)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fitted.StartOffset != 1 || fitted.EndOffset != 15 {
+		t.Fatalf("expected whole-line offsets, got %+v", fitted)
+	}
+
+	if _, err := fitRangeToLines(commentRange{StartLine: 2, EndLine: 4}, lines); err == nil {
+		t.Fatalf("expected error for range past end of file")
+	}
+	if _, err := fitRangeToLines(commentRange{StartLine: 1, StartOffset: 14, EndLine: 1}, lines); err == nil {
+		t.Fatalf("expected error for offset past end of line")
+	}
+}
+
+func TestSnapRange_PreservesLength(t *testing.T) {
+	below := snapRange(commentRange{Side: CommentSideRight, StartLine: 10, EndLine: 12}, 4)
+	if below.StartLine != 4 || below.EndLine != 6 {
+		t.Fatalf("unexpected snap below: %+v", below)
+	}
+	above := snapRange(commentRange{Side: CommentSideRight, StartLine: 10, EndLine: 12}, 20)
+	if above.StartLine != 18 || above.EndLine != 20 {
+		t.Fatalf("unexpected snap above: %+v", above)
+	}
+}
+
+func TestThreadContext_UsesSide(t *testing.T) {
+	left := commentRange{Side: CommentSideLeft, StartLine: 3, StartOffset: 1, EndLine: 5, EndOffset: 8}.threadContext("/a.go")
+	if _, ok := left["leftFileStart"]; !ok {
+		t.Fatalf("expected left-side anchors, got %#v", left)
+	}
+	if _, ok := left["rightFileStart"]; ok {
+		t.Fatalf("did not expect right-side anchors, got %#v", left)
+	}
+
+	legacy := commentRange{Side: CommentSideRight, StartLine: 7, EndLine: 7}.threadContext("/a.go")
+	if !reflect.DeepEqual(legacy["rightFileEnd"], map[string]int{"line": 7, "offset": 1}) {
+		t.Fatalf("unexpected legacy anchor: %#v", legacy)
+	}
+}
+
+func TestLoadIterationFileVersions_RenamedFileReadsBaseFromOriginalPath(t *testing.T) {
+	changes := map[string]any{"changeEntries": []any{
+		map[string]any{
+			"changeTrackingId": float64(4),
+			"changeType":       "rename, edit",
+			"item":             map[string]any{"path": "/src/new_name.go"},
+			"originalPath":     "/src/old_name.go",
+		},
+	}}
+	fileEntry, ok := findChangedFile(changes, "7", "2", "/src/new_name.go")
+	if !ok {
+		t.Fatal("expected the renamed file to be found by its new path")
+	}
+	if fileEntry["originalPath"] != "/src/old_name.go" {
+		t.Fatalf("expected originalPath to be projected, got %v", fileEntry["originalPath"])
+	}
+
+	fetched := map[string]string{}
+	fetch := func(filePath, commitID string) (string, error) {
+		fetched[commitID] = filePath
+		return commitID + " content", nil
+	}
+	iteration := iterationContext{ID: "2", BaseCommit: "base", SourceCommit: "source"}
+	baseContent, prContent, err := loadIterationFileVersions(fetch, fileEntry, iteration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched["base"] != "/src/old_name.go" || fetched["source"] != "/src/new_name.go" {
+		t.Fatalf("expected base from the original path and PR version from the new path, got %v", fetched)
+	}
+	if baseContent != "base content" || prContent != "source content" {
+		t.Fatalf("unexpected contents %q, %q", baseContent, prContent)
+	}
+
+	edited := map[string]any{"path": "/src/app.go", "changeType": "edit"}
+	fetched = map[string]string{}
+	if _, _, err := loadIterationFileVersions(fetch, edited, iteration); err != nil || fetched["base"] != "/src/app.go" {
+		t.Fatalf("expected an edited file to use one path, got %v, %v", fetched, err)
+	}
+}
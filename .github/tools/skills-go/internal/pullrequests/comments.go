@@ -9,51 +9,116 @@ import (
 	"ado-reviewer/.github/tools/skills-go/internal/ado"
 )
 
-func PostComment(organization, project, repositoryID, pullRequestID, filePath, line, comment string) (map[string]any, error) {
-	client, err := ado.NewClient(organization)
+// CommentOptions describes a comment to post. Its fingerprint hashes Category, the file and Key, or
+// the comment text when Key is empty, so only a Key lets OnDuplicate find a reworded comment.
+type CommentOptions struct {
+	Organization  string
+	Project       string
+	RepositoryID  string
+	PullRequestID string
+	FilePath      string
+	Line          string
+	Comment       string
+	PositionMode  string
+	IterationID   string
+	Side          string
+	Category      string
+	OnDuplicate   string
+	Key           string
+}
+
+func PostComment(options CommentOptions) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
 	if err != nil {
 		return nil, err
 	}
-	projectName := strings.TrimSpace(project)
-	repo := strings.TrimSpace(repositoryID)
-	prID := strings.TrimSpace(pullRequestID)
+	projectName := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
 	if projectName == "" || repo == "" || prID == "" {
 		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
 	}
-	if strings.TrimSpace(comment) == "" {
+	if strings.TrimSpace(options.Comment) == "" {
 		return nil, fmt.Errorf("comment is required")
 	}
+	mode, err := NormalizePositionMode(options.PositionMode)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NormalizeDuplicatePolicy(options.OnDuplicate)
+	if err != nil {
+		return nil, err
+	}
 
 	payload := map[string]any{
 		"comments": []map[string]any{{
 			"parentCommentId": 0,
-			"content":         comment,
+			"content":         options.Comment,
 			"commentType":     "text",
 		}},
 		"status": "active",
 	}
 
-	trimPath := strings.TrimSpace(filePath)
+	var position *commentPosition
+	filePath := ""
+	trimPath := strings.TrimSpace(options.FilePath)
 	if trimPath != "" && trimPath != "-" {
 		normalized, err := ado.NormalizeADOFilePath(trimPath)
 		if err != nil {
 			return nil, err
 		}
-		lineNum, _ := strconv.Atoi(strings.TrimSpace(line))
-		if lineNum < 1 {
-			lineNum = 1
+		filePath = normalized
+		requested, err := parseLineSpec(options.Line)
+		if err != nil {
+			return nil, err
 		}
-		payload["threadContext"] = map[string]any{
-			"filePath":       normalized,
-			"rightFileStart": map[string]int{"line": lineNum, "offset": 1},
-			"rightFileEnd":   map[string]int{"line": lineNum, "offset": 1},
+		requested.Side, err = NormalizeCommentSide(options.Side)
+		if err != nil {
+			return nil, err
+		}
+		if mode != PositionModeNone || strings.TrimSpace(options.IterationID) != "" || !requested.isLegacy() {
+			resolved, err := resolveCommentPosition(options, normalized, requested, mode, !requested.isLegacy())
+			if err != nil {
+				return nil, err
+			}
+			position = &resolved
+			requested = resolved.Range
+			payload["pullRequestThreadContext"] = buildPullRequestThreadContext(resolved)
 		}
+		payload["threadContext"] = requested.threadContext(normalized)
 	}
 
 	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%s/threads?api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), prID)
-	response := map[string]any{}
-	if err := client.PostJSON(apiURL, payload, &response); err != nil {
+	fingerprintText := options.Comment
+	if key := strings.TrimSpace(options.Key); key != "" && key != "-" {
+		fingerprintText = key
+	}
+	response, err := postFingerprintedThread(client, apiURL, payload, Fingerprint(options.Category, filePath, fingerprintText), policy)
+	if err != nil {
 		return nil, err
 	}
+	if _, duplicate := response["duplicate"]; duplicate {
+		return response, nil
+	}
+	if position != nil {
+		response["commentPosition"] = map[string]any{
+			"requested":        position.Requested.toMap(),
+			"resolved":         position.Range.toMap(),
+			"snapped":          position.Snapped,
+			"iterationId":      position.IterationID,
+			"changeTrackingId": position.ChangeTrackingID,
+		}
+	}
 	return response, nil
 }
+
+func buildPullRequestThreadContext(position commentPosition) map[string]any {
+	iterationID, _ := strconv.Atoi(position.IterationID)
+	return map[string]any{
+		"changeTrackingId": position.ChangeTrackingID,
+		"iterationContext": map[string]int{
+			"firstComparingIteration":  1,
+			"secondComparingIteration": iterationID,
+		},
+	}
+}
@@ -0,0 +1,241 @@
+package pullrequests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	DigestFormatMarkdown = "markdown"
+	DigestFormatJSON     = "json"
+
+	DefaultDigestCommentChars = 400
+
+	digestSummaryChars = 120
+	digestGeneralGroup = "General"
+)
+
+// DigestOptions controls how much of a discussion GetDiscussionDigest keeps.
+type DigestOptions struct {
+	ExcludeSystem bool
+	// MaxCommentChars cuts each comment of an open thread; <= 0 uses DefaultDigestCommentChars.
+	MaxCommentChars int
+}
+
+// NormalizeDigestFormat validates the digest format; "" and "-" mean markdown.
+func NormalizeDigestFormat(format string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	switch normalized {
+	case "", "-", DigestFormatMarkdown, "md":
+		return DigestFormatMarkdown, nil
+	case DigestFormatJSON:
+		return DigestFormatJSON, nil
+	default:
+		return "", fmt.Errorf("format must be one of: markdown, json")
+	}
+}
+
+// GetDiscussionDigest groups a pull request's threads by file and by open or resolved status. Open
+// threads keep their comments in chronological order; resolved threads are collapsed to a summary.
+func GetDiscussionDigest(organization, project, repositoryID, pullRequestID string, options DigestOptions) (map[string]any, error) {
+	response, err := GetFilteredThreads(organization, project, repositoryID, pullRequestID, ThreadFilter{ExcludeSystem: options.ExcludeSystem})
+	if err != nil {
+		return nil, err
+	}
+	threads := asMapSlice(response["value"])
+	return buildDiscussionDigest(strings.TrimSpace(pullRequestID), threads, options), nil
+}
+
+func buildDiscussionDigest(pullRequestID string, threads []map[string]any, options DigestOptions) map[string]any {
+	maxChars := options.MaxCommentChars
+	if maxChars <= 0 {
+		maxChars = DefaultDigestCommentChars
+	}
+
+	type fileGroup struct {
+		open     []map[string]any
+		resolved []map[string]any
+	}
+	groups := map[string]*fileGroup{}
+	openCount, resolvedCount := 0, 0
+	for _, thread := range threads {
+		if deleted, _ := thread["isDeleted"].(bool); deleted {
+			continue
+		}
+		comments := chronologicalComments(thread)
+		if len(comments) == 0 {
+			continue
+		}
+		filePath, _ := threadPosition(thread)
+		if filePath == "" {
+			filePath = digestGeneralGroup
+		}
+		group, ok := groups[filePath]
+		if !ok {
+			group = &fileGroup{}
+			groups[filePath] = group
+		}
+		if isResolvedThreadStatus(shared.TrimmedString(thread["status"])) {
+			group.resolved = append(group.resolved, resolvedDigestThread(thread, comments))
+			resolvedCount++
+			continue
+		}
+		group.open = append(group.open, openDigestThread(thread, comments, maxChars))
+		openCount++
+	}
+
+	paths := make([]string, 0, len(groups))
+	for filePath := range groups {
+		paths = append(paths, filePath)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if (paths[i] == digestGeneralGroup) != (paths[j] == digestGeneralGroup) {
+			return paths[i] == digestGeneralGroup
+		}
+		return paths[i] < paths[j]
+	})
+
+	files := make([]map[string]any, 0, len(paths))
+	for _, filePath := range paths {
+		group := groups[filePath]
+		sortDigestThreads(group.open)
+		sortDigestThreads(group.resolved)
+		files = append(files, map[string]any{
+			"filePath": filePath,
+			"open":     group.open,
+			"resolved": group.resolved,
+		})
+	}
+
+	return map[string]any{
+		"pullRequestId": toBundleInt(pullRequestID),
+		"threadCount":   openCount + resolvedCount,
+		"openCount":     openCount,
+		"resolvedCount": resolvedCount,
+		"fileCount":     len(files),
+		"files":         files,
+	}
+}
+
+// chronologicalComments returns a thread's human comments ordered by publish date, then id.
+func chronologicalComments(thread map[string]any) []map[string]any {
+	comments := humanComments(thread)
+	sort.SliceStable(comments, func(i, j int) bool {
+		left, right := shared.TrimmedString(comments[i]["publishedDate"]), shared.TrimmedString(comments[j]["publishedDate"])
+		if left != right && left != "" && right != "" {
+			leftTime, leftErr := time.Parse(time.RFC3339, left)
+			rightTime, rightErr := time.Parse(time.RFC3339, right)
+			if leftErr == nil && rightErr == nil {
+				return leftTime.Before(rightTime)
+			}
+		}
+		return toBundleInt(comments[i]["id"]) < toBundleInt(comments[j]["id"])
+	})
+	return comments
+}
+
+func digestThreadBase(thread map[string]any, comments []map[string]any) map[string]any {
+	entry := map[string]any{
+		"id":     toBundleInt(thread["id"]),
+		"status": shared.TrimmedString(thread["status"]),
+		"author": identityName(comments[0]["author"]),
+	}
+	if _, line := threadPosition(thread); line > 0 {
+		entry["line"] = line
+	}
+	if class := shared.TrimmedString(thread["classification"]); class != "" {
+		entry["classification"] = class
+	}
+	return entry
+}
+
+func openDigestThread(thread map[string]any, comments []map[string]any, maxChars int) map[string]any {
+	entry := digestThreadBase(thread, comments)
+	items := make([]map[string]any, 0, len(comments))
+	for _, comment := range comments {
+		items = append(items, map[string]any{
+			"id":            toBundleInt(comment["id"]),
+			"author":        identityName(comment["author"]),
+			"publishedDate": shared.TrimmedString(comment["publishedDate"]),
+			"text":          excerpt(shared.TrimmedString(comment["content"]), maxChars),
+		})
+	}
+	entry["comments"] = items
+	return entry
+}
+
+func resolvedDigestThread(thread map[string]any, comments []map[string]any) map[string]any {
+	entry := digestThreadBase(thread, comments)
+	entry["summary"] = excerpt(shared.TrimmedString(comments[0]["content"]), digestSummaryChars)
+	entry["commentCount"] = len(comments)
+	entry["lastCommentBy"] = identityName(comments[len(comments)-1]["author"])
+	return entry
+}
+
+func sortDigestThreads(threads []map[string]any) {
+	sort.SliceStable(threads, func(i, j int) bool {
+		leftLine, rightLine := toBundleInt(threads[i]["line"]), toBundleInt(threads[j]["line"])
+		if leftLine != rightLine {
+			return leftLine < rightLine
+		}
+		return toBundleInt(threads[i]["id"]) < toBundleInt(threads[j]["id"])
+	})
+}
+
+// RenderDiscussionDigest renders a digest built by GetDiscussionDigest as compact markdown.
+func RenderDiscussionDigest(digest map[string]any) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# PR %v discussion digest\n\n", digest["pullRequestId"])
+	fmt.Fprintf(&builder, "%v threads (%v open, %v resolved) in %v groups. Resolved threads are collapsed to one line.\n",
+		digest["threadCount"], digest["openCount"], digest["resolvedCount"], digest["fileCount"])
+
+	for _, group := range asMapSlice(digest["files"]) {
+		fmt.Fprintf(&builder, "\n## %s\n", shared.TrimmedString(group["filePath"]))
+		open := asMapSlice(group["open"])
+		resolved := asMapSlice(group["resolved"])
+		if len(open) > 0 {
+			builder.WriteString("\n### Open\n\n")
+			for _, thread := range open {
+				fmt.Fprintf(&builder, "- **#%v**%s %s · %s%s\n", thread["id"], digestLineLabel(thread), thread["status"], thread["author"], digestClassLabel(thread))
+				for _, comment := range asMapSlice(thread["comments"]) {
+					fmt.Fprintf(&builder, "  - %s, %s: %s\n", comment["author"], digestDate(shared.TrimmedString(comment["publishedDate"])), comment["text"])
+				}
+			}
+		}
+		if len(resolved) > 0 {
+			builder.WriteString("\n### Resolved\n\n")
+			for _, thread := range resolved {
+				fmt.Fprintf(&builder, "- #%v%s %s · %s: %s (%v comments, last by %s)\n", thread["id"], digestLineLabel(thread), thread["status"], thread["author"], thread["summary"], thread["commentCount"], thread["lastCommentBy"])
+			}
+		}
+	}
+	return builder.String()
+}
+
+func digestLineLabel(thread map[string]any) string {
+	if line := toBundleInt(thread["line"]); line > 0 {
+		return fmt.Sprintf(" L%d", line)
+	}
+	return ""
+}
+
+func digestClassLabel(thread map[string]any) string {
+	class := shared.TrimmedString(thread["classification"])
+	if class == "" || class == ThreadClassHuman {
+		return ""
+	}
+	return " (" + class + ")"
+}
+
+// digestDate shortens an RFC 3339 timestamp to minutes in UTC, or returns it unchanged.
+func digestDate(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsed.UTC().Format("2006-01-02 15:04")
+}
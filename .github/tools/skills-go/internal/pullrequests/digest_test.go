@@ -0,0 +1,122 @@
+package pullrequests
+
+import (
+	"strings"
+	"testing"
+)
+
+func digestTestThreads() []map[string]any {
+	riley := map[string]any{"id": "r", "displayName": "Riley"}
+	avery := map[string]any{"id": "a", "displayName": "Avery"}
+	return []map[string]any{
+		{
+			"id":             float64(5),
+			"status":         "active",
+			"classification": "human",
+			"threadContext":  map[string]any{"filePath": "/src/b.go", "rightFileStart": map[string]any{"line": float64(30)}},
+			"comments": []any{
+				map[string]any{"id": float64(2), "content": "Agreed,\n\nwill fix.", "publishedDate": "2024-05-02T09:00:00Z", "author": avery},
+				map[string]any{"id": float64(1), "content": "This can   panic on nil.", "publishedDate": "2024-05-01T10:00:00Z", "author": riley},
+			},
+		},
+		{
+			"id":             float64(3),
+			"status":         "fixed",
+			"classification": "self",
+			"threadContext":  map[string]any{"filePath": "/src/b.go", "rightFileStart": map[string]any{"line": float64(12)}},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Rename this.", "publishedDate": "2024-05-01T10:00:00Z", "author": riley},
+				map[string]any{"id": float64(2), "content": "Done.", "publishedDate": "2024-05-01T11:00:00Z", "author": avery},
+			},
+		},
+		{
+			"id":             float64(9),
+			"status":         "pending",
+			"classification": "bot",
+			"threadContext":  map[string]any{"filePath": "/src/a.go", "rightFileStart": map[string]any{"line": float64(4)}},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Lint: unused import.", "publishedDate": "2024-05-01T10:00:00Z", "author": map[string]any{"displayName": "Lint Bot"}},
+			},
+		},
+		{
+			"id":     float64(1),
+			"status": "active",
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Please add a changelog entry.", "publishedDate": "2024-04-30T10:00:00Z", "author": riley},
+			},
+		},
+		{
+			"id":       float64(2),
+			"status":   "active",
+			"comments": []any{map[string]any{"id": float64(1), "content": "gone", "isDeleted": true, "author": riley}},
+		},
+	}
+}
+
+func TestBuildDiscussionDigest(t *testing.T) {
+	digest := buildDiscussionDigest("42", digestTestThreads(), DigestOptions{})
+	if digest["threadCount"] != 4 || digest["openCount"] != 3 || digest["resolvedCount"] != 1 || digest["fileCount"] != 3 {
+		t.Fatalf("unexpected counts: %#v", digest)
+	}
+
+	files := asMapSlice(digest["files"])
+	order := []string{"General", "/src/a.go", "/src/b.go"}
+	for index, group := range files {
+		if group["filePath"] != order[index] {
+			t.Fatalf("unexpected group order: %#v", files)
+		}
+	}
+
+	open := asMapSlice(files[2]["open"])
+	comments := asMapSlice(open[0]["comments"])
+	if len(comments) != 2 || comments[0]["author"] != "Riley" || comments[0]["text"] != "This can panic on nil." || comments[1]["text"] != "Agreed, will fix." {
+		t.Fatalf("expected chronological, collapsed comments: %#v", comments)
+	}
+	resolved := asMapSlice(files[2]["resolved"])
+	if len(resolved) != 1 || resolved[0]["summary"] != "Rename this." || resolved[0]["commentCount"] != 2 || resolved[0]["lastCommentBy"] != "Avery" {
+		t.Fatalf("unexpected resolved summary: %#v", resolved)
+	}
+	if _, ok := resolved[0]["comments"]; ok {
+		t.Fatalf("resolved threads should not carry comments: %#v", resolved[0])
+	}
+}
+
+func TestBuildDiscussionDigest_TruncatesComments(t *testing.T) {
+	digest := buildDiscussionDigest("42", digestTestThreads(), DigestOptions{MaxCommentChars: 10})
+	general := asMapSlice(digest["files"])[0]
+	comment := asMapSlice(asMapSlice(general["open"])[0]["comments"])[0]
+	if comment["text"] != "Please ad…" {
+		t.Fatalf("unexpected truncated text: %q", comment["text"])
+	}
+}
+
+func TestRenderDiscussionDigest(t *testing.T) {
+	markdown := RenderDiscussionDigest(buildDiscussionDigest("42", digestTestThreads(), DigestOptions{}))
+	wants := []string{
+		"# PR 42 discussion digest\n",
+		"4 threads (3 open, 1 resolved)",
+		"\n## General\n\n### Open\n\n- **#1** active · Riley\n  - Riley, 2024-04-30 10:00: Please add a changelog entry.\n",
+		"- **#9** L4 pending · Lint Bot (bot)\n",
+		"- **#5** L30 active · Riley\n  - Riley, 2024-05-01 10:00: This can panic on nil.\n  - Avery, 2024-05-02 09:00: Agreed, will fix.\n",
+		"### Resolved\n\n- #3 L12 fixed · Riley: Rename this. (2 comments, last by Avery)\n",
+	}
+	for _, want := range wants {
+		if !strings.Contains(markdown, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+	if strings.Index(markdown, "## /src/a.go") > strings.Index(markdown, "## /src/b.go") {
+		t.Fatalf("expected files in path order:\n%s", markdown)
+	}
+}
+
+func TestNormalizeDigestFormat(t *testing.T) {
+	for input, want := range map[string]string{"": "markdown", "-": "markdown", "MD": "markdown", "json": "json"} {
+		if got, err := NormalizeDigestFormat(input); err != nil || got != want {
+			t.Fatalf("NormalizeDigestFormat(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := NormalizeDigestFormat("html"); err == nil {
+		t.Fatalf("expected format error")
+	}
+}
@@ -0,0 +1,404 @@
+package pullrequests
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/sarif"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	ExportFormatJSON  = "json"
+	ExportFormatSARIF = "sarif"
+	ExportFormatCSV   = "csv"
+
+	exportToolName    = "ado-reviewer"
+	exportGeneralRule = "review-comment"
+)
+
+// exportColumns are the CSV columns, in order; each is a key of an exported thread record.
+var exportColumns = []string{
+	"pullRequestId", "repositoryId", "pullRequestTitle", "threadId", "status", "classification", "filePath", "line", "endLine",
+	"severity", "category", "title", "author", "authorUniqueName", "publishedDate", "lastUpdatedDate",
+	"commentCount", "lastCommentBy", "fingerprint", "comment",
+}
+
+// ExportThreadsOptions selects the threads to export: those of one pull request, or, without
+// PullRequestID, those of every pull request created between From and To in Project (optionally
+// only in RepositoryID).
+type ExportThreadsOptions struct {
+	Organization  string
+	Project       string
+	RepositoryID  string
+	PullRequestID string
+	From          time.Time
+	To            time.Time
+	Status        string
+	ExcludeSystem bool
+}
+
+// NormalizeExportFormat validates the export format; "" and "-" mean json.
+func NormalizeExportFormat(format string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	switch normalized {
+	case "", "-", ExportFormatJSON:
+		return ExportFormatJSON, nil
+	case ExportFormatSARIF, ExportFormatCSV:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("format must be one of: json, sarif, csv")
+	}
+}
+
+// ExportThreads flattens pull request threads into audit records with their position, status,
+// author, timestamps and, for threads posted by submit-review, the finding's severity, category,
+// title and fingerprint.
+func ExportThreads(options ExportThreadsOptions) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	project := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
+	if project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	if prID != "" && repo == "" {
+		return nil, fmt.Errorf("repositoryId is required when pullRequestId is set")
+	}
+	if prID == "" && options.From.IsZero() {
+		return nil, fmt.Errorf("from is required when pullRequestId is not set")
+	}
+	to := options.To
+	if prID == "" && to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if !options.From.IsZero() && to.Before(options.From) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	pullRequests := []pullRequestRef{{id: prID, repositoryID: repo}}
+	if prID == "" {
+		criteria := url.Values{}
+		criteria.Set("searchCriteria.status", "all")
+		criteria.Set("searchCriteria.queryTimeRangeType", "created")
+		criteria.Set("searchCriteria.minTime", options.From.UTC().Format(time.RFC3339))
+		criteria.Set("searchCriteria.maxTime", to.UTC().Format(time.RFC3339))
+		pullRequests, err = listPullRequests(client, project, repo, criteria)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Exports describe who wrote each thread, not who ran the export, so no thread is classified as self.
+	classifier, err := NewThreadClassifier("")
+	if err != nil {
+		return nil, err
+	}
+	responses, fetchErrors := fetchPullRequestThreads(client, project, pullRequests)
+	records := make([]map[string]any, 0)
+	failures := make([]map[string]any, 0)
+	for index, pullRequest := range pullRequests {
+		if fetchErrors[index] != nil {
+			failures = append(failures, map[string]any{"pullRequestId": pullRequest.id, "error": fetchErrors[index].Error()})
+			continue
+		}
+		rawThreads, _ := responses[index]["value"].([]any)
+		for _, raw := range rawThreads {
+			thread, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			class := classifier.Classify(thread)
+			if !isExportedThread(thread, class, options) {
+				continue
+			}
+			record := exportThreadRecord(thread, pullRequest)
+			record["classification"] = class
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if left, right := toBundleInt(records[i]["pullRequestId"]), toBundleInt(records[j]["pullRequestId"]); left != right {
+			return left < right
+		}
+		return toBundleInt(records[i]["threadId"]) < toBundleInt(records[j]["threadId"])
+	})
+
+	output := map[string]any{
+		"organization":     strings.TrimSpace(options.Organization),
+		"project":          project,
+		"exportedAt":       time.Now().UTC().Format(time.RFC3339),
+		"pullRequestCount": len(pullRequests),
+		"threadCount":      len(records),
+		"threads":          records,
+	}
+	if repo != "" {
+		output["repositoryId"] = repo
+	}
+	if prID == "" {
+		output["from"] = options.From.UTC().Format(time.RFC3339)
+		output["to"] = to.UTC().Format(time.RFC3339)
+	}
+	if len(failures) > 0 {
+		output["errors"] = failures
+	}
+	return output, nil
+}
+
+// isExportedThread applies the export filters; class is the thread's classification.
+func isExportedThread(thread map[string]any, class string, options ExportThreadsOptions) bool {
+	if deleted, _ := thread["isDeleted"].(bool); deleted {
+		return false
+	}
+	if options.ExcludeSystem && class == ThreadClassSystem {
+		return false
+	}
+	status := strings.TrimSpace(options.Status)
+	return status == "" || status == "-" || strings.EqualFold(shared.TrimmedString(thread["status"]), status)
+}
+
+// exportThreadRecord flattens one thread. The first comment is kept in full; system threads, which
+// have no human comments, fall back to their first system comment.
+func exportThreadRecord(thread map[string]any, pullRequest pullRequestRef) map[string]any {
+	filePath, line := threadPosition(thread)
+	record := map[string]any{
+		"pullRequestId":   toBundleInt(pullRequest.id),
+		"repositoryId":    pullRequest.repositoryID,
+		"threadId":        toBundleInt(thread["id"]),
+		"status":          shared.TrimmedString(thread["status"]),
+		"publishedDate":   shared.TrimmedString(thread["publishedDate"]),
+		"lastUpdatedDate": shared.TrimmedString(thread["lastUpdatedDate"]),
+	}
+	if pullRequest.title != "" {
+		record["pullRequestTitle"] = pullRequest.title
+	}
+	if filePath != "" {
+		record["filePath"] = filePath
+	}
+	if line > 0 {
+		record["line"] = line
+		if endLine := threadEndLine(thread); endLine > line {
+			record["endLine"] = endLine
+		}
+	}
+
+	comments := humanComments(thread)
+	if len(comments) == 0 {
+		comments = asMapSlice(thread["comments"])
+	}
+	record["commentCount"] = len(comments)
+	if len(comments) > 0 {
+		first, last := comments[0], comments[len(comments)-1]
+		author, _ := first["author"].(map[string]any)
+		record["author"] = identityName(author)
+		if uniqueName := shared.TrimmedString(author["uniqueName"]); uniqueName != "" {
+			record["authorUniqueName"] = uniqueName
+		}
+		record["lastCommentBy"] = identityName(last["author"])
+		content := shared.TrimmedString(first["content"])
+		record["comment"] = content
+		for key, value := range parseFindingComment(content) {
+			record[key] = value
+		}
+	}
+
+	if fingerprint := threadFingerprint(thread); fingerprint != "" {
+		record["fingerprint"] = fingerprint
+	}
+	if properties := threadPropertyValues(thread); len(properties) > 0 {
+		record["properties"] = properties
+	}
+	return record
+}
+
+func threadEndLine(thread map[string]any) int {
+	context, _ := thread["threadContext"].(map[string]any)
+	if _, ok := context["rightFileStart"].(map[string]any); ok {
+		return lineOf(context["rightFileEnd"])
+	}
+	return lineOf(context["leftFileEnd"])
+}
+
+// parseFindingComment reads the severity, category and title back from a comment formatted by
+// FormatFindingComment ("🟠 Major | Security<br/>**Title**<br/>Body"). Other comments yield nothing.
+func parseFindingComment(content string) map[string]any {
+	sections := strings.Split(content, "<br/>")
+	header := strings.TrimSpace(sections[0])
+	severity := ""
+	for name, label := range severityLabels {
+		if strings.HasPrefix(header, label) {
+			severity = name
+			header = strings.TrimSpace(strings.TrimPrefix(header, label))
+			break
+		}
+	}
+	if severity == "" {
+		return nil
+	}
+
+	fields := map[string]any{"severity": severity}
+	if category := strings.TrimSpace(strings.TrimPrefix(header, "|")); category != "" {
+		fields["category"] = category
+	}
+	if len(sections) > 1 {
+		title := strings.TrimSpace(sections[1])
+		if len(title) > 4 && strings.HasPrefix(title, "**") && strings.HasSuffix(title, "**") {
+			fields["title"] = strings.TrimSuffix(strings.TrimPrefix(title, "**"), "**")
+		}
+	}
+	return fields
+}
+
+// threadPropertyValues flattens a thread's typed properties ({"$type": ..., "$value": ...}) to
+// their values.
+func threadPropertyValues(thread map[string]any) map[string]any {
+	properties, _ := thread["properties"].(map[string]any)
+	values := make(map[string]any, len(properties))
+	for name, raw := range properties {
+		if typed, ok := raw.(map[string]any); ok {
+			if value, ok := typed["$value"]; ok {
+				values[name] = value
+				continue
+			}
+		}
+		values[name] = raw
+	}
+	return values
+}
+
+// RenderThreadsCSV renders the threads of an export as CSV with a header row. Text cells that a
+// spreadsheet would run as a formula are escaped (see csvText).
+func RenderThreadsCSV(export map[string]any) (string, error) {
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	if err := writer.Write(exportColumns); err != nil {
+		return "", err
+	}
+	for _, record := range asMapSlice(export["threads"]) {
+		row := make([]string, len(exportColumns))
+		for index, column := range exportColumns {
+			switch value := record[column].(type) {
+			case nil:
+			case string:
+				row[index] = csvText(value)
+			default:
+				row[index] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	return builder.String(), writer.Error()
+}
+
+// csvText prefixes text starting with =, +, -, @, a tab or a carriage return with a quote, so that
+// spreadsheets show comments, titles and names as text instead of evaluating them.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// BuildThreadsSarif converts the threads of an export to a SARIF 2.1.0 log with one result per
+// thread. The finding category becomes the rule, the severity the level, and threads resolved as
+// won't fix, by design or closed are reported as accepted external suppressions.
+func BuildThreadsSarif(export map[string]any) sarif.Log {
+	rules := map[string]sarif.Rule{}
+	results := make([]sarif.Result, 0)
+	for _, record := range asMapSlice(export["threads"]) {
+		ruleID, ruleName := exportGeneralRule, "Review comment"
+		if category := shared.TrimmedString(record["category"]); category != "" {
+			ruleID, ruleName = exportRuleID(category), category
+		}
+		if _, ok := rules[ruleID]; !ok {
+			rules[ruleID] = sarif.Rule{ID: ruleID, Name: ruleName, ShortDescription: &sarif.Message{Text: ruleName}}
+		}
+
+		result := sarif.Result{
+			RuleID:  ruleID,
+			Level:   exportLevel(shared.TrimmedString(record["severity"])),
+			Message: sarif.Message{Text: shared.TrimmedString(record["comment"])},
+			Properties: map[string]any{
+				"pullRequestId": record["pullRequestId"],
+				"threadId":      record["threadId"],
+				"status":        record["status"],
+			},
+		}
+		if result.Message.Text == "" {
+			result.Message.Text = "(no comment text)"
+		}
+		for _, key := range []string{"repositoryId", "pullRequestTitle", "severity", "title", "author", "authorUniqueName", "publishedDate", "lastUpdatedDate", "commentCount", "lastCommentBy"} {
+			if value, ok := record[key]; ok && value != "" {
+				result.Properties[key] = value
+			}
+		}
+		if filePath := shared.TrimmedString(record["filePath"]); filePath != "" {
+			location := &sarif.PhysicalLocation{ArtifactLocation: sarif.ArtifactLocation{URI: strings.TrimPrefix(filePath, "/")}}
+			if line := toBundleInt(record["line"]); line > 0 {
+				location.Region = &sarif.Region{StartLine: line}
+				if endLine := toBundleInt(record["endLine"]); endLine > line {
+					location.Region.EndLine = endLine
+				}
+			}
+			result.Locations = []sarif.Location{{PhysicalLocation: location}}
+		}
+		if fingerprint := shared.TrimmedString(record["fingerprint"]); fingerprint != "" {
+			result.PartialFingerprints = map[string]string{FingerprintProperty: fingerprint}
+		}
+		switch status := strings.ToLower(shared.TrimmedString(record["status"])); status {
+		case "wontfix", "bydesign", "closed":
+			result.Suppressions = []sarif.Suppression{{Kind: "external", Status: "accepted", Justification: "thread resolved as " + shared.TrimmedString(record["status"])}}
+		}
+		results = append(results, result)
+	}
+
+	ruleIDs := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	driver := sarif.Driver{Name: exportToolName, Rules: make([]sarif.Rule, 0, len(ruleIDs))}
+	for _, id := range ruleIDs {
+		driver.Rules = append(driver.Rules, rules[id])
+	}
+
+	runProperties := map[string]any{}
+	for _, key := range []string{"organization", "project", "repositoryId", "from", "to", "exportedAt", "pullRequestCount"} {
+		if value, ok := export[key]; ok {
+			runProperties[key] = value
+		}
+	}
+	return sarif.Log{
+		Schema:  sarif.Schema,
+		Version: sarif.Version,
+		Runs:    []sarif.Run{{Tool: sarif.Tool{Driver: driver}, Results: results, Properties: runProperties}},
+	}
+}
+
+// exportRuleID turns a category such as "Code Quality" into a rule id such as "code-quality".
+func exportRuleID(category string) string {
+	return strings.Join(strings.Fields(strings.ToLower(category)), "-")
+}
+
+func exportLevel(severity string) string {
+	switch severity {
+	case "critical", "major":
+		return sarif.LevelError
+	case "minor":
+		return sarif.LevelWarning
+	default:
+		return sarif.LevelNote
+	}
+}
@@ -0,0 +1,225 @@
+package pullrequests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/sarif"
+)
+
+func exportFixtureThreads() []map[string]any {
+	return []map[string]any{
+		{
+			"id":              float64(11),
+			"status":          "active",
+			"publishedDate":   "2024-05-02T10:00:00Z",
+			"lastUpdatedDate": "2024-05-03T09:00:00Z",
+			"threadContext": map[string]any{
+				"filePath":       "/src/handlers/user.go",
+				"rightFileStart": map[string]any{"line": float64(42), "offset": float64(1)},
+				"rightFileEnd":   map[string]any{"line": float64(44), "offset": float64(1)},
+			},
+			"properties": map[string]any{
+				FingerprintProperty: map[string]any{"$type": "System.String", "$value": "v1:abc"},
+			},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "🟠 Major | Security<br/>**User input is rendered unescaped**<br/>Escape the name.", "commentType": "text",
+					"author": map[string]any{"displayName": "Reviewer", "uniqueName": "reviewer@example.com"}},
+				map[string]any{"id": float64(2), "content": "Done, thanks.", "commentType": "text", "author": map[string]any{"displayName": "Author"}},
+			},
+		},
+		{
+			"id":     float64(12),
+			"status": "wontFix",
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Could this be simpler, \"maybe\"?", "commentType": "text", "author": map[string]any{"displayName": "Reviewer"}},
+			},
+		},
+		{
+			"id":         float64(13),
+			"status":     "active",
+			"properties": map[string]any{"CodeReviewThreadType": map[string]any{"$type": "System.String", "$value": "VoteUpdate"}},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Reviewer voted 10", "commentType": "system", "author": map[string]any{"displayName": "Microsoft.VisualStudio.Services.TFS"}},
+			},
+		},
+	}
+}
+
+func TestExportThreadRecord(t *testing.T) {
+	threads := exportFixtureThreads()
+	pullRequest := pullRequestRef{id: "7", repositoryID: "repo-id", title: "Add user handler"}
+
+	record := exportThreadRecord(threads[0], pullRequest)
+	expected := map[string]any{
+		"pullRequestId": 7, "repositoryId": "repo-id", "pullRequestTitle": "Add user handler", "threadId": 11, "status": "active",
+		"filePath": "/src/handlers/user.go", "line": 42, "endLine": 44, "severity": "major", "category": "Security",
+		"title": "User input is rendered unescaped", "author": "Reviewer", "authorUniqueName": "reviewer@example.com",
+		"lastCommentBy": "Author", "commentCount": 2, "fingerprint": "v1:abc", "publishedDate": "2024-05-02T10:00:00Z",
+	}
+	for key, want := range expected {
+		if record[key] != want {
+			t.Errorf("%s: expected %v, got %v", key, want, record[key])
+		}
+	}
+	if properties, _ := record["properties"].(map[string]any); properties[FingerprintProperty] != "v1:abc" {
+		t.Fatalf("expected flattened properties, got %v", record["properties"])
+	}
+
+	general := exportThreadRecord(threads[1], pullRequest)
+	if _, ok := general["filePath"]; ok {
+		t.Fatalf("expected no file path for a general thread: %v", general)
+	}
+	if _, ok := general["severity"]; ok {
+		t.Fatalf("expected no finding fields for a plain comment: %v", general)
+	}
+
+	system := exportThreadRecord(threads[2], pullRequest)
+	if system["comment"] != "Reviewer voted 10" || system["commentCount"] != 1 {
+		t.Fatalf("expected system threads to fall back to system comments: %v", system)
+	}
+}
+
+func TestIsExportedThread(t *testing.T) {
+	threads := exportFixtureThreads()
+	classifier := ThreadClassifier{BotIdentities: DefaultBotIdentities, PropertyRules: DefaultThreadPropertyRules}
+	classes := make([]string, len(threads))
+	for index, thread := range threads {
+		classes[index] = classifier.Classify(thread)
+	}
+	if classes[0] != ThreadClassBot || classes[1] != ThreadClassHuman || classes[2] != ThreadClassSystem {
+		t.Fatalf("unexpected classes %v", classes)
+	}
+	options := ExportThreadsOptions{ExcludeSystem: true}
+	if !isExportedThread(threads[0], classes[0], options) || isExportedThread(threads[2], classes[2], options) {
+		t.Fatal("expected system threads to be excluded")
+	}
+	options = ExportThreadsOptions{Status: "wontfix"}
+	if isExportedThread(threads[0], classes[0], options) || !isExportedThread(threads[1], classes[1], options) {
+		t.Fatal("expected the status filter to ignore case")
+	}
+	if isExportedThread(map[string]any{"isDeleted": true}, ThreadClassHuman, ExportThreadsOptions{}) {
+		t.Fatal("expected deleted threads to be excluded")
+	}
+}
+
+func TestParseFindingComment(t *testing.T) {
+	fields := parseFindingComment("🔵 Suggestion<br/>**Rename**<br/>Body")
+	if fields["severity"] != "suggestion" || fields["title"] != "Rename" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	if _, ok := fields["category"]; ok {
+		t.Fatalf("expected no category: %v", fields)
+	}
+	if fields := parseFindingComment("Looks good to me"); fields != nil {
+		t.Fatalf("expected nothing for a plain comment, got %v", fields)
+	}
+}
+
+func exportFixture() map[string]any {
+	threads := exportFixtureThreads()
+	pullRequest := pullRequestRef{id: "7", repositoryID: "repo-id"}
+	return map[string]any{
+		"organization":     "org",
+		"project":          "proj",
+		"pullRequestCount": 1,
+		"threads": []map[string]any{
+			exportThreadRecord(threads[0], pullRequest),
+			exportThreadRecord(threads[1], pullRequest),
+		},
+	}
+}
+
+func TestRenderThreadsCSV(t *testing.T) {
+	rendered, err := RenderThreadsCSV(exportFixture())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(rendered), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two rows, got:\n%s", rendered)
+	}
+	if lines[0] != strings.Join(exportColumns, ",") {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "7,repo-id,,11,active,,/src/handlers/user.go,42,44,major,Security,User input is rendered unescaped,Reviewer,") {
+		t.Fatalf("unexpected row %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], `"Could this be simpler, ""maybe""?"`) {
+		t.Fatalf("expected the comment to be quoted, got %q", lines[2])
+	}
+}
+
+func TestRenderThreadsCSV_EscapesFormulas(t *testing.T) {
+	export := map[string]any{"threads": []map[string]any{{
+		"pullRequestId": -1, "threadId": 3, "title": "+SUM(A1)", "author": "@admin", "comment": `=HYPERLINK("http://x")`, "category": "-1",
+	}}}
+	rendered, err := RenderThreadsCSV(export)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	row := strings.Split(strings.TrimSpace(rendered), "\n")[1]
+	for _, want := range []string{"'+SUM(A1)", "'@admin", `"'=HYPERLINK(""http://x"")"`, "'-1"} {
+		if !strings.Contains(row, want) {
+			t.Fatalf("expected %s in row %q", want, row)
+		}
+	}
+	if !strings.HasPrefix(row, "-1,") {
+		t.Fatalf("expected numbers to stay unescaped, got %q", row)
+	}
+}
+
+func TestBuildThreadsSarif(t *testing.T) {
+	log := BuildThreadsSarif(exportFixture())
+	encoded, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := sarif.Parse(encoded)
+	if err != nil {
+		t.Fatalf("expected a valid SARIF log, got %v", err)
+	}
+
+	run := parsed.Runs[0]
+	if run.Tool.Driver.Name != exportToolName || len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("unexpected driver: %+v", run.Tool.Driver)
+	}
+	if run.Properties["project"] != "proj" {
+		t.Fatalf("expected export metadata on the run, got %v", run.Properties)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected two results, got %d", len(run.Results))
+	}
+
+	finding := run.Results[0]
+	if finding.RuleID != "security" || finding.Level != sarif.LevelError || finding.PartialFingerprints[FingerprintProperty] != "v1:abc" {
+		t.Fatalf("unexpected finding result: %+v", finding)
+	}
+	location := finding.PrimaryLocation()
+	if location == nil || location.ArtifactLocation.URI != "src/handlers/user.go" || location.Region.StartLine != 42 || location.Region.EndLine != 44 {
+		t.Fatalf("unexpected location: %+v", location)
+	}
+	if finding.IsSuppressed() {
+		t.Fatal("expected an active thread not to be suppressed")
+	}
+
+	comment := run.Results[1]
+	if comment.RuleID != exportGeneralRule || comment.Level != sarif.LevelNote || comment.PrimaryLocation() != nil {
+		t.Fatalf("unexpected comment result: %+v", comment)
+	}
+	if !comment.IsSuppressed() || comment.Suppressions[0].Status != "accepted" {
+		t.Fatalf("expected a won't fix thread to be suppressed, got %+v", comment.Suppressions)
+	}
+}
+
+func TestNormalizeExportFormat(t *testing.T) {
+	for input, want := range map[string]string{"": ExportFormatJSON, "-": ExportFormatJSON, "SARIF": ExportFormatSARIF, "csv": ExportFormatCSV} {
+		if got, err := NormalizeExportFormat(input); err != nil || got != want {
+			t.Errorf("NormalizeExportFormat(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := NormalizeExportFormat("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,227 @@
+package pullrequests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+// FingerprintProperty is the thread property that identifies a finding across review runs.
+// It must not start with "CodeReview", which marks system threads.
+const FingerprintProperty = "AdoReviewer.Fingerprint"
+
+const (
+	DuplicateSkip   = "skip"
+	DuplicateUpdate = "update"
+	DuplicateReopen = "reopen"
+	DuplicatePost   = "post"
+
+	duplicateActionSkipped   = "skipped"
+	duplicateActionUnchanged = "unchanged"
+	duplicateActionUpdated   = "updated"
+	duplicateActionReopened  = "reopened"
+	duplicateActionNotOwned  = "notOwned"
+)
+
+// NormalizeDuplicatePolicy validates what to do when a thread with the same fingerprint exists:
+// skip it (default), update its first comment, reopen it if resolved (updating the comment too),
+// or post a new thread anyway.
+func NormalizeDuplicatePolicy(policy string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(policy))
+	switch normalized {
+	case "", "-", DuplicateSkip:
+		return DuplicateSkip, nil
+	case DuplicateUpdate, DuplicateReopen, DuplicatePost:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("onDuplicate must be one of: skip, update, reopen, post")
+	}
+}
+
+// Fingerprint identifies a finding by category, file and a hash of its text, ignoring line numbers,
+// case and whitespace so that re-running a review on a moved or reformatted finding still matches.
+func Fingerprint(category, filePath, text string) string {
+	normalizedPath := strings.ToLower(strings.TrimSpace(filePath))
+	if normalizedPath == "-" {
+		normalizedPath = ""
+	}
+	if normalizedPath != "" {
+		if path, err := ado.NormalizeADOFilePath(normalizedPath); err == nil {
+			normalizedPath = path
+		}
+	}
+	content := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	contentHash := sha256.Sum256([]byte(content))
+	key := strings.ToLower(strings.TrimSpace(category)) + "|" + normalizedPath + "|" + hex.EncodeToString(contentHash[:])
+	sum := sha256.Sum256([]byte(key))
+	return "v1:" + hex.EncodeToString(sum[:16])
+}
+
+func fingerprintProperties(fingerprint string) map[string]any {
+	return map[string]any{
+		FingerprintProperty: map[string]any{"$type": "System.String", "$value": fingerprint},
+	}
+}
+
+// threadFingerprint reads the fingerprint property of a thread returned by the threads API.
+func threadFingerprint(thread map[string]any) string {
+	properties, _ := thread["properties"].(map[string]any)
+	switch value := properties[FingerprintProperty].(type) {
+	case map[string]any:
+		return shared.TrimmedString(value["$value"])
+	case string:
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// threadsByFingerprint indexes the first non-deleted thread for each fingerprint.
+func threadsByFingerprint(threads []any) map[string]map[string]any {
+	indexed := map[string]map[string]any{}
+	for _, raw := range threads {
+		thread, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if deleted, _ := thread["isDeleted"].(bool); deleted {
+			continue
+		}
+		fingerprint := threadFingerprint(thread)
+		if fingerprint == "" {
+			continue
+		}
+		if _, exists := indexed[fingerprint]; !exists {
+			indexed[fingerprint] = thread
+		}
+	}
+	return indexed
+}
+
+func listThreadsByFingerprint(client *ado.Client, threadsURL string) (map[string]map[string]any, error) {
+	response := map[string]any{}
+	if err := client.GetJSON(threadsURL, &response); err != nil {
+		return nil, err
+	}
+	threads, _ := response["value"].([]any)
+	return threadsByFingerprint(threads), nil
+}
+
+// authenticatedUser looks the authenticated identity up once, when a duplicate's comment is about
+// to be changed.
+type authenticatedUser struct {
+	client *ado.Client
+	id     string
+	err    error
+	done   bool
+}
+
+func (u *authenticatedUser) ID() (string, error) {
+	if !u.done {
+		u.done = true
+		u.id, u.err = u.client.GetAuthenticatedUserID()
+	}
+	return u.id, u.err
+}
+
+// applyDuplicatePolicy handles an existing thread with the same fingerprint and reports the action
+// taken: skipped, unchanged, updated, reopened or notOwned. The first comment is only updated when
+// user wrote it (see checkOwnComment); otherwise it is left as is and the action is notOwned,
+// unless the thread is reopened. threadsURL is the collection URL with its query.
+func applyDuplicatePolicy(client *ado.Client, threadsURL string, thread map[string]any, content, policy string, user *authenticatedUser) (string, error) {
+	if policy == DuplicateSkip {
+		return duplicateActionSkipped, nil
+	}
+
+	threadURL := threadItemURL(threadsURL, toBundleInt(thread["id"]))
+	action := duplicateActionUnchanged
+	if comment := firstThreadComment(thread); comment != nil && shared.TrimmedString(comment["content"]) != strings.TrimSpace(content) {
+		userID, err := user.ID()
+		if err != nil {
+			return "", err
+		}
+		if checkOwnComment(comment, userID) != nil {
+			action = duplicateActionNotOwned
+		} else {
+			commentURL := threadItemURL(strings.Replace(threadURL, "?", "/comments?", 1), toBundleInt(comment["id"]))
+			if err := client.PatchJSON(commentURL, map[string]string{"content": content}, nil); err != nil {
+				return "", err
+			}
+			action = duplicateActionUpdated
+		}
+	}
+
+	if policy == DuplicateReopen && isResolvedThreadStatus(shared.TrimmedString(thread["status"])) {
+		if err := client.PatchJSON(threadURL, map[string]string{"status": "active"}, nil); err != nil {
+			return "", err
+		}
+		action = duplicateActionReopened
+	}
+	return action, nil
+}
+
+// postFingerprintedThread posts payload as a new thread carrying fingerprint, unless the duplicate
+// policy finds a thread with the same fingerprint; the result then describes that thread and the
+// action taken under "duplicate".
+func postFingerprintedThread(client *ado.Client, threadsURL string, payload map[string]any, fingerprint, policy string) (map[string]any, error) {
+	if policy != DuplicatePost {
+		existing, err := listThreadsByFingerprint(client, threadsURL)
+		if err != nil {
+			return nil, err
+		}
+		if thread, ok := existing[fingerprint]; ok {
+			content, _ := payload["comments"].([]map[string]any)[0]["content"].(string)
+			action, err := applyDuplicatePolicy(client, threadsURL, thread, content, policy, &authenticatedUser{client: client})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"id":          thread["id"],
+				"status":      thread["status"],
+				"fingerprint": fingerprint,
+				"duplicate":   map[string]any{"threadId": thread["id"], "action": action},
+			}, nil
+		}
+	}
+	payload["properties"] = fingerprintProperties(fingerprint)
+
+	response := map[string]any{}
+	if err := client.PostJSON(threadsURL, payload, &response); err != nil {
+		return nil, err
+	}
+	response["fingerprint"] = fingerprint
+	return response, nil
+}
+
+// threadItemURL turns ".../threads?api-version=x" into ".../threads/{id}?api-version=x".
+func threadItemURL(collectionURL string, id int) string {
+	base, query, _ := strings.Cut(collectionURL, "?")
+	return fmt.Sprintf("%s/%d?%s", base, id, query)
+}
+
+func firstThreadComment(thread map[string]any) map[string]any {
+	comments, _ := thread["comments"].([]any)
+	for _, raw := range comments {
+		comment, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if deleted, _ := comment["isDeleted"].(bool); deleted {
+			continue
+		}
+		return comment
+	}
+	return nil
+}
+
+func isResolvedThreadStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "fixed", "closed", "bydesign", "wontfix":
+		return true
+	default:
+		return false
+	}
+}
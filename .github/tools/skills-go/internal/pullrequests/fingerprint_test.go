@@ -0,0 +1,86 @@
+package pullrequests
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("Security", "/src/app.go", "Escape the input")
+	if base != Fingerprint(" security ", "src/app.go", "escape   the\ninput") {
+		t.Fatalf("expected case, whitespace and path normalization to keep the fingerprint stable")
+	}
+	if base == Fingerprint("Performance", "/src/app.go", "Escape the input") {
+		t.Fatalf("expected category to change the fingerprint")
+	}
+	if base == Fingerprint("Security", "/src/other.go", "Escape the input") {
+		t.Fatalf("expected file to change the fingerprint")
+	}
+	if base == Fingerprint("Security", "/src/app.go", "Validate the input") {
+		t.Fatalf("expected content to change the fingerprint")
+	}
+	if Fingerprint("", "-", "General note") != Fingerprint("", "", "General note") {
+		t.Fatalf("expected - to mean no file")
+	}
+
+	first := FindingFingerprint(Finding{Category: "Security", File: "/a.go", Line: 3, Severity: "major", Title: "T", Body: "B"})
+	moved := FindingFingerprint(Finding{Category: "Security", File: "/a.go", Line: 9, Severity: "minor", Title: "T", Body: "B"})
+	if first != moved {
+		t.Fatalf("expected line and severity changes to keep the finding fingerprint")
+	}
+}
+
+func TestThreadsByFingerprint(t *testing.T) {
+	threads := []any{
+		map[string]any{"id": float64(1), "properties": map[string]any{FingerprintProperty: map[string]any{"$type": "System.String", "$value": "v1:a"}}},
+		map[string]any{"id": float64(2), "properties": map[string]any{FingerprintProperty: map[string]any{"$value": "v1:a"}}},
+		map[string]any{"id": float64(3), "isDeleted": true, "properties": map[string]any{FingerprintProperty: map[string]any{"$value": "v1:b"}}},
+		map[string]any{"id": float64(4), "properties": map[string]any{"CodeReviewThreadType": map[string]any{"$value": "VoteUpdate"}}},
+		map[string]any{"id": float64(5)},
+	}
+
+	indexed := threadsByFingerprint(threads)
+	if len(indexed) != 1 || indexed["v1:a"]["id"] != float64(1) {
+		t.Fatalf("expected only the first live fingerprinted thread, got %#v", indexed)
+	}
+}
+
+func TestNormalizeDuplicatePolicy(t *testing.T) {
+	for input, want := range map[string]string{"": DuplicateSkip, "-": DuplicateSkip, "Update": DuplicateUpdate, "reopen": DuplicateReopen, "post": DuplicatePost} {
+		got, err := NormalizeDuplicatePolicy(input)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeDuplicatePolicy(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizeDuplicatePolicy("replace"); err == nil {
+		t.Fatalf("expected unknown policy to fail")
+	}
+}
+
+func TestThreadItemURL(t *testing.T) {
+	got := threadItemURL("https://dev.azure.com/o/p/_apis/git/repositories/r/pullRequests/1/threads?api-version=7.2-preview", 1234567)
+	want := "https://dev.azure.com/o/p/_apis/git/repositories/r/pullRequests/1/threads/1234567?api-version=7.2-preview"
+	if got != want {
+		t.Fatalf("unexpected thread URL: %s", got)
+	}
+	if !isResolvedThreadStatus("wontFix") || isResolvedThreadStatus("active") || isResolvedThreadStatus("pending") {
+		t.Fatalf("unexpected resolved status classification")
+	}
+}
+
+func TestApplyDuplicatePolicy_LeavesOtherAuthorsComments(t *testing.T) {
+	thread := map[string]any{
+		"id":     float64(17),
+		"status": "active",
+		"comments": []any{
+			map[string]any{"id": float64(1), "content": "Old wording", "author": map[string]any{"id": "pipeline", "displayName": "Build Service"}},
+		},
+	}
+	user := &authenticatedUser{id: "me", done: true}
+	action, err := applyDuplicatePolicy(nil, "https://example.test/threads?api-version=7.2-preview", thread, "New wording", DuplicateUpdate, user)
+	if err != nil || action != duplicateActionNotOwned {
+		t.Fatalf("expected notOwned without patching, got %q, %v", action, err)
+	}
+
+	action, err = applyDuplicatePolicy(nil, "https://example.test/threads?api-version=7.2-preview", thread, "Old wording", DuplicateUpdate, user)
+	if err != nil || action != duplicateActionUnchanged {
+		t.Fatalf("expected unchanged for identical text, got %q, %v", action, err)
+	}
+}
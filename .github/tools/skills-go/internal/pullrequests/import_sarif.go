@@ -0,0 +1,366 @@
+package pullrequests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/sarif"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	OutsideDiffSummarize = "summarize"
+	OutsideDiffDrop      = "drop"
+
+	sarifTitleRunes         = 120
+	maxOutsideDiffSummaries = 50
+)
+
+// ImportSarifOptions selects the pull request SARIF results are posted to. OutsideDiff decides what
+// happens to results that do not touch a changed line; SourceRoot is stripped from absolute
+// result paths before they are matched against the pull request's files.
+type ImportSarifOptions struct {
+	Organization  string
+	Project       string
+	RepositoryID  string
+	PullRequestID string
+	IterationID   string
+	OutsideDiff   string
+	SourceRoot    string
+	OnDuplicate   string
+	DryRun        bool
+}
+
+// NormalizeOutsideDiffPolicy validates what to do with results outside the diff: list them in
+// one summary thread (default) or drop them.
+func NormalizeOutsideDiffPolicy(policy string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(policy))
+	switch normalized {
+	case "", "-", OutsideDiffSummarize, "summary":
+		return OutsideDiffSummarize, nil
+	case OutsideDiffDrop:
+		return OutsideDiffDrop, nil
+	default:
+		return "", fmt.Errorf("outsideDiff must be one of: summarize, drop")
+	}
+}
+
+// ImportSarif posts the results of a SARIF log that touch lines changed by the pull request as
+// review threads, through SubmitReview. Each thread is fingerprinted by tool, file, ruleId and the
+// result's partial fingerprints, so importing the same log again finds the existing threads.
+func ImportSarif(options ImportSarifOptions, log sarif.Log) (map[string]any, error) {
+	policy, err := NormalizeOutsideDiffPolicy(options.OutsideDiff)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := NormalizeDuplicatePolicy(options.OnDuplicate); err != nil {
+		return nil, err
+	}
+	commentOptions := CommentOptions{
+		Organization:  strings.TrimSpace(options.Organization),
+		Project:       strings.TrimSpace(options.Project),
+		RepositoryID:  strings.TrimSpace(options.RepositoryID),
+		PullRequestID: strings.TrimSpace(options.PullRequestID),
+		IterationID:   strings.TrimSpace(options.IterationID),
+	}
+	if commentOptions.Organization == "" || commentOptions.Project == "" || commentOptions.RepositoryID == "" || commentOptions.PullRequestID == "" {
+		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
+	}
+
+	source, err := newPositionSource(commentOptions)
+	if err != nil {
+		return nil, err
+	}
+	changedPaths := make([]string, 0)
+	for _, entry := range asMapSlice(ProjectChangedFiles(source.changes, commentOptions.PullRequestID, source.iteration.ID)["files"]) {
+		if isFolder, _ := entry["isFolder"].(bool); !isFolder {
+			changedPaths = append(changedPaths, shared.TrimmedString(entry["path"]))
+		}
+	}
+	validate := func(filePath string, requested commentRange) error {
+		_, err := source.resolve(filePath, requested, PositionModeValidate, false)
+		return err
+	}
+	imported := importSarifResults(log, options.SourceRoot, changedPaths, validate)
+
+	document := ReviewDocument{Findings: imported.findings}
+	if policy == OutsideDiffSummarize && len(imported.outside) > 0 {
+		document.Summary = summarizeOutsideDiff(imported.outside)
+		document.SummaryKey = outsideDiffSummaryKey(log)
+	}
+
+	var output map[string]any
+	if len(document.Findings) == 0 && document.Summary == "" {
+		status := SubmitStatusComplete
+		if options.DryRun {
+			status = SubmitStatusDryRun
+		}
+		output = submitResult(status, []map[string]any{}, nil, nil, nil)
+	} else {
+		output, err = SubmitReview(SubmitReviewOptions{
+			Organization:  commentOptions.Organization,
+			Project:       commentOptions.Project,
+			RepositoryID:  commentOptions.RepositoryID,
+			PullRequestID: commentOptions.PullRequestID,
+			PositionMode:  PositionModeNone,
+			IterationID:   source.iteration.ID,
+			OnDuplicate:   options.OnDuplicate,
+			DryRun:        options.DryRun,
+		}, document)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for index, result := range asMapSlice(output["findings"]) {
+		if index < len(imported.rules) {
+			result["ruleId"] = imported.rules[index]
+		}
+	}
+	output["iterationId"] = toBundleInt(source.iteration.ID)
+	output["outsideDiffPolicy"] = policy
+	output["outsideDiff"] = imported.outside
+	if len(imported.collapsed) > 0 {
+		output["collapsed"] = imported.collapsed
+	}
+	output["sarif"] = map[string]any{
+		"runs":        len(log.Runs),
+		"results":     imported.total,
+		"suppressed":  imported.suppressed,
+		"inDiff":      len(imported.findings),
+		"collapsed":   len(imported.collapsed),
+		"outsideDiff": len(imported.outside),
+	}
+	return output, nil
+}
+
+type sarifImport struct {
+	findings   []Finding
+	rules      []string
+	outside    []map[string]any
+	collapsed  []map[string]any
+	total      int
+	suppressed int
+}
+
+// importSarifResults converts every unsuppressed result to a finding, keeping those validate
+// accepts and recording the others, with the reason, as outside the diff.
+func importSarifResults(log sarif.Log, sourceRoot string, changedPaths []string, validate func(string, commentRange) error) sarifImport {
+	imported := sarifImport{findings: make([]Finding, 0), rules: make([]string, 0), outside: make([]map[string]any, 0), collapsed: make([]map[string]any, 0)}
+	lineByFingerprint := map[string]int{}
+	for _, run := range log.Runs {
+		for _, result := range run.Results {
+			imported.total++
+			if result.IsSuppressed() {
+				imported.suppressed++
+				continue
+			}
+			finding := sarifFinding(run, result)
+			ruleID := result.EffectiveRuleID()
+			entry := map[string]any{
+				"tool":     finding.Category,
+				"ruleId":   ruleID,
+				"severity": finding.Severity,
+				"message":  excerpt(strings.TrimSpace(result.Message.Text), compactExcerptRunes),
+			}
+			skip := func(reason string) {
+				entry["reason"] = reason
+				imported.outside = append(imported.outside, entry)
+			}
+
+			location := result.PrimaryLocation()
+			if location == nil || location.Region == nil || location.Region.StartLine < 1 {
+				skip("result has no file and line")
+				continue
+			}
+			uri := run.ResolveURI(location.ArtifactLocation)
+			startLine, endLine := location.Region.StartLine, location.Region.EndLine
+			if endLine < startLine {
+				endLine = startLine
+			}
+			entry["uri"] = sarif.URIPath(uri)
+			entry["line"] = startLine
+			filePath, ok := mapSarifPath(uri, sourceRoot, changedPaths)
+			if !ok {
+				skip("file is not changed in the pull request")
+				continue
+			}
+			entry["file"] = filePath
+			if err := validate(filePath, commentRange{Side: CommentSideRight, StartLine: startLine, EndLine: endLine}); err != nil {
+				skip(err.Error())
+				continue
+			}
+
+			finding.File = filePath
+			finding.Line = startLine
+			if endLine > startLine {
+				finding.EndLine = endLine
+			}
+			// A review document cannot hold two findings with one fingerprint, so results that only
+			// differ by line (same rule and message, no partial fingerprints) are posted once.
+			fingerprint := FindingFingerprint(finding)
+			if firstLine, seen := lineByFingerprint[fingerprint]; seen {
+				entry["duplicateOfLine"] = firstLine
+				imported.collapsed = append(imported.collapsed, entry)
+				continue
+			}
+			lineByFingerprint[fingerprint] = startLine
+			imported.findings = append(imported.findings, finding)
+			imported.rules = append(imported.rules, ruleID)
+		}
+	}
+	return imported
+}
+
+// sarifFinding maps a result to a finding without a position. The tool name is the category, the
+// rule's short description the title, and the body carries the message, rule id and help link.
+func sarifFinding(run sarif.Run, result sarif.Result) Finding {
+	tool := strings.TrimSpace(run.Tool.Driver.Name)
+	if tool == "" {
+		tool = "SARIF"
+	}
+	ruleID := result.EffectiveRuleID()
+	rule := run.RuleFor(result)
+
+	title := ""
+	helpURI := ""
+	if rule != nil {
+		if rule.ShortDescription != nil {
+			title = strings.TrimSpace(rule.ShortDescription.Text)
+		}
+		if title == "" {
+			title = strings.TrimSpace(rule.Name)
+		}
+		helpURI = strings.TrimSpace(rule.HelpURI)
+	}
+	if title == "" {
+		title = ruleID
+	}
+	message := strings.TrimSpace(result.Message.Markdown)
+	if message == "" {
+		message = strings.TrimSpace(result.Message.Text)
+	}
+	if title == "" {
+		title = strings.SplitN(message, "\n", 2)[0]
+	}
+
+	reference := "Rule `" + ruleID + "` (" + tool + ")"
+	if ruleID == "" {
+		reference = "Reported by " + tool
+	}
+	if helpURI != "" {
+		reference += " · [Help](" + helpURI + ")"
+	}
+	body := reference
+	if message != "" {
+		body = message + "<br/>" + reference
+	}
+
+	return Finding{
+		Severity: sarifSeverity(run, result),
+		Category: tool,
+		Title:    excerpt(title, sarifTitleRunes),
+		Body:     body,
+		Key:      "sarif|" + ruleID + "|" + result.StableKey(),
+	}
+}
+
+// sarifSeverity maps a result to a review severity: by its security-severity score when the tool
+// sets one (the CVSS bands GitHub code scanning uses), otherwise by its level.
+func sarifSeverity(run sarif.Run, result sarif.Result) string {
+	if score, ok := run.SecuritySeverity(result); ok {
+		switch {
+		case score >= 9:
+			return "critical"
+		case score >= 7:
+			return "major"
+		case score >= 4:
+			return "minor"
+		default:
+			return "suggestion"
+		}
+	}
+	switch run.EffectiveLevel(result) {
+	case sarif.LevelError:
+		return "major"
+	case sarif.LevelWarning:
+		return "minor"
+	default:
+		return "suggestion"
+	}
+}
+
+// mapSarifPath maps a result URI, with its uriBaseId already resolved, to a changed file of the
+// pull request: relative paths are taken from the repository root and sourceRoot is stripped from
+// absolute paths. Only an absolute file: URI that sourceRoot does not cover falls back to the
+// longest changed path it ends with, which covers build agents' checkout directories.
+func mapSarifPath(uri, sourceRoot string, changedPaths []string) (string, bool) {
+	fileURI := strings.HasPrefix(strings.ToLower(strings.TrimSpace(uri)), "file:")
+	candidate := strings.TrimLeft(strings.TrimPrefix(sarif.URIPath(uri), "./"), "/")
+	stripped := false
+	if root := strings.Trim(sarif.URIPath(sourceRoot), "/"); root != "" && root != "-" {
+		if len(candidate) > len(root) && strings.EqualFold(candidate[:len(root)], root) && candidate[len(root)] == '/' {
+			candidate = candidate[len(root)+1:]
+			stripped = true
+		}
+	}
+	candidate = "/" + candidate
+
+	for _, changed := range changedPaths {
+		if changed == candidate {
+			return changed, true
+		}
+	}
+	if !fileURI || stripped {
+		return "", false
+	}
+	best := ""
+	for _, changed := range changedPaths {
+		if strings.HasSuffix(candidate, changed) && len(changed) > len(best) {
+			best = changed
+		}
+	}
+	return best, best != ""
+}
+
+// outsideDiffSummaryKey keys the outside-diff summary by the log's tools, so each import of the same
+// tools finds the summary thread of the previous run although the listed results changed.
+func outsideDiffSummaryKey(log sarif.Log) string {
+	tools := make([]string, 0, len(log.Runs))
+	for _, run := range log.Runs {
+		tool := strings.ToLower(strings.TrimSpace(run.Tool.Driver.Name))
+		if tool == "" {
+			tool = "sarif"
+		}
+		if !containsString(tools, tool) {
+			tools = append(tools, tool)
+		}
+	}
+	sort.Strings(tools)
+	return strings.Join(tools, ",") + "|sarif-outside-diff"
+}
+
+// summarizeOutsideDiff renders results outside the diff as the markdown of one summary thread.
+func summarizeOutsideDiff(outside []map[string]any) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "**SARIF results outside the changed lines** (%d)\n", len(outside))
+	for index, entry := range outside {
+		if index == maxOutsideDiffSummaries {
+			fmt.Fprintf(&builder, "\n- … and %d more", len(outside)-maxOutsideDiffSummaries)
+			break
+		}
+		location := shared.TrimmedString(entry["file"])
+		if location == "" {
+			location = shared.TrimmedString(entry["uri"])
+		}
+		if line := toBundleInt(entry["line"]); line > 0 && location != "" {
+			location = fmt.Sprintf("%s:%d", location, line)
+		}
+		if location != "" {
+			location = "`" + location + "` "
+		}
+		fmt.Fprintf(&builder, "\n- %s**%s** %s (%s): %s", location, entry["tool"], entry["ruleId"], entry["severity"], entry["message"])
+	}
+	return builder.String()
+}
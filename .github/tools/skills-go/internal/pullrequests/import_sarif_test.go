@@ -0,0 +1,235 @@
+package pullrequests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/sarif"
+)
+
+const importSarifLog = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "gosec", "rules": [
+      {"id": "G101", "shortDescription": {"text": "Look for hard coded credentials"}, "helpUri": "https://securego.io/G101", "properties": {"security-severity": "9.1"}},
+      {"id": "G104", "name": "Errors unhandled", "defaultConfiguration": {"level": "warning"}}
+    ]}},
+    "results": [
+      {"ruleId": "G101", "message": {"text": "Potential hardcoded credentials"}, "partialFingerprints": {"primaryLocationLineHash": "f00:1"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file:///home/agent/_work/1/s/src/config.go"}, "region": {"startLine": 10, "endLine": 12}}}]},
+      {"ruleId": "G104", "message": {"text": "Errors unhandled."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/main.go"}, "region": {"startLine": 40}}}]},
+      {"ruleId": "G104", "message": {"text": "Errors unhandled."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/untouched.go"}, "region": {"startLine": 3}}}]},
+      {"ruleId": "G104", "level": "error", "message": {"text": "Errors unhandled."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/main.go"}, "region": {"startLine": 5}}}]},
+      {"ruleId": "G104", "message": {"text": "Suppressed."}, "suppressions": [{"kind": "inSource"}]},
+      {"ruleId": "G104", "message": {"text": "No location."}}
+    ]
+  }]
+}`
+
+func TestImportSarifResults(t *testing.T) {
+	log, err := sarif.Parse([]byte(importSarifLog))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed := []string{"/src/config.go", "/src/main.go"}
+	validate := func(filePath string, requested commentRange) error {
+		if filePath == "/src/main.go" && requested.StartLine == 5 {
+			return fmt.Errorf("line 5 is not a changed line in %s", filePath)
+		}
+		return nil
+	}
+
+	imported := importSarifResults(log, "", changed, validate)
+	if imported.total != 6 || imported.suppressed != 1 {
+		t.Fatalf("unexpected counts: total %d, suppressed %d", imported.total, imported.suppressed)
+	}
+	if len(imported.findings) != 2 || len(imported.outside) != 3 {
+		t.Fatalf("expected 2 findings and 3 outside the diff, got %d and %d", len(imported.findings), len(imported.outside))
+	}
+
+	credentials := imported.findings[0]
+	if credentials.File != "/src/config.go" || credentials.Line != 10 || credentials.EndLine != 12 {
+		t.Fatalf("unexpected position: %+v", credentials)
+	}
+	if credentials.Severity != "critical" || credentials.Category != "gosec" || credentials.Title != "Look for hard coded credentials" {
+		t.Fatalf("unexpected finding: %+v", credentials)
+	}
+	if !strings.Contains(credentials.Body, "[Help](https://securego.io/G101)") || !strings.Contains(credentials.Body, "Rule `G101`") {
+		t.Fatalf("expected the rule and help link in the body, got %q", credentials.Body)
+	}
+	if imported.rules[0] != "G101" || imported.rules[1] != "G104" {
+		t.Fatalf("unexpected rules: %v", imported.rules)
+	}
+	if unhandled := imported.findings[1]; unhandled.Severity != "minor" || unhandled.Title != "Errors unhandled" {
+		t.Fatalf("unexpected finding: %+v", unhandled)
+	}
+
+	reasons := make([]string, 0, len(imported.outside))
+	for _, entry := range imported.outside {
+		reasons = append(reasons, entry["reason"].(string))
+	}
+	if !strings.Contains(reasons[0], "not changed") || !strings.Contains(reasons[1], "line 5") || !strings.Contains(reasons[2], "no file") {
+		t.Fatalf("unexpected reasons: %v", reasons)
+	}
+	if imported.outside[1]["severity"] != "major" {
+		t.Fatalf("expected level error to map to major, got %v", imported.outside[1]["severity"])
+	}
+}
+
+func TestImportSarifResults_CollapsesResultsWithOneFingerprint(t *testing.T) {
+	result := func(line int) sarif.Result {
+		return sarif.Result{
+			RuleID:  "G104",
+			Message: sarif.Message{Text: "Errors unhandled."},
+			Locations: []sarif.Location{{PhysicalLocation: &sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: "src/main.go"},
+				Region:           &sarif.Region{StartLine: line},
+			}}},
+		}
+	}
+	log := sarif.Log{Version: sarif.Version, Runs: []sarif.Run{{Tool: sarif.Tool{Driver: sarif.Driver{Name: "gosec"}}, Results: []sarif.Result{result(7), result(9)}}}}
+
+	imported := importSarifResults(log, "", []string{"/src/main.go"}, func(string, commentRange) error { return nil })
+	if len(imported.findings) != 1 || imported.findings[0].Line != 7 {
+		t.Fatalf("expected one finding on line 7, got %+v", imported.findings)
+	}
+	if len(imported.collapsed) != 1 || imported.collapsed[0]["line"] != 9 || imported.collapsed[0]["duplicateOfLine"] != 7 || len(imported.outside) != 0 {
+		t.Fatalf("expected line 9 to be collapsed into line 7, got %v (outside %v)", imported.collapsed, imported.outside)
+	}
+	if problems := ValidateReviewDocument(ReviewDocument{Findings: imported.findings}); len(problems) != 0 {
+		t.Fatalf("expected the imported findings to form a valid document, got %v", problems)
+	}
+}
+
+func TestImportSarifResults_ResolvesURIBaseIDs(t *testing.T) {
+	result := func(uri string) sarif.Result {
+		return sarif.Result{
+			RuleID:  "G104",
+			Message: sarif.Message{Text: "Errors unhandled in " + uri},
+			Locations: []sarif.Location{{PhysicalLocation: &sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: uri, URIBaseID: "SRCROOT"},
+				Region:           &sarif.Region{StartLine: 3},
+			}}},
+		}
+	}
+	run := sarif.Run{
+		Tool:               sarif.Tool{Driver: sarif.Driver{Name: "gosec"}},
+		OriginalURIBaseIDs: map[string]sarif.ArtifactLocation{"SRCROOT": {URI: "file:///agent/_work/1/s/"}},
+		Results:            []sarif.Result{result("src/main.go"), result("vendor/src/main.go")},
+	}
+	log := sarif.Log{Version: sarif.Version, Runs: []sarif.Run{run}}
+
+	imported := importSarifResults(log, "/agent/_work/1/s", []string{"/src/main.go"}, func(string, commentRange) error { return nil })
+	if len(imported.findings) != 1 || imported.findings[0].File != "/src/main.go" {
+		t.Fatalf("expected the resolved URI to map to /src/main.go, got %+v", imported.findings)
+	}
+	if len(imported.outside) != 1 || imported.outside[0]["uri"] != "/agent/_work/1/s/vendor/src/main.go" {
+		t.Fatalf("expected the vendored copy not to match by suffix, got %v", imported.outside)
+	}
+}
+
+func TestSarifFindingFingerprintIgnoresLineAndWording(t *testing.T) {
+	log, err := sarif.Parse([]byte(importSarifLog))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := log.Runs[0]
+	first := sarifFinding(run, run.Results[0])
+	first.File = "/src/config.go"
+
+	moved := run.Results[0]
+	moved.Message.Text = "Potential hardcoded credentials (reworded)"
+	moved.Locations[0].PhysicalLocation.Region.StartLine = 30
+	second := sarifFinding(run, moved)
+	second.File = "/src/config.go"
+	if FindingFingerprint(first) != FindingFingerprint(second) {
+		t.Fatal("expected the partial fingerprint to keep the thread fingerprint stable")
+	}
+
+	other := sarifFinding(run, run.Results[1])
+	other.File = "/src/config.go"
+	if FindingFingerprint(first) == FindingFingerprint(other) {
+		t.Fatal("expected different rules to have different fingerprints")
+	}
+}
+
+func TestMapSarifPath(t *testing.T) {
+	changed := []string{"/src/app.go", "/lib/src/app.go", "/README.md"}
+	tests := []struct {
+		uri, root, want string
+		ok              bool
+	}{
+		{uri: "src/app.go", want: "/src/app.go", ok: true},
+		{uri: "./README.md", want: "/README.md", ok: true},
+		{uri: "file:///home/agent/_work/1/s/lib/src/app.go", want: "/lib/src/app.go", ok: true},
+		{uri: "file:///home/agent/_work/1/s/vendor/lib/src/app.go", root: "/home/agent/_work/1/s", ok: false},
+		{uri: "/home/agent/_work/1/s/lib/src/app.go", ok: false},
+		{uri: "/home/agent/_work/1/s/lib/src/app.go", root: "/home/agent/_work/1/s", want: "/lib/src/app.go", ok: true},
+		{uri: "vendor/lib/src/app.go", ok: false},
+		{uri: "https://example.com/src/app.go", ok: false},
+		{uri: "/C:/agent/_work/1/s/src/app.go", root: `C:\agent\_work\1\s`, want: "/src/app.go", ok: true},
+		{uri: "/C:/agent/_work/1/s/src/app.go", root: "/c:/Agent/_work/1/s/", want: "/src/app.go", ok: true},
+		{uri: "app.go", ok: false},
+		{uri: "src/other.go", ok: false},
+	}
+	for _, testCase := range tests {
+		got, ok := mapSarifPath(testCase.uri, testCase.root, changed)
+		if got != testCase.want || ok != testCase.ok {
+			t.Errorf("mapSarifPath(%q, %q) = %q, %v; want %q, %v", testCase.uri, testCase.root, got, ok, testCase.want, testCase.ok)
+		}
+	}
+}
+
+func TestOutsideDiffSummaryKey(t *testing.T) {
+	log := sarif.Log{Runs: []sarif.Run{
+		{Tool: sarif.Tool{Driver: sarif.Driver{Name: "Semgrep"}}},
+		{Tool: sarif.Tool{Driver: sarif.Driver{Name: "gosec"}}},
+		{Tool: sarif.Tool{Driver: sarif.Driver{Name: "semgrep"}}},
+	}}
+	if got, want := outsideDiffSummaryKey(log), "gosec,semgrep|sarif-outside-diff"; got != want {
+		t.Fatalf("outsideDiffSummaryKey = %q, want %q", got, want)
+	}
+
+	first := ReviewDocument{Summary: summarizeOutsideDiff([]map[string]any{{"tool": "gosec", "ruleId": "G104", "file": "/a.go", "line": 5}}), SummaryKey: outsideDiffSummaryKey(log)}
+	second := ReviewDocument{Summary: summarizeOutsideDiff([]map[string]any{{"tool": "gosec", "ruleId": "G104", "file": "/a.go", "line": 9}}), SummaryKey: outsideDiffSummaryKey(log)}
+	if first.Summary == second.Summary || SummaryFingerprint(first) != SummaryFingerprint(second) {
+		t.Fatal("expected differing summaries to share the keyed fingerprint")
+	}
+}
+
+func TestSummarizeOutsideDiff(t *testing.T) {
+	outside := []map[string]any{
+		{"tool": "gosec", "ruleId": "G104", "severity": "minor", "message": "Errors unhandled.", "file": "/src/main.go", "line": 5},
+		{"tool": "gosec", "ruleId": "G104", "severity": "minor", "message": "No location."},
+	}
+	summary := summarizeOutsideDiff(outside)
+	if !strings.Contains(summary, "(2)") || !strings.Contains(summary, "- `/src/main.go:5` **gosec** G104 (minor): Errors unhandled.") {
+		t.Fatalf("unexpected summary:\n%s", summary)
+	}
+	if !strings.Contains(summary, "\n- **gosec** G104 (minor): No location.") {
+		t.Fatalf("expected entries without a location to omit it:\n%s", summary)
+	}
+
+	many := make([]map[string]any, maxOutsideDiffSummaries+3)
+	for index := range many {
+		many[index] = map[string]any{"tool": "t", "ruleId": "r", "severity": "minor", "message": "m"}
+	}
+	if summary := summarizeOutsideDiff(many); !strings.Contains(summary, "… and 3 more") {
+		t.Fatalf("expected the summary to be capped:\n%s", summary)
+	}
+}
+
+func TestNormalizeOutsideDiffPolicy(t *testing.T) {
+	for input, want := range map[string]string{"": OutsideDiffSummarize, "-": OutsideDiffSummarize, "Drop": OutsideDiffDrop, "summarize": OutsideDiffSummarize} {
+		if got, err := NormalizeOutsideDiffPolicy(input); err != nil || got != want {
+			t.Errorf("NormalizeOutsideDiffPolicy(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := NormalizeOutsideDiffPolicy("ignore"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
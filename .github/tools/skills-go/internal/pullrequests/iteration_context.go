@@ -0,0 +1,83 @@
+package pullrequests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/iterations"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+type iterationContext struct {
+	ID           string
+	SourceCommit string
+	BaseCommit   string
+}
+
+func resolveIterationContext(organization, project, repositoryID, pullRequestID, iterationID string) (iterationContext, error) {
+	response, err := iterations.List(organization, project, repositoryID, pullRequestID)
+	if err != nil {
+		return iterationContext{}, err
+	}
+	return selectIterationContext(response, strings.TrimSpace(iterationID), pullRequestID)
+}
+
+func selectIterationContext(response map[string]any, iterationID, pullRequestID string) (iterationContext, error) {
+	rawIterations, _ := response["value"].([]any)
+	var selected map[string]any
+	selectedID := 0
+	for _, raw := range rawIterations {
+		item, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		id := toBundleInt(item["id"])
+		if id <= 0 {
+			continue
+		}
+		if iterationID != "" {
+			if strconv.Itoa(id) == iterationID {
+				selected = item
+				selectedID = id
+				break
+			}
+			continue
+		}
+		if id > selectedID {
+			selected = item
+			selectedID = id
+		}
+	}
+	if selected == nil {
+		if iterationID != "" {
+			return iterationContext{}, fmt.Errorf("iteration %s not found for pull request %s", iterationID, pullRequestID)
+		}
+		return iterationContext{}, fmt.Errorf("no iterations found for pull request %s", pullRequestID)
+	}
+
+	baseCommit := commitIDOf(selected["commonRefCommit"])
+	if baseCommit == "" {
+		baseCommit = commitIDOf(selected["targetRefCommit"])
+	}
+	return iterationContext{
+		ID:           strconv.Itoa(selectedID),
+		SourceCommit: commitIDOf(selected["sourceRefCommit"]),
+		BaseCommit:   baseCommit,
+	}, nil
+}
+
+func commitIDOf(value any) string {
+	commit, _ := value.(map[string]any)
+	return shared.TrimmedString(commit["commitId"])
+}
+
+func findChangedFile(changes map[string]any, pullRequestID, iterationID, path string) (map[string]any, bool) {
+	projected := ProjectChangedFiles(changes, pullRequestID, iterationID)
+	for _, fileEntry := range asMapSlice(projected["files"]) {
+		if shared.TrimmedString(fileEntry["path"]) == path {
+			return fileEntry, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,277 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	PendingAwaitingMe        = "awaiting-me"
+	PendingAwaitingAuthor    = "awaiting-author"
+	PendingNeedsVerification = "resolved-by-author-needs-verification"
+
+	pendingPullRequestPageSize = 100
+	maxParallelThreadRequests  = 6
+)
+
+// pendingOrder lists classifications in the order results are reported: what needs me first.
+var pendingOrder = []string{PendingAwaitingMe, PendingNeedsVerification, PendingAwaitingAuthor}
+
+// PendingThreadsOptions selects the threads to classify. Without PullRequestID every active pull
+// request in Project (optionally only in RepositoryID) that has the authenticated identity as a
+// reviewer is scanned.
+type PendingThreadsOptions struct {
+	Organization   string
+	Project        string
+	RepositoryID   string
+	PullRequestID  string
+	Since          time.Time
+	Classification string
+}
+
+// NormalizePendingClassification validates a classification filter; "" and "-" keep all.
+func NormalizePendingClassification(value string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	switch normalized {
+	case "", "-":
+		return "", nil
+	case PendingAwaitingMe, PendingAwaitingAuthor, PendingNeedsVerification:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("classification must be one of: %s", strings.Join(pendingOrder, ", "))
+	}
+}
+
+type pullRequestRef struct {
+	id           string
+	repositoryID string
+	title        string
+}
+
+// GetMyPendingThreads classifies the threads the authenticated identity took part in as awaiting-me
+// (someone else commented last on an open thread), awaiting-author (I commented last) or
+// resolved-by-author-needs-verification (the thread was marked fixed, won't fix or by design).
+// Closed threads are treated as settled and left out.
+func GetMyPendingThreads(options PendingThreadsOptions) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	project := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
+	if project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	if prID != "" && repo == "" {
+		return nil, fmt.Errorf("repositoryId is required when pullRequestId is set")
+	}
+	classification, err := NormalizePendingClassification(options.Classification)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := client.GetAuthenticatedUserID()
+	if err != nil {
+		return nil, err
+	}
+	classifier, err := NewThreadClassifier(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequests := []pullRequestRef{{id: prID, repositoryID: repo}}
+	if prID == "" {
+		pullRequests, err = listReviewerPullRequests(client, project, repo, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	responses, fetchErrors := fetchPullRequestThreads(client, project, pullRequests)
+
+	counts := map[string]int{}
+	for _, name := range pendingOrder {
+		counts[name] = 0
+	}
+	threads := make([]map[string]any, 0)
+	failures := make([]map[string]any, 0)
+	for index, pullRequest := range pullRequests {
+		if fetchErrors[index] != nil {
+			failures = append(failures, map[string]any{"pullRequestId": pullRequest.id, "error": fetchErrors[index].Error()})
+			continue
+		}
+		rawThreads, _ := responses[index]["value"].([]any)
+		for _, raw := range rawThreads {
+			thread, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if !options.Since.IsZero() && !threadTimeAtOrAfter(thread["lastUpdatedDate"], options.Since) {
+				continue
+			}
+			threadClass := classifier.Classify(thread)
+			class, ok := classifyMyThread(thread, threadClass, userID)
+			if !ok {
+				continue
+			}
+			counts[class]++
+			if classification != "" && class != classification {
+				continue
+			}
+			entry := compactThread(thread, threadClass)
+			entry["classification"] = class
+			entry["pullRequestId"] = toBundleInt(pullRequest.id)
+			entry["repositoryId"] = pullRequest.repositoryID
+			if pullRequest.title != "" {
+				entry["pullRequestTitle"] = pullRequest.title
+			}
+			threads = append(threads, entry)
+		}
+	}
+	sortPendingThreads(threads)
+
+	output := map[string]any{
+		"userId":           userID,
+		"project":          project,
+		"pullRequestCount": len(pullRequests),
+		"counts":           counts,
+		"count":            len(threads),
+		"threads":          threads,
+	}
+	if repo != "" {
+		output["repositoryId"] = repo
+	}
+	if len(failures) > 0 {
+		output["errors"] = failures
+	}
+	return output, nil
+}
+
+// classifyMyThread reports how a thread userID commented on is waiting; threadClass is the thread's
+// ThreadClassifier class. It returns false for threads the user never commented on, system and bot
+// threads, and closed threads.
+func classifyMyThread(thread map[string]any, threadClass, userID string) (string, bool) {
+	if deleted, _ := thread["isDeleted"].(bool); deleted {
+		return "", false
+	}
+	if threadClass == ThreadClassSystem || threadClass == ThreadClassBot {
+		return "", false
+	}
+	comments := humanComments(thread)
+	participated := false
+	for _, comment := range comments {
+		if identityMatches(comment["author"], userID) {
+			participated = true
+			break
+		}
+	}
+	if !participated {
+		return "", false
+	}
+
+	switch strings.ToLower(shared.TrimmedString(thread["status"])) {
+	case "closed":
+		return "", false
+	case "fixed", "wontfix", "bydesign":
+		return PendingNeedsVerification, true
+	}
+	if identityMatches(comments[len(comments)-1]["author"], userID) {
+		return PendingAwaitingAuthor, true
+	}
+	return PendingAwaitingMe, true
+}
+
+func sortPendingThreads(threads []map[string]any) {
+	rank := map[string]int{}
+	for index, name := range pendingOrder {
+		rank[name] = index
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		left, right := threads[i], threads[j]
+		if leftRank, rightRank := rank[shared.TrimmedString(left["classification"])], rank[shared.TrimmedString(right["classification"])]; leftRank != rightRank {
+			return leftRank < rightRank
+		}
+		if left["pullRequestId"] != right["pullRequestId"] {
+			return toBundleInt(left["pullRequestId"]) < toBundleInt(right["pullRequestId"])
+		}
+		return toBundleInt(left["id"]) < toBundleInt(right["id"])
+	})
+}
+
+// listReviewerPullRequests lists the active pull requests that have userID as a reviewer.
+func listReviewerPullRequests(client *ado.Client, project, repositoryID, userID string) ([]pullRequestRef, error) {
+	criteria := url.Values{}
+	criteria.Set("searchCriteria.reviewerId", userID)
+	criteria.Set("searchCriteria.status", "active")
+	return listPullRequests(client, project, repositoryID, criteria)
+}
+
+// listPullRequests pages through the pull requests of a project, or of one repository when
+// repositoryID is set, that match the searchCriteria in criteria.
+func listPullRequests(client *ado.Client, project, repositoryID string, criteria url.Values) ([]pullRequestRef, error) {
+	baseURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/pullrequests", client.EncodedOrg, url.PathEscape(project))
+	if repositoryID != "" {
+		baseURL = fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullrequests", client.EncodedOrg, url.PathEscape(project), url.PathEscape(repositoryID))
+	}
+
+	pullRequests := make([]pullRequestRef, 0)
+	for skip := 0; ; skip += pendingPullRequestPageSize {
+		query := url.Values{}
+		for key, values := range criteria {
+			query[key] = values
+		}
+		query.Set("$top", fmt.Sprintf("%d", pendingPullRequestPageSize))
+		query.Set("$skip", fmt.Sprintf("%d", skip))
+		query.Set("api-version", "7.2-preview")
+
+		var response struct {
+			Value []map[string]any `json:"value"`
+		}
+		if err := client.GetJSON(baseURL+"?"+query.Encode(), &response); err != nil {
+			return nil, err
+		}
+		for _, raw := range response.Value {
+			repository, _ := raw["repository"].(map[string]any)
+			pullRequests = append(pullRequests, pullRequestRef{
+				id:           fmt.Sprintf("%d", toBundleInt(raw["pullRequestId"])),
+				repositoryID: shared.TrimmedString(repository["id"]),
+				title:        shared.TrimmedString(raw["title"]),
+			})
+		}
+		if len(response.Value) < pendingPullRequestPageSize {
+			return pullRequests, nil
+		}
+	}
+}
+
+// fetchPullRequestThreads fetches the threads of every pull request, a few requests at a time. The
+// responses and errors are indexed like pullRequests.
+func fetchPullRequestThreads(client *ado.Client, project string, pullRequests []pullRequestRef) ([]map[string]any, []error) {
+	responses := make([]map[string]any, len(pullRequests))
+	fetchErrors := make([]error, len(pullRequests))
+	semaphore := make(chan struct{}, maxParallelThreadRequests)
+	var wg sync.WaitGroup
+	for index, pullRequest := range pullRequests {
+		index := index
+		pullRequest := pullRequest
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			responses[index], fetchErrors[index] = fetchThreads(client, project, pullRequest.repositoryID, pullRequest.id)
+		}()
+	}
+	wg.Wait()
+	return responses, fetchErrors
+}
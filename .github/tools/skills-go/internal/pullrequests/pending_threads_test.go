@@ -0,0 +1,90 @@
+package pullrequests
+
+import "testing"
+
+func pendingTestThread(id int, status string, authors ...string) map[string]any {
+	comments := make([]any, 0, len(authors))
+	for index, author := range authors {
+		comments = append(comments, map[string]any{"id": float64(index + 1), "content": "comment", "author": map[string]any{"id": author}})
+	}
+	return map[string]any{"id": float64(id), "status": status, "comments": comments}
+}
+
+func TestClassifyMyThread(t *testing.T) {
+	tests := []struct {
+		name   string
+		thread map[string]any
+		want   string
+		wantOK bool
+	}{
+		{name: "author replied last", thread: pendingTestThread(1, "active", "ME", "author"), want: PendingAwaitingMe, wantOK: true},
+		{name: "I replied last", thread: pendingTestThread(2, "active", "author", "me"), want: PendingAwaitingAuthor, wantOK: true},
+		{name: "pending status", thread: pendingTestThread(3, "pending", "me"), want: PendingAwaitingAuthor, wantOK: true},
+		{name: "marked fixed", thread: pendingTestThread(4, "fixed", "me"), want: PendingNeedsVerification, wantOK: true},
+		{name: "won't fix", thread: pendingTestThread(5, "wontFix", "me", "author"), want: PendingNeedsVerification, wantOK: true},
+		{name: "closed", thread: pendingTestThread(6, "closed", "me", "author"), wantOK: false},
+		{name: "not mine", thread: pendingTestThread(7, "active", "someone", "author"), wantOK: false},
+		{name: "system thread", thread: map[string]any{"id": float64(8), "properties": map[string]any{"CodeReviewThreadType": "VoteUpdate"}, "comments": []any{map[string]any{"author": map[string]any{"id": "me"}}}}, wantOK: false},
+		{name: "bot thread", thread: map[string]any{"id": float64(9), "status": "active", "comments": []any{
+			map[string]any{"id": float64(1), "author": map[string]any{"id": "sonar", "displayName": "SonarQube Scanner"}},
+			map[string]any{"id": float64(2), "author": map[string]any{"id": "me"}},
+		}}, wantOK: false},
+	}
+
+	classifier := ThreadClassifier{UserID: "me", BotIdentities: DefaultBotIdentities, PropertyRules: DefaultThreadPropertyRules}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, ok := classifyMyThread(testCase.thread, classifier.Classify(testCase.thread), "me")
+			if ok != testCase.wantOK || got != testCase.want {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", testCase.want, testCase.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestClassifyMyThread_IgnoresDeletedReplies(t *testing.T) {
+	thread := pendingTestThread(1, "active", "me")
+	thread["comments"] = append(thread["comments"].([]any), map[string]any{"id": float64(2), "isDeleted": true, "author": map[string]any{"id": "author"}})
+	if got, ok := classifyMyThread(thread, ThreadClassSelf, "me"); !ok || got != PendingAwaitingAuthor {
+		t.Fatalf("expected awaiting-author, got (%q, %v)", got, ok)
+	}
+}
+
+func TestSortPendingThreads(t *testing.T) {
+	threads := []map[string]any{
+		{"id": 4, "pullRequestId": 10, "classification": PendingAwaitingAuthor},
+		{"id": 9, "pullRequestId": 12, "classification": PendingAwaitingMe},
+		{"id": 2, "pullRequestId": 10, "classification": PendingNeedsVerification},
+		{"id": 3, "pullRequestId": 10, "classification": PendingAwaitingMe},
+	}
+	sortPendingThreads(threads)
+	want := []int{3, 9, 2, 4}
+	for index, thread := range threads {
+		if thread["id"] != want[index] {
+			t.Fatalf("unexpected order at %d: %#v", index, threads)
+		}
+	}
+}
+
+func TestNormalizePendingClassification(t *testing.T) {
+	if got, err := NormalizePendingClassification(" Awaiting-Me "); err != nil || got != PendingAwaitingMe {
+		t.Fatalf("unexpected result: %q, %v", got, err)
+	}
+	if got, err := NormalizePendingClassification("-"); err != nil || got != "" {
+		t.Fatalf("unexpected result: %q, %v", got, err)
+	}
+	if _, err := NormalizePendingClassification("later"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestGetMyPendingThreads_Validation(t *testing.T) {
+	t.Setenv("ADO_PAT_testorg", "token")
+
+	if _, err := GetMyPendingThreads(PendingThreadsOptions{Organization: "testorg"}); err == nil || err.Error() != "project is required" {
+		t.Fatalf("expected project validation error, got: %v", err)
+	}
+	if _, err := GetMyPendingThreads(PendingThreadsOptions{Organization: "testorg", Project: "proj", PullRequestID: "7"}); err == nil || err.Error() != "repositoryId is required when pullRequestId is set" {
+		t.Fatalf("expected repositoryId validation error, got: %v", err)
+	}
+}
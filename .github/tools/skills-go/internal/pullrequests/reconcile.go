@@ -0,0 +1,388 @@
+package pullrequests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/iterations"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	AnchorUnchanged = "unchanged"
+	AnchorMoved     = "moved"
+	AnchorModified  = "modified"
+	AnchorDeleted   = "deleted"
+	AnchorError     = "error"
+)
+
+// ReconcileOptions selects the pull request and target iteration to reconcile threads against.
+// When Resolve is set, threads whose anchored lines were modified or deleted are marked fixed with
+// Reply (a default message when empty, no reply when "-"), limited to ThreadIDs when given.
+type ReconcileOptions struct {
+	Organization  string
+	Project       string
+	RepositoryID  string
+	PullRequestID string
+	IterationID   string
+	Resolve       bool
+	Reply         string
+	ThreadIDs     []int
+}
+
+// ReconcileThreads maps each active thread's anchor from the iteration it was posted on to the
+// target iteration (the latest by default) and reports whether the anchored lines are unchanged,
+// moved, modified or deleted. Files are followed across renames by changeTrackingId.
+func ReconcileThreads(options ReconcileOptions) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	project := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
+	if project == "" || repo == "" || prID == "" {
+		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
+	}
+
+	iterationsResponse, err := iterations.List(options.Organization, project, repo, prID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := selectIterationContext(iterationsResponse, strings.TrimSpace(options.IterationID), prID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := GetChanges(options.Organization, project, repo, prID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	changesByTrackingID := map[int]map[string]any{}
+	for _, entry := range asMapSlice(ProjectChangedFiles(changes, prID, target.ID)["files"]) {
+		changesByTrackingID[toBundleInt(entry["changeTrackingId"])] = entry
+	}
+
+	threadsResponse, err := fetchThreads(client, project, repo, prID)
+	if err != nil {
+		return nil, err
+	}
+	userID, userErr := client.GetAuthenticatedUserID()
+	classifier, err := NewThreadClassifier(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciler := &threadReconciler{
+		options:    options,
+		project:    project,
+		repo:       repo,
+		iterations: iterationsResponse,
+		target:     target,
+		changes:    changesByTrackingID,
+		contents:   map[string]contentResult{},
+	}
+
+	counts := map[string]int{AnchorUnchanged: 0, AnchorMoved: 0, AnchorModified: 0, AnchorDeleted: 0, AnchorError: 0}
+	results := make([]map[string]any, 0)
+	resolved := 0
+	rawThreads, _ := threadsResponse["value"].([]any)
+	for _, raw := range rawThreads {
+		thread, ok := raw.(map[string]any)
+		if !ok || !isReconcilableThread(thread, classifier) {
+			continue
+		}
+		result := reconciler.reconcile(thread)
+		counts[shared.TrimmedString(result["outcome"])]++
+		if options.Resolve && reconciler.shouldResolve(result) {
+			if reconciler.resolve(result) {
+				resolved++
+			}
+		}
+		results = append(results, result)
+	}
+
+	output := map[string]any{
+		"pullRequestId": toBundleInt(prID),
+		"iterationId":   toBundleInt(target.ID),
+		"counts":        counts,
+		"count":         len(results),
+		"resolved":      resolved,
+		"threads":       results,
+	}
+	if userErr != nil {
+		output["classificationWarning"] = "authenticated user could not be resolved, so threads you started are only reconciled when they classify as human: " + userErr.Error()
+	}
+	return output, nil
+}
+
+// isReconcilableThread keeps open human and self threads (see ThreadClassifier) anchored to lines
+// of the PR (right) side.
+func isReconcilableThread(thread map[string]any, classifier ThreadClassifier) bool {
+	if deleted, _ := thread["isDeleted"].(bool); deleted {
+		return false
+	}
+	switch strings.ToLower(shared.TrimmedString(thread["status"])) {
+	case "active", "pending":
+	default:
+		return false
+	}
+	switch classifier.Classify(thread) {
+	case ThreadClassSystem, ThreadClassBot:
+		return false
+	}
+	context, _ := thread["threadContext"].(map[string]any)
+	_, hasRight := context["rightFileStart"].(map[string]any)
+	return hasRight && shared.TrimmedString(context["filePath"]) != ""
+}
+
+type contentResult struct {
+	text string
+	err  error
+}
+
+type threadReconciler struct {
+	options    ReconcileOptions
+	project    string
+	repo       string
+	iterations map[string]any
+	target     iterationContext
+	changes    map[int]map[string]any
+	contents   map[string]contentResult
+}
+
+func (r *threadReconciler) reconcile(thread map[string]any) map[string]any {
+	context, _ := thread["threadContext"].(map[string]any)
+	filePath := shared.TrimmedString(context["filePath"])
+	start := lineOf(context["rightFileStart"])
+	end := lineOf(context["rightFileEnd"])
+	if end < start {
+		end = start
+	}
+
+	result := map[string]any{
+		"threadId":  toBundleInt(thread["id"]),
+		"filePath":  filePath,
+		"startLine": start,
+		"endLine":   end,
+	}
+	if comments := humanComments(thread); len(comments) > 0 {
+		result["comment"] = excerpt(shared.TrimmedString(comments[0]["content"]), compactExcerptRunes)
+	}
+	fail := func(err error) map[string]any {
+		result["outcome"] = AnchorError
+		result["error"] = err.Error()
+		return result
+	}
+
+	anchorID := threadIteration(thread)
+	if anchorID == 0 {
+		anchorID = iterationAt(r.iterations, shared.TrimmedString(thread["publishedDate"]))
+	}
+	result["anchorIterationId"] = anchorID
+	targetID, _ := strconv.Atoi(r.target.ID)
+	if anchorID >= targetID {
+		result["outcome"] = AnchorUnchanged
+		result["currentFilePath"], result["currentStartLine"], result["currentEndLine"] = filePath, start, end
+		return result
+	}
+	anchor, err := selectIterationContext(r.iterations, strconv.Itoa(anchorID), r.options.PullRequestID)
+	if err != nil {
+		return fail(err)
+	}
+
+	currentPath := filePath
+	trackingContext, _ := thread["pullRequestThreadContext"].(map[string]any)
+	if entry, ok := r.changes[toBundleInt(trackingContext["changeTrackingId"])]; ok && entry["changeTrackingId"] != nil {
+		if strings.Contains(strings.ToLower(fmt.Sprint(entry["changeType"])), "delete") {
+			result["outcome"] = AnchorDeleted
+			result["reason"] = "file deleted"
+			return result
+		}
+		currentPath = shared.TrimmedString(entry["path"])
+	}
+
+	oldContent, err := r.content(filePath, anchor.SourceCommit)
+	if err != nil {
+		return fail(err)
+	}
+	newContent, err := r.content(currentPath, r.target.SourceCommit)
+	if err != nil {
+		return fail(err)
+	}
+
+	outcome, newStart, newEnd, err := mapAnchor(oldContent, newContent, start, end)
+	if err != nil {
+		return fail(err)
+	}
+	if outcome == AnchorUnchanged && currentPath != filePath {
+		outcome = AnchorMoved
+	}
+	result["outcome"] = outcome
+	if outcome != AnchorDeleted {
+		result["currentFilePath"] = currentPath
+		if newStart > 0 {
+			result["currentStartLine"], result["currentEndLine"] = newStart, newEnd
+		}
+	}
+	return result
+}
+
+func (r *threadReconciler) content(path, commit string) (string, error) {
+	key := commit + ":" + path
+	if cached, ok := r.contents[key]; ok {
+		return cached.text, cached.err
+	}
+	response, err := files.GetContentLimited(r.options.Organization, r.project, r.repo, path, commit, "commit", 0)
+	result := contentResult{err: err}
+	if err == nil {
+		if isBinary, _ := response["isBinary"].(bool); isBinary {
+			result.err = fmt.Errorf("%s is a binary file", path)
+		}
+		result.text, _ = response["content"].(string)
+	}
+	r.contents[key] = result
+	return result.text, result.err
+}
+
+func (r *threadReconciler) shouldResolve(result map[string]any) bool {
+	switch shared.TrimmedString(result["outcome"]) {
+	case AnchorModified, AnchorDeleted:
+	default:
+		return false
+	}
+	if len(r.options.ThreadIDs) == 0 {
+		return true
+	}
+	threadID := toBundleInt(result["threadId"])
+	for _, id := range r.options.ThreadIDs {
+		if id == threadID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *threadReconciler) resolve(result map[string]any) bool {
+	reply := r.options.Reply
+	if strings.TrimSpace(reply) == "" {
+		reply = fmt.Sprintf("The commented lines were %s in iteration %s; marking this thread as fixed.", result["outcome"], r.target.ID)
+	}
+	threadID := strconv.Itoa(toBundleInt(result["threadId"]))
+	if _, err := UpdateThread(r.options.Organization, r.project, r.repo, r.options.PullRequestID, threadID, reply, "fixed", 0); err != nil {
+		result["resolveError"] = err.Error()
+		return false
+	}
+	result["resolved"] = true
+	return true
+}
+
+func lineOf(raw any) int {
+	position, _ := raw.(map[string]any)
+	line, _ := position["line"].(float64)
+	return int(line)
+}
+
+// iterationAt returns the latest iteration created at or before published, or 1 when unknown.
+func iterationAt(iterationsResponse map[string]any, published string) int {
+	publishedAt, err := time.Parse(time.RFC3339, published)
+	if err != nil {
+		return 1
+	}
+	selected := 1
+	rawIterations, _ := iterationsResponse["value"].([]any)
+	for _, raw := range rawIterations {
+		item, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, shared.TrimmedString(item["createdDate"]))
+		if err != nil || created.After(publishedAt) {
+			continue
+		}
+		if id := toBundleInt(item["id"]); id > selected {
+			selected = id
+		}
+	}
+	return selected
+}
+
+// mapAnchor follows the inclusive line range start-end from oldContent to newContent. The anchor is
+// unchanged or moved when every line survives with nothing inserted between them, deleted when all
+// lines were removed without replacement, and modified otherwise. For surviving anchors the new
+// range spans the first and last surviving lines. When the versions differ too much for an exact
+// diff, the anchor cannot be followed and mapAnchor returns an error instead of a guess.
+func mapAnchor(oldContent, newContent string, start, end int) (string, int, int, error) {
+	oldLines := linediff.SplitLines(oldContent)
+	newLines := linediff.SplitLines(newContent)
+	if start < 1 || start > len(oldLines) {
+		return AnchorDeleted, 0, 0, nil
+	}
+	if end > len(oldLines) {
+		end = len(oldLines)
+	}
+
+	context := len(oldLines)
+	if len(newLines) > context {
+		context = len(newLines)
+	}
+	oldToNew := make(map[int]int, len(oldLines))
+	insertedAfter := map[int]bool{}
+	hunks, exact := linediff.ComputeChecked(oldLines, newLines, context)
+	if !exact {
+		return "", 0, 0, fmt.Errorf("the file changed too much between iterations to follow lines %s", formatLineRange(commentRange{StartLine: start, EndLine: end}))
+	}
+	if len(hunks) == 0 {
+		return AnchorUnchanged, start, end, nil
+	}
+	lastOld := 0
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case linediff.KindContext:
+				oldToNew[line.OldLine] = line.NewLine
+				lastOld = line.OldLine
+			case linediff.KindDeleted:
+				lastOld = line.OldLine
+			case linediff.KindAdded:
+				insertedAfter[lastOld] = true
+			}
+		}
+	}
+
+	newStart, newEnd, kept := 0, 0, 0
+	insertedInside := false
+	for line := start; line <= end; line++ {
+		if mapped, ok := oldToNew[line]; ok {
+			if newStart == 0 {
+				newStart = mapped
+			}
+			newEnd = mapped
+			kept++
+		}
+		if line < end && insertedAfter[line] {
+			insertedInside = true
+		}
+	}
+
+	switch {
+	case kept == 0:
+		for line := start - 1; line <= end; line++ {
+			if insertedAfter[line] {
+				return AnchorModified, 0, 0, nil
+			}
+		}
+		return AnchorDeleted, 0, 0, nil
+	case kept < end-start+1 || insertedInside:
+		return AnchorModified, newStart, newEnd, nil
+	case newStart != start:
+		return AnchorMoved, newStart, newEnd, nil
+	default:
+		return AnchorUnchanged, newStart, newEnd, nil
+	}
+}
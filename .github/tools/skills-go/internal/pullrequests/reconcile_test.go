@@ -0,0 +1,128 @@
+package pullrequests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const reconcileOld = "package main\n\nfunc a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n"
+
+func TestMapAnchor(t *testing.T) {
+	tests := []struct {
+		name       string
+		newContent string
+		start, end int
+		want       string
+		wantStart  int
+		wantEnd    int
+	}{
+		{name: "identical", newContent: reconcileOld, start: 3, end: 5, want: AnchorUnchanged, wantStart: 3, wantEnd: 5},
+		{name: "change elsewhere", newContent: "package main\n\nfunc a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 3\n}\n", start: 3, end: 5, want: AnchorUnchanged, wantStart: 3, wantEnd: 5},
+		{name: "shifted down", newContent: "package main\n\nimport \"fmt\"\n\nfunc a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n", start: 3, end: 5, want: AnchorMoved, wantStart: 5, wantEnd: 7},
+		{name: "line edited", newContent: "package main\n\nfunc a() {\n\treturn 10\n}\n\nfunc b() {\n\treturn 2\n}\n", start: 3, end: 5, want: AnchorModified, wantStart: 3, wantEnd: 5},
+		{name: "line inserted inside", newContent: "package main\n\nfunc a() {\n\tlog()\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n", start: 3, end: 5, want: AnchorModified, wantStart: 3, wantEnd: 6},
+		{name: "single line replaced", newContent: "package main\n\nfunc a() {\n\treturn 10\n}\n\nfunc b() {\n\treturn 2\n}\n", start: 4, end: 4, want: AnchorModified},
+		{name: "block removed", newContent: "package main\n\nfunc b() {\n\treturn 2\n}\n", start: 3, end: 5, want: AnchorDeleted},
+		{name: "past end of file", newContent: reconcileOld, start: 40, end: 40, want: AnchorDeleted},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, start, end, err := mapAnchor(reconcileOld, testCase.newContent, testCase.start, testCase.end)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != testCase.want || start != testCase.wantStart || end != testCase.wantEnd {
+				t.Fatalf("expected (%s, %d, %d), got (%s, %d, %d)", testCase.want, testCase.wantStart, testCase.wantEnd, got, start, end)
+			}
+		})
+	}
+}
+
+func TestMapAnchor_TooManyEditsIsAnError(t *testing.T) {
+	var oldContent, newContent strings.Builder
+	for i := 0; i < 1100; i++ {
+		fmt.Fprintf(&oldContent, "old %d\n", i)
+		fmt.Fprintf(&newContent, "new %d\n", i)
+		if i == 10 {
+			oldContent.WriteString("anchored\n")
+			newContent.WriteString("anchored\n")
+		}
+	}
+	outcome, start, end, err := mapAnchor(oldContent.String(), newContent.String(), 12, 12)
+	if err == nil || outcome != "" || start != 0 || end != 0 {
+		t.Fatalf("expected an error instead of a guessed outcome, got (%q, %d, %d, %v)", outcome, start, end, err)
+	}
+
+	reconciler := &threadReconciler{}
+	if reconciler.shouldResolve(map[string]any{"outcome": AnchorError, "threadId": 1}) {
+		t.Fatal("expected threads that could not be mapped never to be resolved")
+	}
+}
+
+func TestIterationAt(t *testing.T) {
+	response := map[string]any{"value": []any{
+		map[string]any{"id": float64(1), "createdDate": "2024-05-01T10:00:00Z"},
+		map[string]any{"id": float64(2), "createdDate": "2024-05-02T10:00:00.5Z"},
+		map[string]any{"id": float64(3), "createdDate": "2024-05-04T10:00:00Z"},
+	}}
+	if got := iterationAt(response, "2024-05-03T08:00:00Z"); got != 2 {
+		t.Fatalf("expected iteration 2, got %d", got)
+	}
+	if got := iterationAt(response, "2024-04-01T08:00:00Z"); got != 1 {
+		t.Fatalf("expected iteration 1 before the first push, got %d", got)
+	}
+	if got := iterationAt(response, ""); got != 1 {
+		t.Fatalf("expected iteration 1 for an unknown date, got %d", got)
+	}
+}
+
+func TestIsReconcilableThread(t *testing.T) {
+	fileContext := map[string]any{"filePath": "/a.go", "rightFileStart": map[string]any{"line": float64(3)}}
+	fingerprinted := map[string]any{FingerprintProperty: "v1:abc"}
+	tests := []struct {
+		name   string
+		thread map[string]any
+		want   bool
+	}{
+		{name: "active file thread", thread: map[string]any{"status": "active", "threadContext": fileContext}, want: true},
+		{name: "pending file thread", thread: map[string]any{"status": "pending", "threadContext": fileContext}, want: true},
+		{name: "already fixed", thread: map[string]any{"status": "fixed", "threadContext": fileContext}},
+		{name: "general thread", thread: map[string]any{"status": "active"}},
+		{name: "left side only", thread: map[string]any{"status": "active", "threadContext": map[string]any{"filePath": "/a.go", "leftFileStart": map[string]any{"line": float64(3)}}}},
+		{name: "deleted", thread: map[string]any{"status": "active", "isDeleted": true, "threadContext": fileContext}},
+		{name: "own finding", thread: map[string]any{"status": "active", "threadContext": fileContext, "properties": fingerprinted,
+			"comments": []any{map[string]any{"author": map[string]any{"id": "me"}}}}, want: true},
+		{name: "bot finding", thread: map[string]any{"status": "active", "threadContext": fileContext, "properties": fingerprinted,
+			"comments": []any{map[string]any{"author": map[string]any{"id": "pipeline", "displayName": "Project Build Service (org)"}}}}},
+		{name: "system thread", thread: map[string]any{"status": "active", "threadContext": fileContext,
+			"properties": map[string]any{"CodeReviewThreadType": "VoteUpdate"}}},
+	}
+	classifier := ThreadClassifier{UserID: "me", BotIdentities: DefaultBotIdentities, PropertyRules: DefaultThreadPropertyRules}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := isReconcilableThread(testCase.thread, classifier); got != testCase.want {
+				t.Fatalf("expected %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestThreadReconcilerShouldResolve(t *testing.T) {
+	all := &threadReconciler{}
+	if !all.shouldResolve(map[string]any{"threadId": 4, "outcome": AnchorModified}) {
+		t.Fatalf("expected modified anchor to be resolved")
+	}
+	if all.shouldResolve(map[string]any{"threadId": 4, "outcome": AnchorMoved}) {
+		t.Fatalf("expected moved anchor to stay open")
+	}
+
+	selected := &threadReconciler{options: ReconcileOptions{ThreadIDs: []int{7}}}
+	if selected.shouldResolve(map[string]any{"threadId": 4, "outcome": AnchorDeleted}) {
+		t.Fatalf("expected unselected thread to stay open")
+	}
+	if !selected.shouldResolve(map[string]any{"threadId": 7, "outcome": AnchorDeleted}) {
+		t.Fatalf("expected selected thread to be resolved")
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"strconv"
 	"strings"
 
-	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
 	"ado-reviewer/.github/tools/skills-go/internal/iterations"
 	"ado-reviewer/.github/tools/skills-go/internal/shared"
 )
@@ -31,6 +31,14 @@ type ReviewBundleOptions struct {
 	ThreadStatusFilter   string
 	ExcludeSystemThreads bool
 	IncludeLineMap       bool
+	ExcludeCategories    map[string]bool
+	TokenBudget          int
+	ChunkIndex           int
+	IncludeHunks         bool
+	HunkContextLines     int
+	MaxHunkFileBytes     int
+	MaxHunkTotalBytes    int
+	IncludeEnclosingBody bool
 }
 
 func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
@@ -46,6 +54,12 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 	if fileOffset < 0 {
 		return nil, fmt.Errorf("fileOffset must be >= 0")
 	}
+	if options.TokenBudget < 0 {
+		return nil, fmt.Errorf("tokenBudget must be >= 0")
+	}
+	if options.ChunkIndex < 0 {
+		return nil, fmt.Errorf("chunkIndex must be >= 0")
+	}
 	threadOffset := options.ThreadOffset
 	if threadOffset < 0 {
 		return nil, fmt.Errorf("threadOffset must be >= 0")
@@ -77,10 +91,33 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 	}
 	projected := ProjectChangedFiles(changes, prID, iterationID)
 	allFiles := asMapSlice(projected["files"])
+	contents := newBundleContents(org, project, repo, targetBranch, sourceBranch)
+	reviewFiles, excludedFiles := classifyBundleFiles(allFiles, options.ExcludeCategories, contents)
+
+	var plan []reviewChunk
+	var filesSlice []map[string]any
+	filesHasMore := false
+	if options.TokenBudget > 0 {
+		contents.load(reviewFiles)
+		for _, fileEntry := range reviewFiles {
+			path := shared.TrimmedString(fileEntry["path"])
+			fileEntry["estimatedTokens"] = contents.estimateTokens(fileEntry, path)
+		}
+		plan = planReviewChunks(reviewFiles, options.TokenBudget)
+		if options.ChunkIndex >= len(plan) && len(plan) > 0 {
+			return nil, fmt.Errorf("chunkIndex %d is out of range (chunk count: %d)", options.ChunkIndex, len(plan))
+		}
+		filesSlice = []map[string]any{}
+		if len(plan) > 0 {
+			filesSlice = chunkFiles(reviewFiles, plan[options.ChunkIndex])
+			filesHasMore = options.ChunkIndex < len(plan)-1
+		}
+	} else {
+		filesSlice, filesHasMore = paginateMaps(reviewFiles, fileOffset, fileLimit)
+	}
 
-	filesSlice, filesHasMore := paginateMaps(allFiles, fileOffset, fileLimit)
 	if options.IncludeLineMap {
-		contentPaths := make([]string, 0, len(filesSlice))
+		contents.load(filesSlice)
 		for _, fileEntry := range filesSlice {
 			path := shared.TrimmedString(fileEntry["path"])
 			if path == "" {
@@ -93,37 +130,46 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 				fileEntry["prExists"] = false
 				continue
 			}
-			contentPaths = append(contentPaths, path)
-		}
-
-		baseByPath := map[string]string{}
-		prByPath := map[string]string{}
-		if len(contentPaths) > 0 {
-			basePayload, _ := files.GetMultiple(org, project, repo, targetBranch, "branch", contentPaths)
-			prPayload, _ := files.GetMultiple(org, project, repo, sourceBranch, "branch", contentPaths)
-			baseByPath = files.ContentByPath(basePayload)
-			prByPath = files.ContentByPath(prPayload)
-		}
-
-		for _, fileEntry := range filesSlice {
-			path := shared.TrimmedString(fileEntry["path"])
-			if path == "" {
-				continue
-			}
-			isFolder, _ := fileEntry["isFolder"].(bool)
-			if isFolder {
+			if isBinary, _ := fileEntry["binary"].(bool); isBinary {
+				fileEntry["lineMap"] = emptyBundleLineMap()
+				fileEntry["lineMapSkipped"] = fileclass.CategoryBinary
 				continue
 			}
 
-			baseContent, baseExists := baseByPath[path]
-			prContent, prExists := prByPath[path]
+			baseContent, baseExists := contents.base[path]
+			prContent, prExists := contents.pr[path]
 
 			fileEntry["baseExists"] = baseExists
 			fileEntry["prExists"] = prExists
+
+			sniffed := prContent
+			if !prExists {
+				sniffed = baseContent
+			}
+			classification := fileclass.Classify(path, sniffed)
+			classification.Apply(fileEntry)
+			if reason := classification.SkipReason(); reason != "" {
+				fileEntry["lineMap"] = emptyBundleLineMap()
+				fileEntry["lineMapSkipped"] = reason
+				continue
+			}
 			fileEntry["lineMap"] = buildBundleSimpleLineMap(baseContent, prContent)
 		}
 	}
 
+	var hunkLimits bundleHunkLimits
+	var hunkUsage bundleHunkUsage
+	if options.IncludeHunks {
+		hunkLimits = bundleHunkLimits{
+			Context:              options.HunkContextLines,
+			MaxFileBytes:         options.MaxHunkFileBytes,
+			MaxTotalBytes:        options.MaxHunkTotalBytes,
+			IncludeEnclosingBody: options.IncludeEnclosingBody,
+		}.normalized()
+		contents.load(filesSlice)
+		hunkUsage = attachBundleHunks(filesSlice, contents, hunkLimits)
+	}
+
 	threadsResponse, err := GetThreads(org, project, repo, prID, strings.TrimSpace(options.ThreadStatusFilter), options.ExcludeSystemThreads)
 	if err != nil {
 		return nil, err
@@ -132,13 +178,15 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 	threadsSlice, threadsHasMore := paginateMaps(allThreads, threadOffset, threadLimit)
 
 	warnings := make([]string, 0)
-	if options.FileLimit > maxBundleFileLimit {
+	if options.FileLimit > maxBundleFileLimit && options.TokenBudget == 0 {
 		warnings = append(warnings, fmt.Sprintf("fileLimit capped to %d", maxBundleFileLimit))
 	}
 	if options.ThreadLimit > maxBundleThreadLimit {
 		warnings = append(warnings, fmt.Sprintf("threadLimit capped to %d", maxBundleThreadLimit))
 	}
 
+	filesPage := buildBundleFilesPage(filesSlice, len(reviewFiles), filesHasMore, fileOffset, fileLimit, plan, options)
+
 	bundle := map[string]any{
 		"organization":  org,
 		"project":       project,
@@ -149,19 +197,15 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 		"targetBranch":  targetBranch,
 		"summary": map[string]any{
 			"totalChangedFiles":  len(allFiles),
+			"excludedFiles":      excludedFiles,
+			"excludedCategories": sortedCategories(options.ExcludeCategories),
 			"totalThreads":       len(allThreads),
 			"filePageReturned":   len(filesSlice),
 			"threadPageReturned": len(threadsSlice),
 			"filesHasMore":       filesHasMore,
 			"threadsHasMore":     threadsHasMore,
 		},
-		"files": map[string]any{
-			"offset":  fileOffset,
-			"limit":   fileLimit,
-			"total":   len(allFiles),
-			"hasMore": filesHasMore,
-			"items":   filesSlice,
-		},
+		"files": filesPage,
 		"threads": map[string]any{
 			"offset":  threadOffset,
 			"limit":   threadLimit,
@@ -173,9 +217,28 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 		"warnings":    warnings,
 	}
 
-	if filesHasMore {
+	if options.TokenBudget > 0 {
+		bundle["chunkPlan"] = chunkPlanSummary(plan)
+		if filesHasMore {
+			bundle["nextChunkIndex"] = options.ChunkIndex + 1
+		}
+	} else if filesHasMore {
 		bundle["nextFileOffset"] = fileOffset + len(filesSlice)
 	}
+	if options.IncludeHunks {
+		bundle["hunks"] = map[string]any{
+			"contextLines":   hunkLimits.Context,
+			"maxFileBytes":   hunkLimits.MaxFileBytes,
+			"maxTotalBytes":  hunkLimits.MaxTotalBytes,
+			"usedBytes":      hunkUsage.UsedBytes,
+			"truncatedFiles": hunkUsage.TruncatedFiles,
+			"truncated":      hunkUsage.TruncatedFiles > 0,
+			"enclosingBody":  hunkLimits.IncludeEnclosingBody,
+		}
+	}
+	if len(contents.loaded) > 0 {
+		bundle["contentFetch"] = contents.stats.ToMap()
+	}
 	if threadsHasMore {
 		bundle["nextThreadOffset"] = threadOffset + len(threadsSlice)
 	}
@@ -183,6 +246,74 @@ func GetReviewBundle(options ReviewBundleOptions) (map[string]any, error) {
 	return bundle, nil
 }
 
+// buildBundleFilesPage describes the returned files. With a token budget the page is a chunk and
+// fileOffset/fileLimit are ignored, so the page carries chunk fields instead of offset and limit.
+func buildBundleFilesPage(items []map[string]any, total int, hasMore bool, offset, limit int, plan []reviewChunk, options ReviewBundleOptions) map[string]any {
+	page := map[string]any{
+		"total":   total,
+		"hasMore": hasMore,
+		"items":   items,
+	}
+	if options.TokenBudget > 0 {
+		page["chunkIndex"] = options.ChunkIndex
+		page["chunkCount"] = len(plan)
+		page["tokenBudget"] = options.TokenBudget
+		return page
+	}
+	page["offset"] = offset
+	page["limit"] = limit
+	return page
+}
+
+// classifyBundleFiles drops files in the excluded categories. Path rules are applied first; when an
+// excluded category can also be detected from content (generated headers, binary data, LFS
+// pointers), the remaining files are loaded and classified again from their PR version.
+func classifyBundleFiles(allFiles []map[string]any, exclude map[string]bool, contents *bundleContents) ([]map[string]any, int) {
+	candidates := make([]map[string]any, 0, len(allFiles))
+	excluded := 0
+	for _, fileEntry := range allFiles {
+		classification := fileclass.ClassifyPath(shared.TrimmedString(fileEntry["path"]))
+		classification.Apply(fileEntry)
+		if classification.MatchesAny(exclude) {
+			excluded++
+			continue
+		}
+		candidates = append(candidates, fileEntry)
+	}
+	if !exclude[fileclass.CategoryGenerated] && !exclude[fileclass.CategoryBinary] && !exclude[fileclass.CategoryLFSPointer] {
+		return candidates, excluded
+	}
+
+	contents.load(candidates)
+	reviewFiles := make([]map[string]any, 0, len(candidates))
+	for _, fileEntry := range candidates {
+		path := shared.TrimmedString(fileEntry["path"])
+		content, exists := contents.pr[path]
+		if !exists {
+			content = contents.base[path]
+		}
+		classification := fileclass.Classify(path, content)
+		classification.Apply(fileEntry)
+		if classification.MatchesAny(exclude) {
+			excluded++
+			continue
+		}
+		reviewFiles = append(reviewFiles, fileEntry)
+	}
+	return reviewFiles, excluded
+}
+
+func sortedCategories(categories map[string]bool) []string {
+	names := make([]string, 0, len(categories))
+	for name, enabled := range categories {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func resolveLatestIterationID(organization, project, repositoryID, pullRequestID string) (string, error) {
 	response, err := iterations.List(organization, project, repositoryID, pullRequestID)
 	if err != nil {
@@ -72,3 +72,62 @@ func TestNormalizeBundleLimit_DefaultAndCap(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyBundleFiles_ExcludesCategories(t *testing.T) {
+	allFiles := []map[string]any{
+		{"path": "/src/app.go"},
+		{"path": "/go.sum"},
+		{"path": "/vendor/lib/lib.go"},
+		{"path": "/docs/diagram.png"},
+	}
+
+	contents := preloadedBundleContents(map[string]string{"/src/app.go": "package app\n", "/vendor/lib/lib.go": "package lib\n"})
+	reviewFiles, excluded := classifyBundleFiles(allFiles, map[string]bool{"generated": true, "binary": true}, contents)
+	if excluded != 2 {
+		t.Fatalf("expected 2 excluded files, got %d", excluded)
+	}
+	if len(reviewFiles) != 2 || reviewFiles[0]["path"] != "/src/app.go" || reviewFiles[1]["path"] != "/vendor/lib/lib.go" {
+		t.Fatalf("unexpected review files: %#v", reviewFiles)
+	}
+	if reviewFiles[1]["vendored"] != true || reviewFiles[0]["language"] != "go" {
+		t.Fatalf("expected classification fields on entries, got %#v", reviewFiles)
+	}
+}
+
+func TestClassifyBundleFiles_ExcludesByContent(t *testing.T) {
+	allFiles := []map[string]any{
+		{"path": "/src/app.go"},
+		{"path": "/assets/model.bin.txt"},
+		{"path": "/api/client.go"},
+		{"path": "/README.md"},
+	}
+	contents := preloadedBundleContents(map[string]string{
+		"/src/app.go":           "package app\n",
+		"/assets/model.bin.txt": "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12\n",
+		"/api/client.go":        "// Code generated by oapi-codegen. DO NOT EDIT.\n\npackage api\n",
+		"/README.md":            "Do not edit the generated client by hand.\n",
+	})
+
+	reviewFiles, excluded := classifyBundleFiles(allFiles, map[string]bool{"generated": true, "lfsPointer": true}, contents)
+	if excluded != 2 {
+		t.Fatalf("expected the LFS pointer and the generated file to be excluded, got %d", excluded)
+	}
+	if len(reviewFiles) != 2 || reviewFiles[0]["path"] != "/src/app.go" || reviewFiles[1]["path"] != "/README.md" {
+		t.Fatalf("unexpected review files: %#v", reviewFiles)
+	}
+
+	vendoredOnly := []map[string]any{{"path": "/src/app.go"}}
+	if reviewFiles, _ := classifyBundleFiles(vendoredOnly, map[string]bool{"vendored": true}, newBundleContents("o", "p", "r", "main", "feature")); len(reviewFiles) != 1 {
+		t.Fatalf("expected path-only categories to need no content, got %#v", reviewFiles)
+	}
+}
+
+// preloadedBundleContents returns contents whose PR versions are already loaded, so no request is made.
+func preloadedBundleContents(pr map[string]string) *bundleContents {
+	contents := newBundleContents("o", "p", "r", "main", "feature")
+	for path, content := range pr {
+		contents.pr[path] = content
+		contents.loaded[path] = true
+	}
+	return contents
+}
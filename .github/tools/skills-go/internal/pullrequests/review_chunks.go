@@ -0,0 +1,220 @@
+package pullrequests
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	bundleFileOverheadTokens = 40
+	bytesPerToken            = 4
+	chunkContextLines        = 3
+)
+
+type reviewChunk struct {
+	Index           int
+	Paths           []string
+	EstimatedTokens int
+	OverBudget      bool
+}
+
+func (c *bundleContents) estimateTokens(fileEntry map[string]any, filePath string) int {
+	if isFolder, _ := fileEntry["isFolder"].(bool); isFolder {
+		return 0
+	}
+	if isBinary, _ := fileEntry["binary"].(bool); isBinary {
+		return bundleFileOverheadTokens
+	}
+	prContent, prExists := c.pr[filePath]
+	if prExists && fileclass.Classify(filePath, prContent).LFSPointer {
+		return bundleFileOverheadTokens
+	}
+	return estimateDiffTokens(c.base[filePath], prContent)
+}
+
+func estimateDiffTokens(baseContent, prContent string) int {
+	size := 0
+	for _, hunk := range linediff.Diff(baseContent, prContent, chunkContextLines) {
+		for _, line := range hunk.Lines {
+			size += len(line.Text) + 2
+		}
+	}
+	return bundleFileOverheadTokens + (size+bytesPerToken-1)/bytesPerToken
+}
+
+func planReviewChunks(entries []map[string]any, budget int) []reviewChunk {
+	tokensByPath := map[string]int{}
+	paths := make([]string, 0, len(entries))
+	for _, fileEntry := range entries {
+		filePath := shared.TrimmedString(fileEntry["path"])
+		if filePath == "" {
+			continue
+		}
+		tokensByPath[filePath] = toBundleInt(fileEntry["estimatedTokens"])
+		paths = append(paths, filePath)
+	}
+
+	chunks := make([]reviewChunk, 0)
+	current := reviewChunk{}
+	flush := func() {
+		if len(current.Paths) == 0 {
+			return
+		}
+		current.Index = len(chunks)
+		current.OverBudget = current.EstimatedTokens > budget
+		chunks = append(chunks, current)
+		current = reviewChunk{}
+	}
+	add := func(filePath string) {
+		current.Paths = append(current.Paths, filePath)
+		current.EstimatedTokens += tokensByPath[filePath]
+	}
+
+	for _, group := range groupRelatedFiles(paths) {
+		groupTokens := 0
+		for _, filePath := range group {
+			groupTokens += tokensByPath[filePath]
+		}
+		if len(current.Paths) > 0 && current.EstimatedTokens+groupTokens > budget {
+			flush()
+		}
+		if groupTokens <= budget {
+			for _, filePath := range group {
+				add(filePath)
+			}
+			continue
+		}
+		for _, filePath := range group {
+			if len(current.Paths) > 0 && current.EstimatedTokens+tokensByPath[filePath] > budget {
+				flush()
+			}
+			add(filePath)
+		}
+	}
+	flush()
+	return chunks
+}
+
+func groupRelatedFiles(paths []string) [][]string {
+	parent := make([]int, len(paths))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		rootA, rootB := find(a), find(b)
+		if rootA != rootB {
+			parent[rootB] = rootA
+		}
+	}
+
+	byDir := map[string]int{}
+	sourcesByStem := map[string][]int{}
+	for i, filePath := range paths {
+		dir := path.Dir(filePath)
+		if first, ok := byDir[dir]; ok {
+			union(first, i)
+		} else {
+			byDir[dir] = i
+		}
+		if _, isTest := testStem(filePath); !isTest {
+			stem := fileStem(filePath)
+			sourcesByStem[stem] = append(sourcesByStem[stem], i)
+		}
+	}
+	for i, filePath := range paths {
+		stem, isTest := testStem(filePath)
+		if !isTest {
+			continue
+		}
+		best, bestShared := -1, -1
+		for _, candidate := range sourcesByStem[stem] {
+			shared := commonPrefixLength(path.Dir(filePath), path.Dir(paths[candidate]))
+			if shared > bestShared {
+				best, bestShared = candidate, shared
+			}
+		}
+		if best >= 0 {
+			union(best, i)
+		}
+	}
+
+	groupsByRoot := map[int][]string{}
+	for i, filePath := range paths {
+		root := find(i)
+		groupsByRoot[root] = append(groupsByRoot[root], filePath)
+	}
+	groups := make([][]string, 0, len(groupsByRoot))
+	for _, group := range groupsByRoot {
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+func fileStem(filePath string) string {
+	name := strings.ToLower(path.Base(filePath))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func testStem(filePath string) (string, bool) {
+	name := path.Base(filePath)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	for _, suffix := range []string{".test", ".spec", "_test", "_spec", "Tests", "Test"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.ToLower(strings.TrimRight(strings.TrimSuffix(name, suffix), "._-")), true
+		}
+	}
+	if strings.HasPrefix(name, "test_") && len(name) > len("test_") {
+		return strings.ToLower(strings.TrimPrefix(name, "test_")), true
+	}
+	return strings.ToLower(name), false
+}
+
+func commonPrefixLength(a, b string) int {
+	length := 0
+	for length < len(a) && length < len(b) && a[length] == b[length] {
+		length++
+	}
+	return length
+}
+
+func chunkFiles(entries []map[string]any, chunk reviewChunk) []map[string]any {
+	byPath := map[string]map[string]any{}
+	for _, fileEntry := range entries {
+		byPath[shared.TrimmedString(fileEntry["path"])] = fileEntry
+	}
+	selected := make([]map[string]any, 0, len(chunk.Paths))
+	for _, filePath := range chunk.Paths {
+		if fileEntry, ok := byPath[filePath]; ok {
+			selected = append(selected, fileEntry)
+		}
+	}
+	return selected
+}
+
+func chunkPlanSummary(plan []reviewChunk) []map[string]any {
+	summary := make([]map[string]any, 0, len(plan))
+	for _, chunk := range plan {
+		summary = append(summary, map[string]any{
+			"index":           chunk.Index,
+			"fileCount":       len(chunk.Paths),
+			"estimatedTokens": chunk.EstimatedTokens,
+			"overBudget":      chunk.OverBudget,
+			"paths":           chunk.Paths,
+		})
+	}
+	return summary
+}
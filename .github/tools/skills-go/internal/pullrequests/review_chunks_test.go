@@ -0,0 +1,118 @@
+package pullrequests
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupRelatedFiles_KeepsDirectoriesAndTestPairsTogether(t *testing.T) {
+	paths := []string{
+		"/src/api/handler.go",
+		"/src/api/router.go",
+		"/src/web/app.ts",
+		"/tests/web/app.spec.ts",
+		"/src/Billing/Invoice.cs",
+		"/tests/Billing/InvoiceTests.cs",
+		"/docs/readme.md",
+	}
+
+	groups := groupRelatedFiles(paths)
+	want := [][]string{
+		{"/docs/readme.md"},
+		{"/src/Billing/Invoice.cs", "/tests/Billing/InvoiceTests.cs"},
+		{"/src/api/handler.go", "/src/api/router.go"},
+		{"/src/web/app.ts", "/tests/web/app.spec.ts"},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("unexpected groups: %#v", groups)
+	}
+}
+
+func TestTestStem(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantStem string
+		wantTest bool
+	}{
+		{path: "/a/foo_test.go", wantStem: "foo", wantTest: true},
+		{path: "/a/foo.test.ts", wantStem: "foo", wantTest: true},
+		{path: "/a/test_foo.py", wantStem: "foo", wantTest: true},
+		{path: "/a/FooTests.cs", wantStem: "foo", wantTest: true},
+		{path: "/a/FooTest.java", wantStem: "foo", wantTest: true},
+		{path: "/a/contest.go", wantStem: "contest", wantTest: false},
+		{path: "/a/test.go", wantStem: "test", wantTest: false},
+	}
+	for _, testCase := range tests {
+		stem, isTest := testStem(testCase.path)
+		if stem != testCase.wantStem || isTest != testCase.wantTest {
+			t.Fatalf("testStem(%s) = %q, %v; want %q, %v", testCase.path, stem, isTest, testCase.wantStem, testCase.wantTest)
+		}
+	}
+}
+
+func TestPlanReviewChunks_PacksGroupsWithinBudget(t *testing.T) {
+	files := []map[string]any{
+		{"path": "/a/one.go", "estimatedTokens": 40},
+		{"path": "/a/one_test.go", "estimatedTokens": 30},
+		{"path": "/b/two.go", "estimatedTokens": 50},
+		{"path": "/c/huge.go", "estimatedTokens": 500},
+		{"path": "/d/small.go", "estimatedTokens": 10},
+	}
+
+	plan := planReviewChunks(files, 100)
+	if len(plan) != 4 {
+		t.Fatalf("expected 4 chunks, got %#v", plan)
+	}
+	if !reflect.DeepEqual(plan[0].Paths, []string{"/a/one.go", "/a/one_test.go"}) || plan[0].EstimatedTokens != 70 {
+		t.Fatalf("unexpected first chunk: %#v", plan[0])
+	}
+	if !reflect.DeepEqual(plan[1].Paths, []string{"/b/two.go"}) {
+		t.Fatalf("unexpected second chunk: %#v", plan[1])
+	}
+	if !plan[2].OverBudget || plan[2].Index != 2 || !reflect.DeepEqual(plan[2].Paths, []string{"/c/huge.go"}) {
+		t.Fatalf("expected oversized file in its own chunk: %#v", plan[2])
+	}
+	if plan[3].OverBudget || plan[3].EstimatedTokens != 10 {
+		t.Fatalf("unexpected last chunk: %#v", plan[3])
+	}
+
+	selected := chunkFiles(files, plan[0])
+	if len(selected) != 2 || selected[1]["path"] != "/a/one_test.go" {
+		t.Fatalf("unexpected chunk files: %#v", selected)
+	}
+}
+
+func TestEstimateDiffTokens_GrowsWithChangeSize(t *testing.T) {
+	small := estimateDiffTokens("a\nb\n", "a\nc\n")
+	large := estimateDiffTokens("", "line one\nline two\nline three\nline four\n")
+	if small <= bundleFileOverheadTokens || large <= small {
+		t.Fatalf("unexpected estimates: small=%d large=%d", small, large)
+	}
+	if same := estimateDiffTokens("a\n", "a\n"); same != bundleFileOverheadTokens {
+		t.Fatalf("expected overhead only for unchanged content, got %d", same)
+	}
+}
+
+func TestBuildBundleFilesPage_BudgetModeOmitsOffsetAndLimit(t *testing.T) {
+	items := []map[string]any{{"path": "/a.go"}}
+	plan := []reviewChunk{{Index: 0, Paths: []string{"/a.go"}}, {Index: 1, Paths: []string{"/b.go"}}}
+
+	page := buildBundleFilesPage(items, 2, true, 5, 100, plan, ReviewBundleOptions{TokenBudget: 4000, ChunkIndex: 0})
+	if _, ok := page["offset"]; ok {
+		t.Fatalf("expected no offset in budget mode: %v", page)
+	}
+	if _, ok := page["limit"]; ok {
+		t.Fatalf("expected no limit in budget mode: %v", page)
+	}
+	if page["chunkCount"] != 2 || page["tokenBudget"] != 4000 || page["chunkIndex"] != 0 {
+		t.Fatalf("expected chunk fields in budget mode: %v", page)
+	}
+
+	page = buildBundleFilesPage(items, 2, true, 5, 100, nil, ReviewBundleOptions{})
+	if page["offset"] != 5 || page["limit"] != 100 {
+		t.Fatalf("expected offset and limit without a budget: %v", page)
+	}
+	if _, ok := page["chunkIndex"]; ok {
+		t.Fatalf("expected no chunk fields without a budget: %v", page)
+	}
+}
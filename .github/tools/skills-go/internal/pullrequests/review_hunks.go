@@ -0,0 +1,187 @@
+package pullrequests
+
+import (
+	"fmt"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+	"ado-reviewer/.github/tools/skills-go/internal/symbols"
+)
+
+const (
+	defaultBundleHunkContext   = 3
+	defaultBundleMaxFileBytes  = 16000
+	defaultBundleMaxTotalBytes = 200000
+	bundleFullContentMaxBytes  = 4000
+
+	hunksOmittedTotalLimit = "totalByteLimit"
+)
+
+type bundleHunkLimits struct {
+	Context              int
+	MaxFileBytes         int
+	MaxTotalBytes        int
+	IncludeEnclosingBody bool
+}
+
+type bundleHunkUsage struct {
+	UsedBytes      int
+	TruncatedFiles int
+}
+
+func (l bundleHunkLimits) normalized() bundleHunkLimits {
+	if l.Context < 0 {
+		l.Context = defaultBundleHunkContext
+	}
+	if l.MaxFileBytes <= 0 {
+		l.MaxFileBytes = defaultBundleMaxFileBytes
+	}
+	if l.MaxTotalBytes <= 0 {
+		l.MaxTotalBytes = defaultBundleMaxTotalBytes
+	}
+	return l
+}
+
+func attachBundleHunks(entries []map[string]any, contents *bundleContents, limits bundleHunkLimits) bundleHunkUsage {
+	usage := bundleHunkUsage{}
+	for _, fileEntry := range entries {
+		path := shared.TrimmedString(fileEntry["path"])
+		if path == "" {
+			continue
+		}
+		if isFolder, _ := fileEntry["isFolder"].(bool); isFolder {
+			continue
+		}
+		if isBinary, _ := fileEntry["binary"].(bool); isBinary {
+			fileEntry["hunksSkipped"] = fileclass.CategoryBinary
+			continue
+		}
+
+		baseContent := contents.base[path]
+		prContent, prExists := contents.pr[path]
+		sniffed := prContent
+		if !prExists {
+			sniffed = baseContent
+		}
+		if reason := fileclass.Classify(path, sniffed).SkipReason(); reason != "" {
+			fileEntry["hunksSkipped"] = reason
+			continue
+		}
+
+		remaining := limits.MaxTotalBytes - usage.UsedBytes
+		if remaining <= 0 {
+			fileEntry["hunks"] = []map[string]any{}
+			fileEntry["hunksTruncated"] = true
+			fileEntry["hunksOmittedReason"] = hunksOmittedTotalLimit
+			usage.TruncatedFiles++
+			continue
+		}
+		budget := limits.MaxFileBytes
+		if remaining < budget {
+			budget = remaining
+		}
+
+		used := 0
+		if prExists && len(prContent) <= bundleFullContentMaxBytes && len(prContent) <= budget {
+			fileEntry["content"] = prContent
+			used += len(prContent)
+		}
+
+		diffHunks := linediff.Diff(baseContent, prContent, limits.Context)
+		hunks, hunkBytes, omitted := renderBundleHunks(diffHunks, budget-used)
+		used += hunkBytes
+		used += annotateBundleHunks(hunks, diffHunks, symbols.NewLocator(path, baseContent, prContent), limits.IncludeEnclosingBody, budget-used)
+		fileEntry["hunks"] = hunks
+		fileEntry["hunkBytes"] = used
+		fileEntry["hunksTruncated"] = omitted > 0
+		if omitted > 0 {
+			fileEntry["omittedHunks"] = omitted
+			usage.TruncatedFiles++
+		}
+		usage.UsedBytes += used
+	}
+	return usage
+}
+
+// renderBundleHunks renders hunks as unified diff text until the byte budget is spent.
+// The returned count covers the hunk cut short (marked truncated) plus every hunk after it.
+func renderBundleHunks(hunks []linediff.Hunk, budget int) ([]map[string]any, int, int) {
+	rendered := make([]map[string]any, 0, len(hunks))
+	used := 0
+	for index, hunk := range hunks {
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		if used+len(header) > budget {
+			return rendered, used, len(hunks) - index
+		}
+
+		var builder strings.Builder
+		builder.WriteString(header)
+		truncated := false
+		for _, line := range hunk.Lines {
+			text := unifiedPrefix(line.Kind) + line.Text + "\n"
+			if used+builder.Len()+len(text) > budget {
+				truncated = true
+				break
+			}
+			builder.WriteString(text)
+		}
+		used += builder.Len()
+		rendered = append(rendered, map[string]any{
+			"oldStart":  hunk.OldStart,
+			"oldLines":  hunk.OldLines,
+			"newStart":  hunk.NewStart,
+			"newLines":  hunk.NewLines,
+			"text":      builder.String(),
+			"truncated": truncated,
+		})
+		if truncated {
+			return rendered, used, len(hunks) - index
+		}
+	}
+	return rendered, used, 0
+}
+
+// annotateBundleHunks adds the enclosing symbol to each rendered hunk and, when requested, the symbol body.
+// A body shared by several hunks is included once; bodies that do not fit the remaining budget are marked omitted.
+func annotateBundleHunks(rendered []map[string]any, hunks []linediff.Hunk, locator *symbols.Locator, includeBody bool, budget int) int {
+	used := 0
+	seen := map[string]bool{}
+	for index, entry := range rendered {
+		symbol, side, ok := locator.Locate(hunks[index])
+		if !ok {
+			continue
+		}
+		enclosing := symbol.ToMap()
+		enclosing["side"] = side
+		entry["enclosingSymbol"] = enclosing
+		if !includeBody {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s:%d", side, symbol.Name, symbol.StartLine)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		body := symbols.Body(locator.Content(side), symbol)
+		if used+len(body) > budget {
+			entry["enclosingBodyOmitted"] = true
+			continue
+		}
+		entry["enclosingBody"] = body
+		used += len(body)
+	}
+	return used
+}
+
+func unifiedPrefix(kind string) string {
+	switch kind {
+	case linediff.KindAdded:
+		return "+"
+	case linediff.KindDeleted:
+		return "-"
+	default:
+		return " "
+	}
+}
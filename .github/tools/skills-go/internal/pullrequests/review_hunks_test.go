@@ -0,0 +1,78 @@
+package pullrequests
+
+import (
+	"strings"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+)
+
+func TestRenderBundleHunks_UnifiedText(t *testing.T) {
+	hunks := linediff.Diff("a\nb\nc\n", "a\nB\nc\n", 1)
+	rendered, used, omitted := renderBundleHunks(hunks, 1000)
+	if omitted != 0 || len(rendered) != 1 {
+		t.Fatalf("unexpected render result: %#v omitted=%d", rendered, omitted)
+	}
+	want := "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"
+	if rendered[0]["text"] != want || used != len(want) {
+		t.Fatalf("unexpected hunk text %q (used %d)", rendered[0]["text"], used)
+	}
+}
+
+func TestRenderBundleHunks_TruncatesAtBudget(t *testing.T) {
+	hunks := linediff.Diff("a\nb\nc\nd\ne\nf\ng\nh\n", "A\nb\nc\nd\ne\nf\ng\nH\n", 0)
+	rendered, used, omitted := renderBundleHunks(hunks, 20)
+	if len(rendered) != 1 || omitted != 2 || used > 20 {
+		t.Fatalf("unexpected render result: %#v used=%d omitted=%d", rendered, used, omitted)
+	}
+	if truncated, _ := rendered[0]["truncated"].(bool); !truncated {
+		t.Fatalf("expected first hunk to be truncated: %#v", rendered[0])
+	}
+}
+
+func TestAttachBundleHunks_RespectsTotalLimit(t *testing.T) {
+	contents := newBundleContents("org", "proj", "repo", "main", "feature")
+	contents.base["/a.txt"] = "one\n"
+	contents.pr["/a.txt"] = "two\n"
+	contents.base["/b.txt"] = strings.Repeat("x\n", 10)
+	contents.pr["/b.txt"] = strings.Repeat("y\n", 10)
+	entries := []map[string]any{{"path": "/a.txt"}, {"path": "/b.txt"}, {"path": "/logo.png", "binary": true}}
+
+	usage := attachBundleHunks(entries, contents, bundleHunkLimits{Context: 3, MaxFileBytes: 1000, MaxTotalBytes: 30}.normalized())
+	if entries[0]["content"] != "two\n" {
+		t.Fatalf("expected small file content to be inlined: %#v", entries[0])
+	}
+	if truncated, _ := entries[1]["hunksTruncated"].(bool); !truncated {
+		t.Fatalf("expected second file to be truncated: %#v", entries[1])
+	}
+	if entries[2]["hunksSkipped"] != "binary" {
+		t.Fatalf("expected binary file to be skipped: %#v", entries[2])
+	}
+	if usage.UsedBytes > 30 || usage.TruncatedFiles != 1 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAttachBundleHunks_IncludesEnclosingBodyOnce(t *testing.T) {
+	contents := newBundleContents("org", "proj", "repo", "main", "feature")
+	base := "package sample\n\nfunc Run() {\n\ta()\n\tb()\n\tc()\n\td()\n\te()\n\tf()\n\tg()\n\th()\n}\n"
+	contents.base["/run.go"] = base
+	contents.pr["/run.go"] = strings.Replace(strings.Replace(base, "a()", "A()", 1), "h()", "H()", 1)
+	entries := []map[string]any{{"path": "/run.go"}}
+
+	attachBundleHunks(entries, contents, bundleHunkLimits{Context: 0, IncludeEnclosingBody: true}.normalized())
+	hunks, _ := entries[0]["hunks"].([]map[string]any)
+	if len(hunks) != 2 {
+		t.Fatalf("expected two hunks, got %#v", entries[0]["hunks"])
+	}
+	symbol, _ := hunks[0]["enclosingSymbol"].(map[string]any)
+	if symbol["name"] != "Run" || symbol["startLine"] != 3 || symbol["endLine"] != 12 {
+		t.Fatalf("unexpected enclosing symbol: %#v", hunks[0]["enclosingSymbol"])
+	}
+	if body, _ := hunks[0]["enclosingBody"].(string); !strings.HasPrefix(body, "func Run() {") {
+		t.Fatalf("expected enclosing body on first hunk, got %q", body)
+	}
+	if _, ok := hunks[1]["enclosingBody"]; ok {
+		t.Fatalf("expected shared body to be included only once")
+	}
+}
@@ -0,0 +1,515 @@
+package pullrequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/reviews"
+)
+
+const (
+	SubmitStatusComplete = "complete"
+	SubmitStatusPartial  = "partial"
+	SubmitStatusAborted  = "aborted"
+	SubmitStatusDryRun   = "dryRun"
+
+	findingStatusReady     = "ready"
+	findingStatusPosted    = "posted"
+	findingStatusFailed    = "failed"
+	findingStatusInvalid   = "invalid"
+	findingStatusSkipped   = "skipped"
+	findingStatusDuplicate = "duplicate"
+)
+
+var severityLabels = map[string]string{
+	"critical":   "🔴 Critical",
+	"major":      "🟠 Major",
+	"minor":      "🟡 Minor",
+	"suggestion": "🔵 Suggestion",
+}
+
+var reviewVotes = map[string]int{
+	"approve":                  10,
+	"accept":                   10,
+	"accept-pr":                10,
+	"approve-with-suggestions": 5,
+	"none":                     0,
+	"reset":                    0,
+	"reset-feedback":           0,
+	"wait-for-author":          -5,
+	"reject":                   -10,
+	"reject-pr":                -10,
+}
+
+// Finding is one review finding to post as a thread. File and Line are omitted for general
+// (file-less) findings. Suggestion, when set, replaces lines Line..EndLine on the PR side, which
+// must still read Original when that is set. Key, when set, replaces title and body in the
+// fingerprint so rewording a finding keeps its thread.
+type Finding struct {
+	File       string  `json:"file"`
+	Line       int     `json:"line"`
+	EndLine    int     `json:"endLine"`
+	Side       string  `json:"side"`
+	Severity   string  `json:"severity"`
+	Category   string  `json:"category"`
+	Title      string  `json:"title"`
+	Body       string  `json:"body"`
+	Suggestion *string `json:"suggestion"`
+	Original   *string `json:"original,omitempty"`
+	Key        string  `json:"key,omitempty"`
+}
+
+// ReviewDocument is the submit-review input: findings plus an optional summary thread and vote.
+// SummaryKey, when set, replaces the summary text in its fingerprint, like Finding.Key.
+type ReviewDocument struct {
+	Summary    string    `json:"summary"`
+	SummaryKey string    `json:"summaryKey,omitempty"`
+	Vote       string    `json:"vote"`
+	Findings   []Finding `json:"findings"`
+}
+
+type SubmitReviewOptions struct {
+	Organization  string
+	Project       string
+	RepositoryID  string
+	PullRequestID string
+	PositionMode  string
+	IterationID   string
+	OnDuplicate   string
+	DryRun        bool
+}
+
+// ParseReviewDocument reads a findings document: either an object with findings, summary and vote,
+// or a bare array of findings.
+func ParseReviewDocument(data []byte) (ReviewDocument, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return ReviewDocument{}, fmt.Errorf("findings document is empty")
+	}
+
+	var document ReviewDocument
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &document.Findings); err != nil {
+			return ReviewDocument{}, fmt.Errorf("invalid findings document: %w", err)
+		}
+		return document, nil
+	}
+	if err := json.Unmarshal(trimmed, &document); err != nil {
+		return ReviewDocument{}, fmt.Errorf("invalid findings document: %w", err)
+	}
+	return document, nil
+}
+
+// ValidateReviewDocument returns one message per problem in the document; it does not call Azure DevOps.
+func ValidateReviewDocument(document ReviewDocument) []string {
+	problems := make([]string, 0)
+	if len(document.Findings) == 0 && strings.TrimSpace(document.Summary) == "" && strings.TrimSpace(document.Vote) == "" {
+		problems = append(problems, "document has no findings, summary or vote")
+	}
+	if vote := strings.TrimSpace(document.Vote); vote != "" {
+		if _, ok := reviewVotes[strings.ToLower(vote)]; !ok {
+			problems = append(problems, fmt.Sprintf("vote %q must be one of: approve, approve-with-suggestions, wait-for-author, reject, reset", vote))
+		}
+	}
+	firstByFingerprint := map[string]int{}
+	for index, finding := range document.Findings {
+		for _, problem := range validateFinding(finding) {
+			problems = append(problems, fmt.Sprintf("finding %d: %s", index, problem))
+		}
+		fingerprint := FindingFingerprint(finding)
+		if first, seen := firstByFingerprint[fingerprint]; seen {
+			problems = append(problems, fmt.Sprintf("finding %d: same fingerprint as finding %d (category, file and title/body or key); merge them or give each a distinct key", index, first))
+			continue
+		}
+		firstByFingerprint[fingerprint] = index
+	}
+	return problems
+}
+
+func validateFinding(finding Finding) []string {
+	problems := make([]string, 0)
+	if strings.TrimSpace(finding.Title) == "" && strings.TrimSpace(finding.Body) == "" {
+		problems = append(problems, "title or body is required")
+	}
+	if severity := strings.TrimSpace(finding.Severity); severity != "" {
+		if _, ok := severityLabels[strings.ToLower(severity)]; !ok {
+			problems = append(problems, fmt.Sprintf("severity %q must be one of: critical, major, minor, suggestion", severity))
+		}
+	}
+	if _, err := NormalizeCommentSide(finding.Side); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	hasFile := strings.TrimSpace(finding.File) != "" && strings.TrimSpace(finding.File) != "-"
+	if !hasFile {
+		if finding.Line != 0 || finding.EndLine != 0 {
+			problems = append(problems, "line requires file")
+		}
+		if finding.Suggestion != nil {
+			problems = append(problems, "suggestion requires file and line")
+		}
+		if finding.Original != nil {
+			problems = append(problems, "original requires a suggestion")
+		}
+		return problems
+	}
+	if _, err := ado.NormalizeADOFilePath(finding.File); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if finding.Line < 1 {
+		problems = append(problems, "line must be >= 1 when file is set")
+	}
+	if finding.EndLine != 0 && finding.EndLine < finding.Line {
+		problems = append(problems, "endLine must be >= line")
+	}
+	if finding.Suggestion != nil {
+		if side, _ := NormalizeCommentSide(finding.Side); side != CommentSideRight {
+			problems = append(problems, "suggestions apply to the right (PR) side only")
+		}
+	} else if finding.Original != nil {
+		problems = append(problems, "original requires a suggestion")
+	}
+	return problems
+}
+
+// FormatFindingComment renders a finding the way the review prompt formats comments:
+// "🟠 Major | Security<br/>**Title**<br/>Body", followed by a suggestion block when present.
+func FormatFindingComment(finding Finding) string {
+	header := make([]string, 0, 2)
+	if label, ok := severityLabels[strings.ToLower(strings.TrimSpace(finding.Severity))]; ok {
+		header = append(header, label)
+	}
+	if category := strings.TrimSpace(finding.Category); category != "" {
+		header = append(header, category)
+	}
+
+	sections := make([]string, 0, 3)
+	if len(header) > 0 {
+		sections = append(sections, strings.Join(header, " | "))
+	}
+	if title := strings.TrimSpace(finding.Title); title != "" {
+		sections = append(sections, "**"+title+"**")
+	}
+	if body := strings.TrimSpace(finding.Body); body != "" {
+		sections = append(sections, body)
+	}
+	text := strings.Join(sections, "<br/>")
+	if finding.Suggestion != nil {
+		return buildSuggestionBody(text, normalizeSuggestionText(*finding.Suggestion))
+	}
+	return text
+}
+
+// SubmitReview posts every finding as a thread, then the summary thread and the vote. All findings
+// are validated and positioned before anything is posted, so an invalid document posts nothing.
+// If a post fails the remaining findings are still attempted, but the vote is not cast.
+func SubmitReview(options SubmitReviewOptions, document ReviewDocument) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
+	if err != nil {
+		return nil, err
+	}
+	projectName := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
+	if projectName == "" || repo == "" || prID == "" {
+		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
+	}
+	mode, err := NormalizePositionMode(options.PositionMode)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NormalizeDuplicatePolicy(options.OnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, len(document.Findings))
+	for index, finding := range document.Findings {
+		results[index] = findingResult(index, finding)
+	}
+	if problems := ValidateReviewDocument(document); len(problems) > 0 {
+		for index, finding := range document.Findings {
+			if findingProblems := validateFinding(finding); len(findingProblems) > 0 {
+				results[index]["status"] = findingStatusInvalid
+				results[index]["error"] = strings.Join(findingProblems, "; ")
+			} else {
+				results[index]["status"] = findingStatusSkipped
+			}
+		}
+		return submitResult(SubmitStatusAborted, results, nil, nil, problems), nil
+	}
+
+	commentOptions := CommentOptions{
+		Organization:  options.Organization,
+		Project:       projectName,
+		RepositoryID:  repo,
+		PullRequestID: prID,
+		IterationID:   options.IterationID,
+	}
+	payloads, problems := prepareFindingThreads(commentOptions, document.Findings, mode, results)
+	if len(problems) > 0 {
+		for _, result := range results {
+			if result["status"] == findingStatusReady {
+				result["status"] = findingStatusSkipped
+			}
+		}
+		return submitResult(SubmitStatusAborted, results, nil, nil, problems), nil
+	}
+
+	summary := map[string]any{}
+	if text := strings.TrimSpace(document.Summary); text != "" {
+		summary["status"] = findingStatusReady
+	}
+	vote := map[string]any{}
+	if name := strings.ToLower(strings.TrimSpace(document.Vote)); name != "" {
+		vote["name"] = name
+		vote["vote"] = reviewVotes[name]
+		vote["status"] = findingStatusReady
+	}
+	threadsURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%s/threads?api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), prID)
+	existing := map[string]map[string]any{}
+	if policy != DuplicatePost {
+		if existing, err = listThreadsByFingerprint(client, threadsURL); err != nil {
+			return nil, err
+		}
+	}
+	summaryPayload := generalThreadPayload(document.Summary)
+	summaryFingerprint := SummaryFingerprint(document)
+	if len(summary) > 0 {
+		summaryPayload["properties"] = fingerprintProperties(summaryFingerprint)
+		summary["fingerprint"] = summaryFingerprint
+	}
+
+	if options.DryRun {
+		for index := range payloads {
+			if thread, ok := existing[results[index]["fingerprint"].(string)]; ok {
+				results[index]["existingThreadId"] = thread["id"]
+			}
+		}
+		if thread, ok := existing[summaryFingerprint]; ok && len(summary) > 0 {
+			summary["existingThreadId"] = thread["id"]
+		}
+		return submitResult(SubmitStatusDryRun, results, summary, vote, nil), nil
+	}
+
+	// post creates a thread unless one with the same fingerprint exists, in which case the duplicate
+	// policy decides whether it is left alone, updated or reopened.
+	user := &authenticatedUser{client: client}
+	post := func(payload map[string]any, fingerprint string, result map[string]any) bool {
+		content := payload["comments"].([]map[string]any)[0]["content"].(string)
+		if thread, ok := existing[fingerprint]; ok {
+			action, err := applyDuplicatePolicy(client, threadsURL, thread, content, policy, user)
+			if err != nil {
+				result["status"] = findingStatusFailed
+				result["error"] = err.Error()
+				return false
+			}
+			result["status"] = findingStatusDuplicate
+			result["duplicateAction"] = action
+			result["threadId"] = thread["id"]
+			return true
+		}
+
+		response := map[string]any{}
+		if err := client.PostJSON(threadsURL, payload, &response); err != nil {
+			result["status"] = findingStatusFailed
+			result["error"] = err.Error()
+			return false
+		}
+		result["status"] = findingStatusPosted
+		result["threadId"] = response["id"]
+		if policy != DuplicatePost {
+			existing[fingerprint] = response
+		}
+		return true
+	}
+
+	failed := false
+	for index, payload := range payloads {
+		if !post(payload, results[index]["fingerprint"].(string), results[index]) {
+			failed = true
+		}
+	}
+	if len(summary) > 0 && !post(summaryPayload, summaryFingerprint, summary) {
+		failed = true
+	}
+
+	if len(vote) > 0 {
+		if failed {
+			vote["status"] = findingStatusSkipped
+			vote["error"] = "not cast because some threads failed to post"
+		} else if _, err := reviews.SetVote(options.Organization, projectName, repo, prID, vote["vote"].(int)); err != nil {
+			vote["status"] = findingStatusFailed
+			vote["error"] = err.Error()
+			failed = true
+		} else {
+			vote["status"] = findingStatusPosted
+		}
+	}
+
+	status := SubmitStatusComplete
+	if failed {
+		status = SubmitStatusPartial
+	}
+	return submitResult(status, results, summary, vote, nil), nil
+}
+
+// prepareFindingThreads builds the thread payload for every finding, resolving inline positions
+// against one iteration. It marks each result ready or invalid and returns the problems found.
+func prepareFindingThreads(options CommentOptions, findings []Finding, mode string, results []map[string]any) ([]map[string]any, []string) {
+	payloads := make([]map[string]any, len(findings))
+	problems := make([]string, 0)
+	var source *positionSource
+	var sourceErr error
+
+	for index, finding := range findings {
+		payload := generalThreadPayload(FormatFindingComment(finding))
+		fingerprint := FindingFingerprint(finding)
+		payload["properties"] = fingerprintProperties(fingerprint)
+		results[index]["fingerprint"] = fingerprint
+		filePath := strings.TrimSpace(finding.File)
+		if filePath == "" || filePath == "-" {
+			payloads[index] = payload
+			results[index]["status"] = findingStatusReady
+			continue
+		}
+
+		normalized, _ := ado.NormalizeADOFilePath(filePath)
+		side, _ := NormalizeCommentSide(finding.Side)
+		endLine := finding.EndLine
+		if endLine == 0 {
+			endLine = finding.Line
+		}
+		requested := commentRange{Side: side, StartLine: finding.Line, EndLine: endLine}
+		resolved := requested
+
+		needsPosition := mode != PositionModeNone || strings.TrimSpace(options.IterationID) != "" || !requested.isLegacy() || finding.Suggestion != nil
+		if needsPosition {
+			if source == nil && sourceErr == nil {
+				source, sourceErr = newPositionSource(options)
+			}
+			if sourceErr != nil {
+				results[index]["status"] = findingStatusInvalid
+				results[index]["error"] = sourceErr.Error()
+				problems = append(problems, fmt.Sprintf("finding %d: %s", index, sourceErr.Error()))
+				continue
+			}
+
+			positionMode := mode
+			if finding.Suggestion != nil {
+				positionMode = PositionModeNone
+			}
+			position, err := source.resolve(normalized, requested, positionMode, !requested.isLegacy() || finding.Suggestion != nil)
+			if err == nil && finding.Suggestion != nil && finding.Original != nil {
+				err = checkExpectedOriginal(position, *finding.Original, normalized)
+			}
+			if err == nil && finding.Suggestion != nil {
+				err = checkSuggestionChangesLines(position, normalizeSuggestionText(*finding.Suggestion), normalized)
+			}
+			if err != nil {
+				results[index]["status"] = findingStatusInvalid
+				results[index]["error"] = err.Error()
+				problems = append(problems, fmt.Sprintf("finding %d: %s", index, err.Error()))
+				continue
+			}
+			resolved = position.Range
+			payload["pullRequestThreadContext"] = buildPullRequestThreadContext(position)
+			results[index]["commentPosition"] = map[string]any{
+				"requested":   position.Requested.toMap(),
+				"resolved":    position.Range.toMap(),
+				"snapped":     position.Snapped,
+				"iterationId": position.IterationID,
+			}
+		}
+
+		payload["threadContext"] = resolved.threadContext(normalized)
+		payloads[index] = payload
+		results[index]["status"] = findingStatusReady
+	}
+	return payloads, problems
+}
+
+// FindingFingerprint fingerprints a finding by category, file, title and body (or its key), so
+// changing its severity, line or suggestion on a later run updates the same thread instead of
+// adding another.
+func FindingFingerprint(finding Finding) string {
+	if key := strings.TrimSpace(finding.Key); key != "" {
+		return Fingerprint(finding.Category, finding.File, key)
+	}
+	return Fingerprint(finding.Category, finding.File, finding.Title+"\n"+finding.Body)
+}
+
+// SummaryFingerprint fingerprints the summary thread of a document by its SummaryKey, or by its
+// text when no key is set.
+func SummaryFingerprint(document ReviewDocument) string {
+	if key := strings.TrimSpace(document.SummaryKey); key != "" {
+		return Fingerprint("summary", "", key)
+	}
+	return Fingerprint("summary", "", document.Summary)
+}
+
+func checkSuggestionChangesLines(position commentPosition, replacement, filePath string) error {
+	original := position.Lines[position.Range.StartLine-1 : position.Range.EndLine]
+	if replacement == strings.Join(original, "\n") {
+		return fmt.Errorf("replacement is identical to lines %s of %s in iteration %s", formatLineRange(position.Range), filePath, position.IterationID)
+	}
+	return nil
+}
+
+func generalThreadPayload(content string) map[string]any {
+	return map[string]any{
+		"comments": []map[string]any{{
+			"parentCommentId": 0,
+			"content":         content,
+			"commentType":     "text",
+		}},
+		"status": "active",
+	}
+}
+
+func findingResult(index int, finding Finding) map[string]any {
+	result := map[string]any{"index": index, "title": strings.TrimSpace(finding.Title)}
+	if filePath := strings.TrimSpace(finding.File); filePath != "" && filePath != "-" {
+		result["file"] = filePath
+		result["line"] = finding.Line
+		if finding.EndLine > finding.Line {
+			result["endLine"] = finding.EndLine
+		}
+	}
+	if finding.Suggestion != nil {
+		result["suggestion"] = true
+	}
+	return result
+}
+
+func submitResult(status string, results []map[string]any, summary, vote map[string]any, problems []string) map[string]any {
+	counts := map[string]int{}
+	for _, result := range results {
+		if status, ok := result["status"].(string); ok {
+			counts[status]++
+		}
+	}
+
+	output := map[string]any{
+		"status":     status,
+		"total":      len(results),
+		"posted":     counts[findingStatusPosted],
+		"duplicates": counts[findingStatusDuplicate],
+		"failed":     counts[findingStatusFailed],
+		"findings":   results,
+	}
+	if len(summary) > 0 {
+		output["summary"] = summary
+	}
+	if len(vote) > 0 {
+		output["vote"] = vote
+	}
+	if len(problems) > 0 {
+		output["problems"] = problems
+	}
+	return output
+}
@@ -0,0 +1,152 @@
+package pullrequests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReviewDocument(t *testing.T) {
+	document, err := ParseReviewDocument([]byte(`{"summary": "Looks good", "vote": "approve-with-suggestions", "findings": [{"file": "/a.go", "line": 3, "title": "Nil check", "suggestion": ""}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if document.Summary != "Looks good" || document.Vote != "approve-with-suggestions" || len(document.Findings) != 1 {
+		t.Fatalf("unexpected document: %#v", document)
+	}
+	if document.Findings[0].Suggestion == nil || *document.Findings[0].Suggestion != "" {
+		t.Fatalf("expected an empty suggestion to be kept as a line deletion")
+	}
+
+	bare, err := ParseReviewDocument([]byte(` [{"title": "General"}]`))
+	if err != nil || len(bare.Findings) != 1 || bare.Findings[0].Suggestion != nil {
+		t.Fatalf("expected bare findings array to parse, got %#v, %v", bare, err)
+	}
+
+	if _, err := ParseReviewDocument([]byte("  ")); err == nil {
+		t.Fatalf("expected empty document to fail")
+	}
+	if _, err := ParseReviewDocument([]byte(`{"findings": "x"}`)); err == nil {
+		t.Fatalf("expected malformed document to fail")
+	}
+}
+
+func TestValidateReviewDocument(t *testing.T) {
+	suggestion := "x"
+	document := ReviewDocument{
+		Vote: "lgtm",
+		Findings: []Finding{
+			{Title: "ok", File: "/a.go", Line: 4},
+			{File: "/a.go", Line: 4},
+			{Title: "bad severity", Severity: "blocker"},
+			{Title: "line without file", Line: 3},
+			{Title: "backwards", File: "/a.go", Line: 9, EndLine: 2},
+			{Title: "left suggestion", File: "/a.go", Line: 1, Side: "left", Suggestion: &suggestion},
+			{Title: "original without suggestion", File: "/a.go", Line: 1, Original: &suggestion},
+			{Title: "windows path", File: "C:\\repo\\a.go", Line: 1},
+		},
+	}
+
+	want := []string{
+		`vote "lgtm" must be one of: approve, approve-with-suggestions, wait-for-author, reject, reset`,
+		"finding 1: title or body is required",
+		`finding 2: severity "blocker" must be one of: critical, major, minor, suggestion`,
+		"finding 3: line requires file",
+		"finding 4: endLine must be >= line",
+		"finding 5: suggestions apply to the right (PR) side only",
+		"finding 6: original requires a suggestion",
+	}
+	problems := ValidateReviewDocument(document)
+	if len(problems) != len(want)+1 || !reflect.DeepEqual(problems[:len(want)], want) || !strings.HasPrefix(problems[len(want)], "finding 7: FilePath must be repository-relative") {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(problems, "\n"))
+	}
+
+	duplicates := ReviewDocument{Findings: []Finding{
+		{Title: "Unescaped input", File: "/a.go", Line: 4},
+		{Title: "unescaped  input", File: "/A.go", Line: 9},
+		{Title: "Unescaped input", File: "/a.go", Line: 12, Key: "second-handler"},
+	}}
+	if problems := ValidateReviewDocument(duplicates); len(problems) != 1 || !strings.HasPrefix(problems[0], "finding 1: same fingerprint as finding 0") {
+		t.Fatalf("expected only the repeated finding to be rejected, got %v", problems)
+	}
+
+	if problems := ValidateReviewDocument(ReviewDocument{}); len(problems) != 1 {
+		t.Fatalf("expected empty document to be rejected, got %v", problems)
+	}
+}
+
+func TestFormatFindingComment(t *testing.T) {
+	finding := Finding{Severity: "Major", Category: "Security", Title: "Unescaped input", Body: "Escape the value before rendering."}
+	if got, want := FormatFindingComment(finding), "🟠 Major | Security<br/>**Unescaped input**<br/>Escape the value before rendering."; got != want {
+		t.Fatalf("unexpected comment:\n got: %q\nwant: %q", got, want)
+	}
+
+	replacement := "html.EscapeString(value)\r\n"
+	finding.Suggestion = &replacement
+	want := "🟠 Major | Security<br/>**Unescaped input**<br/>Escape the value before rendering.\n\n```suggestion\nhtml.EscapeString(value)\n```"
+	if got := FormatFindingComment(finding); got != want {
+		t.Fatalf("unexpected suggestion comment:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestSubmitReview_InvalidDocumentPostsNothing(t *testing.T) {
+	t.Setenv("ADO_PAT_testorg", "token")
+
+	document := ReviewDocument{Findings: []Finding{{Title: "fine"}, {Title: "broken", Severity: "huge"}}}
+	result, err := SubmitReview(SubmitReviewOptions{Organization: "testorg", Project: "p", RepositoryID: "r", PullRequestID: "1"}, document)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["status"] != SubmitStatusAborted || result["posted"] != 0 {
+		t.Fatalf("expected aborted submission, got %#v", result)
+	}
+	findings := result["findings"].([]map[string]any)
+	if findings[0]["status"] != findingStatusSkipped || findings[1]["status"] != findingStatusInvalid {
+		t.Fatalf("unexpected finding statuses: %#v", findings)
+	}
+}
+
+func TestSubmitReview_DryRunWithoutPositions(t *testing.T) {
+	t.Setenv("ADO_PAT_testorg", "token")
+
+	document := ReviewDocument{
+		Summary:  "Two notes",
+		Vote:     "wait-for-author",
+		Findings: []Finding{{Title: "General"}, {Title: "Inline", File: "src/a.go", Line: 7}},
+	}
+	options := SubmitReviewOptions{Organization: "testorg", Project: "p", RepositoryID: "r", PullRequestID: "1", PositionMode: PositionModeNone, OnDuplicate: DuplicatePost, DryRun: true}
+	result, err := SubmitReview(options, document)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["status"] != SubmitStatusDryRun {
+		t.Fatalf("expected dry run, got %#v", result)
+	}
+	findings := result["findings"].([]map[string]any)
+	if findings[0]["status"] != findingStatusReady || findings[1]["status"] != findingStatusReady || findings[1]["file"] != "src/a.go" {
+		t.Fatalf("unexpected findings: %#v", findings)
+	}
+	if findings[0]["fingerprint"] != FindingFingerprint(document.Findings[0]) {
+		t.Fatalf("expected findings to carry their fingerprint, got %#v", findings[0])
+	}
+	vote := result["vote"].(map[string]any)
+	if vote["vote"] != -5 || vote["status"] != findingStatusReady {
+		t.Fatalf("unexpected vote: %#v", vote)
+	}
+}
+
+func TestPrepareFindingThreads_LegacyInlineContext(t *testing.T) {
+	findings := []Finding{{Title: "Inline", File: "src/a.go", Line: 7}}
+	results := []map[string]any{findingResult(0, findings[0])}
+	payloads, problems := prepareFindingThreads(CommentOptions{}, findings, PositionModeNone, results)
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	context := payloads[0]["threadContext"].(map[string]any)
+	if context["filePath"] != "/src/a.go" || !reflect.DeepEqual(context["rightFileStart"], map[string]int{"line": 7, "offset": 1}) {
+		t.Fatalf("unexpected thread context: %#v", context)
+	}
+	if _, ok := payloads[0]["pullRequestThreadContext"]; ok {
+		t.Fatalf("expected no iteration context without position resolution")
+	}
+}
@@ -0,0 +1,172 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/linediff"
+)
+
+// suggestionCategory is the fingerprint category of threads posted by PostSuggestion.
+const suggestionCategory = "suggestion"
+
+// SuggestionOptions describes a suggestion for lines StartLine..EndLine. ExpectedOriginal, when
+// set, is the text the replacement was written against; the suggestion is refused when the
+// iteration's lines no longer match it. The thread is fingerprinted like a comment, from the file
+// and Key or, when Key is empty, the comment and replacement, and OnDuplicate decides what happens
+// when that fingerprint already exists.
+type SuggestionOptions struct {
+	Organization     string
+	Project          string
+	RepositoryID     string
+	PullRequestID    string
+	FilePath         string
+	StartLine        int
+	EndLine          int
+	Replacement      string
+	Comment          string
+	IterationID      string
+	ExpectedOriginal *string
+	OnDuplicate      string
+	Key              string
+}
+
+func PostSuggestion(options SuggestionOptions) (map[string]any, error) {
+	client, err := ado.NewClient(options.Organization)
+	if err != nil {
+		return nil, err
+	}
+	projectName := strings.TrimSpace(options.Project)
+	repo := strings.TrimSpace(options.RepositoryID)
+	prID := strings.TrimSpace(options.PullRequestID)
+	if projectName == "" || repo == "" || prID == "" {
+		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
+	}
+	trimPath := strings.TrimSpace(options.FilePath)
+	if trimPath == "" || trimPath == "-" {
+		return nil, fmt.Errorf("filePath is required for suggestions")
+	}
+	normalized, err := ado.NormalizeADOFilePath(trimPath)
+	if err != nil {
+		return nil, err
+	}
+	if options.StartLine < 1 || options.EndLine < options.StartLine {
+		return nil, fmt.Errorf("startLine must be >= 1 and endLine must be >= startLine")
+	}
+	policy, err := NormalizeDuplicatePolicy(options.OnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+
+	commentOptions := CommentOptions{
+		Organization:  options.Organization,
+		Project:       projectName,
+		RepositoryID:  repo,
+		PullRequestID: prID,
+		IterationID:   options.IterationID,
+	}
+	requested := commentRange{Side: CommentSideRight, StartLine: options.StartLine, EndLine: options.EndLine}
+	position, err := resolveCommentPosition(commentOptions, normalized, requested, PositionModeNone, true)
+	if err != nil {
+		return nil, err
+	}
+
+	original := position.Lines[options.StartLine-1 : options.EndLine]
+	if options.ExpectedOriginal != nil {
+		if err := checkExpectedOriginal(position, *options.ExpectedOriginal, normalized); err != nil {
+			return nil, err
+		}
+	}
+	replacement := normalizeSuggestionText(options.Replacement)
+	if replacement == strings.Join(original, "\n") {
+		return nil, fmt.Errorf("replacement is identical to lines %s of %s in iteration %s", formatLineRange(requested), normalized, position.IterationID)
+	}
+
+	body := buildSuggestionBody(options.Comment, replacement)
+	payload := map[string]any{
+		"comments": []map[string]any{{
+			"parentCommentId": 0,
+			"content":         body,
+			"commentType":     "text",
+		}},
+		"status":                   "active",
+		"threadContext":            position.Range.threadContext(normalized),
+		"pullRequestThreadContext": buildPullRequestThreadContext(position),
+	}
+
+	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%s/threads?api-version=7.2-preview", client.EncodedOrg, url.PathEscape(projectName), url.PathEscape(repo), prID)
+	fingerprintText := body
+	if key := strings.TrimSpace(options.Key); key != "" && key != "-" {
+		fingerprintText = key
+	}
+	response, err := postFingerprintedThread(client, apiURL, payload, Fingerprint(suggestionCategory, normalized, fingerprintText), policy)
+	if err != nil {
+		return nil, err
+	}
+	response["suggestion"] = map[string]any{
+		"filePath":         normalized,
+		"range":            position.Range.toMap(),
+		"iterationId":      position.IterationID,
+		"changeTrackingId": position.ChangeTrackingID,
+		"originalLines":    original,
+		"replacementLines": strings.Split(replacement, "\n"),
+	}
+	return response, nil
+}
+
+// checkExpectedOriginal compares the lines a suggestion replaces with the text it was written
+// against, ignoring line endings, and returns an error with a diff when they differ.
+func checkExpectedOriginal(position commentPosition, expected, filePath string) error {
+	actual := make([]string, 0, position.Range.EndLine-position.Range.StartLine+1)
+	for _, line := range position.Lines[position.Range.StartLine-1 : position.Range.EndLine] {
+		actual = append(actual, strings.TrimSuffix(line, "\r"))
+	}
+	expectedLines := strings.Split(normalizeSuggestionText(expected), "\n")
+	if strings.Join(expectedLines, "\n") == strings.Join(actual, "\n") {
+		return nil
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "lines %s of %s in iteration %s do not match the expected original text:\n--- expected\n+++ iteration %s", formatLineRange(position.Range), filePath, position.IterationID, position.IterationID)
+	for _, hunk := range linediff.Compute(expectedLines, actual, len(expectedLines)+len(actual)) {
+		for _, line := range hunk.Lines {
+			prefix := " "
+			switch line.Kind {
+			case linediff.KindDeleted:
+				prefix = "-"
+			case linediff.KindAdded:
+				prefix = "+"
+			}
+			diff.WriteString("\n" + prefix + line.Text)
+		}
+	}
+	return fmt.Errorf("%s", diff.String())
+}
+
+func normalizeSuggestionText(text string) string {
+	normalized := strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.TrimSuffix(normalized, "\n")
+}
+
+func buildSuggestionBody(comment, replacement string) string {
+	fence := "```"
+	for strings.Contains(replacement, fence) {
+		fence += "`"
+	}
+
+	var body strings.Builder
+	if trimmed := strings.TrimSpace(comment); trimmed != "" && trimmed != "-" {
+		body.WriteString(trimmed)
+		body.WriteString("\n\n")
+	}
+	body.WriteString(fence)
+	body.WriteString("suggestion\n")
+	if replacement != "" {
+		body.WriteString(replacement)
+		body.WriteString("\n")
+	}
+	body.WriteString(fence)
+	return body.String()
+}
@@ -0,0 +1,49 @@
+package pullrequests
+
+import "testing"
+
+func TestBuildSuggestionBody(t *testing.T) {
+	got := buildSuggestionBody("Use a constant.", "const limit = 10;")
+	want := "Use a constant.\n\n```suggestion\nconst limit = 10;\n```"
+	if got != want {
+		t.Fatalf("unexpected body\nwant: %q\n got: %q", want, got)
+	}
+}
+
+func TestBuildSuggestionBody_DeletionAndNestedFence(t *testing.T) {
+	if got := buildSuggestionBody("", ""); got != "```suggestion\n```" {
+		t.Fatalf("unexpected deletion body: %q", got)
+	}
+
+	got := buildSuggestionBody("-", "// ```go\n// x := 1\n// ```")
+	want := "````suggestion\n// ```go\n// x := 1\n// ```\n````"
+	if got != want {
+		t.Fatalf("unexpected nested fence body\nwant: %q\n got: %q", want, got)
+	}
+}
+
+func TestNormalizeSuggestionText(t *testing.T) {
+	if got := normalizeSuggestionText("a\r\nb\r\n"); got != "a\nb" {
+		t.Fatalf("unexpected normalized text: %q", got)
+	}
+}
+
+func TestCheckExpectedOriginal(t *testing.T) {
+	position := commentPosition{
+		Range:       commentRange{Side: CommentSideRight, StartLine: 2, EndLine: 3},
+		IterationID: "4",
+		Lines:       []string{"package main", "var limit = 10\r", "var name = \"x\"", "func main() {}"},
+	}
+	if err := checkExpectedOriginal(position, "var limit = 10\r\nvar name = \"x\"\r\n", "/main.go"); err != nil {
+		t.Fatalf("expected matching text with different line endings to pass, got %v", err)
+	}
+
+	err := checkExpectedOriginal(position, "var limit = 5\nvar name = \"x\"", "/main.go")
+	if err == nil {
+		t.Fatal("expected a mismatch error")
+	}
+	want := "lines 2-3 of /main.go in iteration 4 do not match the expected original text:\n--- expected\n+++ iteration 4\n-var limit = 5\n+var limit = 10\n var name = \"x\""
+	if err.Error() != want {
+		t.Fatalf("unexpected error\nwant: %q\n got: %q", want, err.Error())
+	}
+}
@@ -0,0 +1,196 @@
+package pullrequests
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	ThreadClassHuman  = "human"
+	ThreadClassSystem = "system"
+	ThreadClassBot    = "bot"
+	ThreadClassSelf   = "self"
+
+	// BotIdentitiesEnv adds comma-separated bot identities (id, unique name or display name, with
+	// "*" wildcards) to DefaultBotIdentities.
+	BotIdentitiesEnv = "ADO_REVIEWER_BOT_IDENTITIES"
+	// ThreadPropertyRulesEnv adds comma-separated "propertyPattern=class" rules, where class is
+	// system or bot, to DefaultThreadPropertyRules. Later rules win over earlier ones.
+	ThreadPropertyRulesEnv = "ADO_REVIEWER_THREAD_PROPERTY_RULES"
+)
+
+// DefaultBotIdentities match common service accounts that post review comments from pipelines.
+var DefaultBotIdentities = []string{
+	"* Build Service (*)",
+	"Project Collection Build Service*",
+	"*dependabot*",
+	"*renovate*",
+	"SonarQube*",
+	"SonarCloud*",
+}
+
+// DefaultThreadPropertyRules mark Azure DevOps generated threads (vote, push and policy updates) as
+// system and fingerprinted findings posted by this tool under another identity as bot.
+var DefaultThreadPropertyRules = []ThreadPropertyRule{
+	{Pattern: "CodeReview*", Class: ThreadClassSystem},
+	{Pattern: FingerprintProperty, Class: ThreadClassBot},
+}
+
+var threadClasses = []string{ThreadClassHuman, ThreadClassSystem, ThreadClassBot, ThreadClassSelf}
+
+// ThreadPropertyRule classifies threads that carry a property whose name matches Pattern.
+type ThreadPropertyRule struct {
+	Pattern string
+	Class   string
+}
+
+// ThreadClassifier sorts threads into system, self, bot and human, in that order of precedence:
+// system rules first, then threads started by UserID, then bot rules, and human otherwise.
+type ThreadClassifier struct {
+	UserID        string
+	BotIdentities []string
+	PropertyRules []ThreadPropertyRule
+}
+
+// NewThreadClassifier returns the default rules extended by BotIdentitiesEnv and ThreadPropertyRulesEnv.
+// userID may be empty, in which case no thread is classified as self.
+func NewThreadClassifier(userID string) (ThreadClassifier, error) {
+	classifier := ThreadClassifier{
+		UserID:        strings.TrimSpace(userID),
+		BotIdentities: append([]string{}, DefaultBotIdentities...),
+		PropertyRules: append([]ThreadPropertyRule{}, DefaultThreadPropertyRules...),
+	}
+	for _, identity := range strings.Split(os.Getenv(BotIdentitiesEnv), ",") {
+		if identity = strings.TrimSpace(identity); identity != "" {
+			classifier.BotIdentities = append(classifier.BotIdentities, identity)
+		}
+	}
+	rules, err := ParseThreadPropertyRules(os.Getenv(ThreadPropertyRulesEnv))
+	if err != nil {
+		return ThreadClassifier{}, fmt.Errorf("%s: %w", ThreadPropertyRulesEnv, err)
+	}
+	classifier.PropertyRules = append(classifier.PropertyRules, rules...)
+	return classifier, nil
+}
+
+// ParseThreadPropertyRules parses "pattern=class" items separated by commas; class is system or bot.
+func ParseThreadPropertyRules(spec string) ([]ThreadPropertyRule, error) {
+	rules := make([]ThreadPropertyRule, 0)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, class, ok := strings.Cut(item, "=")
+		pattern = strings.TrimSpace(pattern)
+		class = strings.ToLower(strings.TrimSpace(class))
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid rule %q: use propertyPattern=class", item)
+		}
+		if class != ThreadClassSystem && class != ThreadClassBot {
+			return nil, fmt.Errorf("invalid rule %q: class must be system or bot", item)
+		}
+		rules = append(rules, ThreadPropertyRule{Pattern: pattern, Class: class})
+	}
+	return rules, nil
+}
+
+// ParseThreadClasses parses a comma-separated list of classes to keep; "" and "-" keep all.
+func ParseThreadClasses(spec string) ([]string, error) {
+	trimmed := strings.TrimSpace(spec)
+	if trimmed == "" || trimmed == "-" {
+		return nil, nil
+	}
+	values := make([]string, 0)
+	for _, item := range strings.Split(trimmed, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if !containsString(threadClasses, item) {
+			return nil, fmt.Errorf("classification must be a comma-separated list of: %s", strings.Join(threadClasses, ", "))
+		}
+		values = append(values, item)
+	}
+	return values, nil
+}
+
+// Classify returns the class of a thread.
+func (c ThreadClassifier) Classify(thread map[string]any) string {
+	propertyClass := c.propertyClass(thread)
+	if propertyClass == ThreadClassSystem || allSystemComments(thread) {
+		return ThreadClassSystem
+	}
+	first := firstThreadComment(thread)
+	if first == nil {
+		return ThreadClassHuman
+	}
+	if c.UserID != "" && identityMatches(first["author"], c.UserID) {
+		return ThreadClassSelf
+	}
+	if propertyClass == ThreadClassBot || c.isBot(first["author"]) {
+		return ThreadClassBot
+	}
+	return ThreadClassHuman
+}
+
+// propertyClass returns the class of the last rule matching any property of the thread.
+func (c ThreadClassifier) propertyClass(thread map[string]any) string {
+	properties, _ := thread["properties"].(map[string]any)
+	class := ""
+	for _, rule := range c.PropertyRules {
+		for name := range properties {
+			if wildcardMatch(rule.Pattern, name) {
+				class = rule.Class
+				break
+			}
+		}
+	}
+	return class
+}
+
+func (c ThreadClassifier) isBot(raw any) bool {
+	identity, _ := raw.(map[string]any)
+	for _, pattern := range c.BotIdentities {
+		for _, key := range []string{"id", "uniqueName", "displayName"} {
+			if value := shared.TrimmedString(identity[key]); value != "" && wildcardMatch(pattern, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches value against pattern case-insensitively, where "*" matches any run of characters.
+func wildcardMatch(pattern, value string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	value = strings.ToLower(value)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(value, part)
+		if index < 0 {
+			return false
+		}
+		value = value[index+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package pullrequests
+
+import "testing"
+
+func classificationThread(properties map[string]any, comments ...map[string]any) map[string]any {
+	items := make([]any, len(comments))
+	for index, comment := range comments {
+		items[index] = comment
+	}
+	return map[string]any{"properties": properties, "comments": items}
+}
+
+func commentBy(identity map[string]any) map[string]any {
+	return map[string]any{"content": "text", "author": identity}
+}
+
+func TestThreadClassifierClassify(t *testing.T) {
+	classifier := ThreadClassifier{
+		UserID:        "me-id",
+		BotIdentities: append([]string{"svc-lint@example.com"}, DefaultBotIdentities...),
+		PropertyRules: append([]ThreadPropertyRule{{Pattern: "Sonar.*", Class: ThreadClassBot}}, DefaultThreadPropertyRules...),
+	}
+	person := map[string]any{"id": "person-id", "displayName": "Riley"}
+	me := map[string]any{"id": "ME-ID", "displayName": "Me"}
+
+	tests := []struct {
+		name   string
+		thread map[string]any
+		want   string
+	}{
+		{name: "human", thread: classificationThread(nil, commentBy(person)), want: ThreadClassHuman},
+		{name: "self", thread: classificationThread(nil, commentBy(me), commentBy(person)), want: ThreadClassSelf},
+		{name: "vote update property", thread: classificationThread(map[string]any{"CodeReviewThreadType": map[string]any{"$value": "VoteUpdate"}}, commentBy(me)), want: ThreadClassSystem},
+		{name: "system comments", thread: classificationThread(nil, map[string]any{"commentType": "system", "author": person}, commentBy(map[string]any{"displayName": "Microsoft.VisualStudio.Services.TFS"})), want: ThreadClassSystem},
+		{name: "build service", thread: classificationThread(nil, commentBy(map[string]any{"displayName": "Contoso Build Service (contoso)"})), want: ThreadClassBot},
+		{name: "configured unique name", thread: classificationThread(nil, commentBy(map[string]any{"displayName": "Lint", "uniqueName": "SVC-LINT@example.com"})), want: ThreadClassBot},
+		{name: "bot property rule", thread: classificationThread(map[string]any{"Sonar.IssueKey": "abc"}, commentBy(person)), want: ThreadClassBot},
+		{name: "own fingerprint under another identity", thread: classificationThread(fingerprintProperties("v1:abc"), commentBy(person)), want: ThreadClassBot},
+		{name: "own fingerprint as me", thread: classificationThread(fingerprintProperties("v1:abc"), commentBy(me)), want: ThreadClassSelf},
+		{name: "no comments", thread: classificationThread(nil), want: ThreadClassHuman},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := classifier.Classify(testCase.thread); got != testCase.want {
+				t.Fatalf("expected %s, got %s", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestNewThreadClassifier_Environment(t *testing.T) {
+	t.Setenv(BotIdentitiesEnv, " review-bot@example.com , ")
+	t.Setenv(ThreadPropertyRulesEnv, "Lint.*=bot, CodeReviewReferences=bot")
+
+	classifier, err := NewThreadClassifier("me-id")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := classifier.Classify(classificationThread(nil, commentBy(map[string]any{"uniqueName": "review-bot@example.com"}))); got != ThreadClassBot {
+		t.Fatalf("expected configured bot identity, got %s", got)
+	}
+	if got := classifier.Classify(classificationThread(map[string]any{"CodeReviewReferences": "x"}, commentBy(map[string]any{"id": "person"}))); got != ThreadClassBot {
+		t.Fatalf("expected later rule to override the default system rule, got %s", got)
+	}
+
+	t.Setenv(ThreadPropertyRulesEnv, "Lint.*=robot")
+	if _, err := NewThreadClassifier(""); err == nil {
+		t.Fatalf("expected invalid rule error")
+	}
+}
+
+func TestParseThreadClasses(t *testing.T) {
+	classes, err := ParseThreadClasses(" Human, bot ")
+	if err != nil || len(classes) != 2 || classes[0] != ThreadClassHuman || classes[1] != ThreadClassBot {
+		t.Fatalf("unexpected classes: %v, %v", classes, err)
+	}
+	if classes, err := ParseThreadClasses("-"); err != nil || classes != nil {
+		t.Fatalf("expected no classes, got %v, %v", classes, err)
+	}
+	if _, err := ParseThreadClasses("human,robot"); err == nil {
+		t.Fatalf("expected invalid class error")
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"SonarQube*", "sonarqube scanner", true},
+		{"* Build Service (*)", "Contoso Build Service (contoso)", true},
+		{"* Build Service (*)", "Build Service", false},
+		{"*bot*", "dependabot[bot]", true},
+		{"exact", "EXACT", true},
+		{"exact", "exactly", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+	}
+	for _, testCase := range tests {
+		if got := wildcardMatch(testCase.pattern, testCase.value); got != testCase.want {
+			t.Fatalf("wildcardMatch(%q, %q) = %v, want %v", testCase.pattern, testCase.value, got, testCase.want)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	// AuthorMe stands for the authenticated identity in ThreadFilter.Author.
+	AuthorMe = "me"
+
+	LastCommentByAuthor   = "author"
+	LastCommentByReviewer = "reviewer"
+
+	compactExcerptRunes = 160
+)
+
+// ThreadFilter narrows the threads returned by GetFilteredThreads. Zero values disable a filter.
+type ThreadFilter struct {
+	Status        string
+	ExcludeSystem bool
+	// Author matches the thread's first comment by identity id, unique name or display name,
+	// case-insensitively. AuthorMe matches the authenticated identity.
+	Author string
+	// PathGlob matches the thread's file path using files.CompileGlob; general threads never match.
+	PathGlob string
+	// Iteration keeps threads anchored to this iteration (the second comparing iteration).
+	Iteration    int
+	CreatedSince time.Time
+	UpdatedSince time.Time
+	// HasReplies keeps threads with (true) or without (false) human replies after the first comment.
+	HasReplies *bool
+	// LastCommentBy keeps threads whose last human comment is by the PR author or by someone else.
+	LastCommentBy string
+	// Compact replaces each thread with a short projection to keep agent context small.
+	Compact bool
+	// Classes keeps threads whose classification (human, system, bot or self) is listed.
+	Classes []string
+}
+
+// NormalizeLastCommentBy validates the last-comment-by filter; "" and "-" disable it.
+func NormalizeLastCommentBy(value string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	switch normalized {
+	case "", "-":
+		return "", nil
+	case LastCommentByAuthor, LastCommentByReviewer:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("lastCommentBy must be one of: author, reviewer")
+	}
+}
+
+// ParseThreadTime parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC); "" and "-" mean no bound.
+func ParseThreadTime(value string) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "-" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, trimmed); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.Parse("2006-01-02", trimmed); err == nil {
+		return parsed, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 (2024-05-01T12:00:00Z) or YYYY-MM-DD", value)
+}
+
+// GetFilteredThreads lists the comment threads of a pull request that pass filter, each with its
+// classification. The PR author is only looked up when the filter needs it, and the authenticated
+// identity when author is "me", a class filter needs it, or a thread that is not a system thread is
+// returned. When that lookup fails without being required, threads are returned unclassified.
+func GetFilteredThreads(organization, project, repositoryID, pullRequestID string, filter ThreadFilter) (map[string]any, error) {
+	client, err := ado.NewClient(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	projectName := strings.TrimSpace(project)
+	repo := strings.TrimSpace(repositoryID)
+	prID := strings.TrimSpace(pullRequestID)
+	if projectName == "" || repo == "" || prID == "" {
+		return nil, fmt.Errorf("organization, project, repositoryId and pullRequestId are required")
+	}
+
+	matcher, err := newThreadMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+	matcher.classifier, err = NewThreadClassifier("")
+	if err != nil {
+		return nil, err
+	}
+	userLookedUp := false
+	var userErr error
+	lookUpUser := func() {
+		if userLookedUp {
+			return
+		}
+		userLookedUp = true
+		matcher.classifier.UserID, userErr = client.GetAuthenticatedUserID()
+	}
+	// Author "me" and class filters other than system cannot be applied without the identity.
+	if strings.EqualFold(matcher.author, AuthorMe) || classesNeedSelf(filter.Classes) {
+		if lookUpUser(); userErr != nil {
+			return nil, userErr
+		}
+	}
+	if strings.EqualFold(matcher.author, AuthorMe) {
+		matcher.author = matcher.classifier.UserID
+	}
+	if matcher.filter.LastCommentBy != "" {
+		details, err := GetDetails(organization, projectName, repo, prID)
+		if err != nil {
+			return nil, err
+		}
+		createdBy, _ := details["createdBy"].(map[string]any)
+		matcher.prAuthorID = shared.TrimmedString(createdBy["id"])
+	}
+
+	response, err := fetchThreads(client, projectName, repo, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	rawThreads, _ := response["value"].([]any)
+	filtered := make([]any, 0, len(rawThreads))
+	for _, t := range rawThreads {
+		thread, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		class := matcher.classifier.Classify(thread)
+		if !matcher.matches(thread, class) {
+			continue
+		}
+		// Without a class filter the identity only decides between self and human or bot, so it is
+		// looked up once the first such thread is returned.
+		if class != ThreadClassSystem && !userLookedUp {
+			lookUpUser()
+			class = matcher.classifier.Classify(thread)
+		}
+		if filter.Compact {
+			thread = compactThread(thread, class)
+		}
+		thread["classification"] = class
+		filtered = append(filtered, thread)
+	}
+	response["value"] = filtered
+	response["count"] = len(filtered)
+	if userErr != nil {
+		for _, thread := range filtered {
+			delete(thread.(map[string]any), "classification")
+		}
+		response["classificationWarning"] = "authenticated user could not be resolved, so threads are returned without classification: " + userErr.Error()
+	}
+	return response, nil
+}
+
+// classesNeedSelf reports whether a classification filter depends on telling self threads apart,
+// which is the case for every class but system.
+func classesNeedSelf(classes []string) bool {
+	for _, class := range classes {
+		if class != ThreadClassSystem {
+			return true
+		}
+	}
+	return false
+}
+
+func fetchThreads(client *ado.Client, project, repositoryID, pullRequestID string) (map[string]any, error) {
+	apiURL := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%s/threads?api-version=7.2-preview", client.EncodedOrg, url.PathEscape(project), url.PathEscape(repositoryID), pullRequestID)
+	response := map[string]any{}
+	if err := client.GetJSON(apiURL, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+type threadMatcher struct {
+	filter     ThreadFilter
+	classifier ThreadClassifier
+	glob       *files.Glob
+	author     string
+	prAuthorID string
+}
+
+func newThreadMatcher(filter ThreadFilter) (*threadMatcher, error) {
+	filter.Status = strings.TrimSpace(filter.Status)
+	if filter.Status == "-" {
+		filter.Status = ""
+	}
+	lastCommentBy, err := NormalizeLastCommentBy(filter.LastCommentBy)
+	if err != nil {
+		return nil, err
+	}
+	filter.LastCommentBy = lastCommentBy
+	if filter.Iteration < 0 {
+		return nil, fmt.Errorf("iteration must be a positive integer")
+	}
+
+	matcher := &threadMatcher{filter: filter, author: strings.TrimSpace(filter.Author)}
+	if matcher.author == "-" {
+		matcher.author = ""
+	}
+	if pattern := strings.TrimSpace(filter.PathGlob); pattern != "" && pattern != "-" {
+		glob, err := files.CompileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matcher.glob = glob
+	}
+	return matcher, nil
+}
+
+// matches reports whether a thread passes the filter; class is the thread's classification.
+func (m *threadMatcher) matches(thread map[string]any, class string) bool {
+	filter := m.filter
+	if filter.ExcludeSystem && class == ThreadClassSystem {
+		return false
+	}
+	if len(filter.Classes) > 0 && !containsString(filter.Classes, class) {
+		return false
+	}
+	if filter.Status != "" && shared.TrimmedString(thread["status"]) != filter.Status {
+		return false
+	}
+	if m.author != "" {
+		first := firstThreadComment(thread)
+		if first == nil || !identityMatches(first["author"], m.author) {
+			return false
+		}
+	}
+	if m.glob != nil {
+		filePath, _ := threadPosition(thread)
+		if filePath == "" || !m.glob.Match(filePath) {
+			return false
+		}
+	}
+	if filter.Iteration > 0 && threadIteration(thread) != filter.Iteration {
+		return false
+	}
+	if !filter.CreatedSince.IsZero() && !threadTimeAtOrAfter(thread["publishedDate"], filter.CreatedSince) {
+		return false
+	}
+	if !filter.UpdatedSince.IsZero() && !threadTimeAtOrAfter(thread["lastUpdatedDate"], filter.UpdatedSince) {
+		return false
+	}
+
+	comments := humanComments(thread)
+	if filter.HasReplies != nil && (len(comments) > 1) != *filter.HasReplies {
+		return false
+	}
+	if filter.LastCommentBy != "" {
+		if len(comments) == 0 || m.prAuthorID == "" {
+			return false
+		}
+		byAuthor := identityMatches(comments[len(comments)-1]["author"], m.prAuthorID)
+		if byAuthor != (filter.LastCommentBy == LastCommentByAuthor) {
+			return false
+		}
+	}
+	return true
+}
+
+// identityMatches compares an identity object with an id, unique name or display name.
+func identityMatches(raw any, value string) bool {
+	identity, _ := raw.(map[string]any)
+	for _, key := range []string{"id", "uniqueName", "displayName"} {
+		if candidate := shared.TrimmedString(identity[key]); candidate != "" && strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// humanComments returns the non-deleted comments that are not system messages, in thread order.
+func humanComments(thread map[string]any) []map[string]any {
+	raw, _ := thread["comments"].([]any)
+	comments := make([]map[string]any, 0, len(raw))
+	for _, item := range raw {
+		comment, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if deleted, _ := comment["isDeleted"].(bool); deleted {
+			continue
+		}
+		if shared.TrimmedString(comment["commentType"]) == "system" {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// threadPosition returns the file path and line a thread is anchored to, preferring the right side.
+func threadPosition(thread map[string]any) (string, int) {
+	context, _ := thread["threadContext"].(map[string]any)
+	filePath := shared.TrimmedString(context["filePath"])
+	for _, key := range []string{"rightFileStart", "leftFileStart"} {
+		if position, ok := context[key].(map[string]any); ok {
+			if line, ok := position["line"].(float64); ok && line > 0 {
+				return filePath, int(line)
+			}
+		}
+	}
+	return filePath, 0
+}
+
+func threadIteration(thread map[string]any) int {
+	context, _ := thread["pullRequestThreadContext"].(map[string]any)
+	iterationContext, _ := context["iterationContext"].(map[string]any)
+	iteration, _ := iterationContext["secondComparingIteration"].(float64)
+	return int(iteration)
+}
+
+func threadTimeAtOrAfter(raw any, bound time.Time) bool {
+	parsed, err := time.Parse(time.RFC3339, shared.TrimmedString(raw))
+	if err != nil {
+		return false
+	}
+	return !parsed.Before(bound)
+}
+
+// compactThread projects a thread to id, status, position, author and an excerpt of its last comment;
+// class is the thread's classification.
+func compactThread(thread map[string]any, class string) map[string]any {
+	filePath, line := threadPosition(thread)
+	comments := humanComments(thread)
+	compact := map[string]any{
+		"id":           toBundleInt(thread["id"]),
+		"status":       shared.TrimmedString(thread["status"]),
+		"commentCount": len(comments),
+		"isSystem":     class == ThreadClassSystem,
+	}
+	if filePath != "" {
+		compact["filePath"] = filePath
+	}
+	if line > 0 {
+		compact["line"] = line
+	}
+	if first := firstThreadComment(thread); first != nil {
+		compact["author"] = identityName(first["author"])
+	}
+	if len(comments) > 0 {
+		last := comments[len(comments)-1]
+		compact["lastComment"] = map[string]any{
+			"id":            toBundleInt(last["id"]),
+			"author":        identityName(last["author"]),
+			"publishedDate": shared.TrimmedString(last["publishedDate"]),
+			"excerpt":       excerpt(shared.TrimmedString(last["content"]), compactExcerptRunes),
+		}
+	}
+	return compact
+}
+
+func identityName(raw any) string {
+	identity, _ := raw.(map[string]any)
+	if name := shared.TrimmedString(identity["displayName"]); name != "" {
+		return name
+	}
+	return shared.TrimmedString(identity["uniqueName"])
+}
+
+// excerpt collapses whitespace and cuts text to at most limit runes, marking the cut with "…".
+func excerpt(text string, limit int) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(collapsed) <= limit {
+		return collapsed
+	}
+	runes := []rune(collapsed)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
@@ -0,0 +1,167 @@
+package pullrequests
+
+import (
+	"testing"
+	"time"
+)
+
+func filterTestThreads() []map[string]any {
+	return []map[string]any{
+		{
+			"id":              float64(1),
+			"status":          "active",
+			"publishedDate":   "2024-05-01T10:00:00.123Z",
+			"lastUpdatedDate": "2024-05-03T09:00:00Z",
+			"threadContext": map[string]any{
+				"filePath":       "/src/app/main.go",
+				"rightFileStart": map[string]any{"line": float64(12), "offset": float64(1)},
+			},
+			"pullRequestThreadContext": map[string]any{
+				"iterationContext": map[string]any{"firstComparingIteration": float64(1), "secondComparingIteration": float64(2)},
+			},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Handle the error here.", "author": map[string]any{"id": "reviewer-id", "displayName": "Riley", "uniqueName": "riley@example.com"}},
+				map[string]any{"id": float64(2), "content": "Done,   see   the next push.", "publishedDate": "2024-05-03T09:00:00Z", "author": map[string]any{"id": "author-id", "displayName": "Avery"}},
+			},
+		},
+		{
+			"id":              float64(2),
+			"status":          "fixed",
+			"publishedDate":   "2024-04-20T10:00:00Z",
+			"lastUpdatedDate": "2024-04-21T10:00:00Z",
+			"threadContext":   map[string]any{"filePath": "/docs/readme.md", "leftFileStart": map[string]any{"line": float64(3)}},
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Typo.", "author": map[string]any{"id": "author-id", "displayName": "Avery"}},
+			},
+		},
+		{
+			"id":              float64(3),
+			"status":          "active",
+			"publishedDate":   "2024-05-02T10:00:00Z",
+			"lastUpdatedDate": "2024-05-02T10:00:00Z",
+			"comments": []any{
+				map[string]any{"id": float64(1), "content": "Overall question.", "author": map[string]any{"id": "reviewer-id", "displayName": "Riley", "uniqueName": "riley@example.com"}},
+				map[string]any{"id": float64(2), "content": "Deleted reply", "isDeleted": true, "author": map[string]any{"id": "author-id"}},
+				map[string]any{"id": float64(3), "content": "Policy updated", "commentType": "system", "author": map[string]any{"displayName": "Microsoft.VisualStudio.Services.TFS"}},
+			},
+		},
+	}
+}
+
+func matchingThreadIDs(t *testing.T, filter ThreadFilter, prAuthorID string) []int {
+	t.Helper()
+	matcher, err := newThreadMatcher(filter)
+	if err != nil {
+		t.Fatalf("newThreadMatcher: %v", err)
+	}
+	matcher.prAuthorID = prAuthorID
+	matcher.classifier = ThreadClassifier{UserID: "reviewer-id", PropertyRules: DefaultThreadPropertyRules}
+	ids := []int{}
+	for _, thread := range filterTestThreads() {
+		if matcher.matches(thread, matcher.classifier.Classify(thread)) {
+			ids = append(ids, toBundleInt(thread["id"]))
+		}
+	}
+	return ids
+}
+
+func TestThreadMatcher(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter ThreadFilter
+		want   []int
+	}{
+		{name: "no filter", filter: ThreadFilter{}, want: []int{1, 2, 3}},
+		{name: "status placeholder", filter: ThreadFilter{Status: "-"}, want: []int{1, 2, 3}},
+		{name: "status", filter: ThreadFilter{Status: "fixed"}, want: []int{2}},
+		{name: "author by unique name", filter: ThreadFilter{Author: "RILEY@example.com"}, want: []int{1, 3}},
+		{name: "author by id", filter: ThreadFilter{Author: "author-id"}, want: []int{2}},
+		{name: "path glob", filter: ThreadFilter{PathGlob: "/src/**/*.go"}, want: []int{1}},
+		{name: "basename glob", filter: ThreadFilter{PathGlob: "*.md"}, want: []int{2}},
+		{name: "iteration", filter: ThreadFilter{Iteration: 2}, want: []int{1}},
+		{name: "created since", filter: ThreadFilter{CreatedSince: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}, want: []int{1, 3}},
+		{name: "updated since", filter: ThreadFilter{UpdatedSince: time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)}, want: []int{1}},
+		{name: "has replies", filter: ThreadFilter{HasReplies: &yes}, want: []int{1}},
+		{name: "no replies", filter: ThreadFilter{HasReplies: &no}, want: []int{2, 3}},
+		{name: "last comment by author", filter: ThreadFilter{LastCommentBy: "author"}, want: []int{1, 2}},
+		{name: "last comment by reviewer", filter: ThreadFilter{LastCommentBy: "Reviewer"}, want: []int{3}},
+		{name: "classification self", filter: ThreadFilter{Classes: []string{ThreadClassSelf}}, want: []int{1, 3}},
+		{name: "classification human", filter: ThreadFilter{Classes: []string{ThreadClassHuman, ThreadClassBot}}, want: []int{2}},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := matchingThreadIDs(t, testCase.filter, "author-id")
+			if len(got) != len(testCase.want) {
+				t.Fatalf("expected %v, got %v", testCase.want, got)
+			}
+			for index := range got {
+				if got[index] != testCase.want[index] {
+					t.Fatalf("expected %v, got %v", testCase.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewThreadMatcher_Invalid(t *testing.T) {
+	if _, err := newThreadMatcher(ThreadFilter{LastCommentBy: "someone"}); err == nil || err.Error() != "lastCommentBy must be one of: author, reviewer" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := newThreadMatcher(ThreadFilter{PathGlob: "/src/[a"}); err == nil {
+		t.Fatalf("expected invalid glob error")
+	}
+}
+
+func TestParseThreadTime(t *testing.T) {
+	for _, value := range []string{"", "-"} {
+		if parsed, err := ParseThreadTime(value); err != nil || !parsed.IsZero() {
+			t.Fatalf("expected zero time for %q, got %v, %v", value, parsed, err)
+		}
+	}
+	if parsed, err := ParseThreadTime("2024-05-01"); err != nil || !parsed.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date parse: %v, %v", parsed, err)
+	}
+	if parsed, err := ParseThreadTime("2024-05-01T12:30:00+02:00"); err != nil || !parsed.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected timestamp parse: %v, %v", parsed, err)
+	}
+	if _, err := ParseThreadTime("yesterday"); err == nil {
+		t.Fatalf("expected error for invalid time")
+	}
+}
+
+func TestCompactThread(t *testing.T) {
+	threads := filterTestThreads()
+	compact := compactThread(threads[0], ThreadClassHuman)
+	if compact["id"] != 1 || compact["status"] != "active" || compact["filePath"] != "/src/app/main.go" || compact["line"] != 12 {
+		t.Fatalf("unexpected compact thread: %#v", compact)
+	}
+	if compact["author"] != "Riley" || compact["commentCount"] != 2 || compact["isSystem"] != false {
+		t.Fatalf("unexpected author or count: %#v", compact)
+	}
+	last, _ := compact["lastComment"].(map[string]any)
+	if last["author"] != "Avery" || last["excerpt"] != "Done, see the next push." || last["id"] != 2 {
+		t.Fatalf("unexpected last comment: %#v", last)
+	}
+
+	general := compactThread(threads[2], ThreadClassHuman)
+	if _, ok := general["filePath"]; ok {
+		t.Fatalf("general thread should have no filePath: %#v", general)
+	}
+	if last, _ := general["lastComment"].(map[string]any); last["excerpt"] != "Overall question." {
+		t.Fatalf("expected deleted and system comments to be skipped: %#v", general)
+	}
+	if system := compactThread(threads[2], ThreadClassSystem); system["isSystem"] != true {
+		t.Fatalf("expected isSystem to follow the classification: %#v", system)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("short", 10); got != "short" {
+		t.Fatalf("unexpected excerpt %q", got)
+	}
+	if got := excerpt("ééééé ééééé", 6); got != "ééééé…" {
+		t.Fatalf("unexpected excerpt %q", got)
+	}
+}
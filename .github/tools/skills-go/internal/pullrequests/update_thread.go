@@ -8,7 +8,8 @@ import (
 	"ado-reviewer/.github/tools/skills-go/internal/ado"
 )
 
-func UpdateThread(organization, project, repositoryID, pullRequestID, threadID, reply, status string) (map[string]any, error) {
+// UpdateThread posts a reply to parentCommentID (the thread's first comment when <= 0) and/or sets the thread status.
+func UpdateThread(organization, project, repositoryID, pullRequestID, threadID, reply, status string, parentCommentID int) (map[string]any, error) {
 	client, err := ado.NewClient(organization)
 	if err != nil {
 		return nil, err
@@ -25,6 +26,12 @@ func UpdateThread(organization, project, repositoryID, pullRequestID, threadID,
 	if rep == "-" {
 		rep = ""
 	}
+	if st == "-" {
+		st = ""
+	}
+	if parentCommentID <= 0 {
+		parentCommentID = 1
+	}
 	if rep == "" && st == "" {
 		return nil, fmt.Errorf("at least one of reply or status must be provided")
 	}
@@ -33,7 +40,7 @@ func UpdateThread(organization, project, repositoryID, pullRequestID, threadID,
 	result := map[string]any{}
 	if rep != "" {
 		commentURL := baseURL + "/comments?api-version=7.2-preview"
-		replyPayload := map[string]any{"content": rep, "parentCommentId": 1, "commentType": "text"}
+		replyPayload := map[string]any{"content": rep, "parentCommentId": parentCommentID, "commentType": "text"}
 		replyResponse := map[string]any{}
 		if err := client.PostJSON(commentURL, replyPayload, &replyResponse); err != nil {
 			return nil, err
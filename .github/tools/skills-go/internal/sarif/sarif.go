@@ -0,0 +1,278 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+	LevelNone    = "none"
+)
+
+// Log is the subset of a SARIF 2.1.0 log used to import and export review findings.
+type Log struct {
+	Schema  string `json:"$schema,omitempty"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	Results            []Result                    `json:"results"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Properties         map[string]any              `json:"properties,omitempty"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules,omitempty"`
+}
+
+type Rule struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name,omitempty"`
+	ShortDescription     *Message       `json:"shortDescription,omitempty"`
+	FullDescription      *Message       `json:"fullDescription,omitempty"`
+	HelpURI              string         `json:"helpUri,omitempty"`
+	Help                 *Message       `json:"help,omitempty"`
+	DefaultConfiguration *Configuration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]any `json:"properties,omitempty"`
+}
+
+type Configuration struct {
+	Level string `json:"level,omitempty"`
+}
+
+type Message struct {
+	Text     string `json:"text,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type Result struct {
+	RuleID              string            `json:"ruleId,omitempty"`
+	RuleIndex           *int              `json:"ruleIndex,omitempty"`
+	Rule                *RuleReference    `json:"rule,omitempty"`
+	Level               string            `json:"level,omitempty"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Fingerprints        map[string]string `json:"fingerprints,omitempty"`
+	Suppressions        []Suppression     `json:"suppressions,omitempty"`
+	BaselineState       string            `json:"baselineState,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type Suppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status,omitempty"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type RuleReference struct {
+	ID    string `json:"id,omitempty"`
+	Index *int   `json:"index,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri,omitempty"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type Region struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// Parse reads a SARIF 2.1.0 log.
+func Parse(data []byte) (Log, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return Log{}, fmt.Errorf("SARIF file is empty")
+	}
+	var log Log
+	if err := json.Unmarshal(trimmed, &log); err != nil {
+		return Log{}, fmt.Errorf("invalid SARIF file: %w", err)
+	}
+	if log.Version != Version {
+		return Log{}, fmt.Errorf("unsupported SARIF version %q: expected %s", log.Version, Version)
+	}
+	return log, nil
+}
+
+// RuleFor returns the rule a result refers to, by index first and then by id, or nil.
+func (r Run) RuleFor(result Result) *Rule {
+	index := result.RuleIndex
+	if index == nil && result.Rule != nil {
+		index = result.Rule.Index
+	}
+	if index != nil && *index >= 0 && *index < len(r.Tool.Driver.Rules) {
+		return &r.Tool.Driver.Rules[*index]
+	}
+	id := result.EffectiveRuleID()
+	for position := range r.Tool.Driver.Rules {
+		if r.Tool.Driver.Rules[position].ID == id && id != "" {
+			return &r.Tool.Driver.Rules[position]
+		}
+	}
+	return nil
+}
+
+// EffectiveRuleID returns ruleId, falling back to rule.id.
+func (r Result) EffectiveRuleID() string {
+	if id := strings.TrimSpace(r.RuleID); id != "" {
+		return id
+	}
+	if r.Rule != nil {
+		return strings.TrimSpace(r.Rule.ID)
+	}
+	return ""
+}
+
+// EffectiveLevel returns the result level, falling back to the rule's default level and then to
+// warning, as the SARIF specification does.
+func (r Run) EffectiveLevel(result Result) string {
+	if level := strings.ToLower(strings.TrimSpace(result.Level)); level != "" {
+		return level
+	}
+	if rule := r.RuleFor(result); rule != nil && rule.DefaultConfiguration != nil {
+		if level := strings.ToLower(strings.TrimSpace(rule.DefaultConfiguration.Level)); level != "" {
+			return level
+		}
+	}
+	return LevelWarning
+}
+
+// SecuritySeverity returns the "security-severity" score (0-10) set by CodeQL-style tools on the
+// result or its rule, and whether one was found.
+func (r Run) SecuritySeverity(result Result) (float64, bool) {
+	if score, ok := securitySeverity(result.Properties); ok {
+		return score, true
+	}
+	if rule := r.RuleFor(result); rule != nil {
+		return securitySeverity(rule.Properties)
+	}
+	return 0, false
+}
+
+func securitySeverity(properties map[string]any) (float64, bool) {
+	switch value := properties["security-severity"].(type) {
+	case string:
+		score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return score, err == nil
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// IsSuppressed reports whether a result was suppressed in source or is absent from the baseline.
+func (r Result) IsSuppressed() bool {
+	return len(r.Suppressions) > 0 || strings.EqualFold(r.BaselineState, "absent")
+}
+
+// StableKey identifies a result across runs: its partial fingerprints when the tool provides them
+// (they survive line moves), otherwise its message text.
+func (r Result) StableKey() string {
+	for _, fingerprints := range []map[string]string{r.PartialFingerprints, r.Fingerprints} {
+		if len(fingerprints) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(fingerprints))
+		for name := range fingerprints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, name+"="+fingerprints[name])
+		}
+		return strings.Join(parts, ";")
+	}
+	return r.Message.Text
+}
+
+// PrimaryLocation returns the first physical location of a result, or nil.
+func (r Result) PrimaryLocation() *PhysicalLocation {
+	for _, location := range r.Locations {
+		if location.PhysicalLocation != nil {
+			return location.PhysicalLocation
+		}
+	}
+	return nil
+}
+
+// ResolveURI returns the URI of location with its uriBaseId resolved through the run's
+// originalUriBaseIds, following bases that are themselves relative to another base. A base the run
+// does not define (such as an unset %SRCROOT%) is left unresolved, so the URI stays relative.
+func (r Run) ResolveURI(location ArtifactLocation) string {
+	uri := strings.TrimSpace(location.URI)
+	baseID := strings.TrimSpace(location.URIBaseID)
+	seen := map[string]bool{}
+	for baseID != "" && !seen[baseID] && !isAbsoluteURI(uri) {
+		seen[baseID] = true
+		base, ok := r.OriginalURIBaseIDs[baseID]
+		if !ok {
+			break
+		}
+		if prefix := strings.TrimSpace(base.URI); prefix != "" {
+			uri = strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(uri, "./")
+		}
+		baseID = strings.TrimSpace(base.URIBaseID)
+	}
+	return uri
+}
+
+// isAbsoluteURI reports whether uri has a scheme of more than one letter (one letter is a Windows
+// drive) or starts at a root.
+func isAbsoluteURI(uri string) bool {
+	if strings.HasPrefix(uri, "/") {
+		return true
+	}
+	parsed, err := url.Parse(uri)
+	return err == nil && len(parsed.Scheme) > 1
+}
+
+// URIPath turns an artifact URI into a slash-separated path: file:// URIs are reduced to their
+// path and percent-encoding is decoded. Relative URIs stay relative.
+func URIPath(uri string) string {
+	trimmed := strings.TrimSpace(uri)
+	if strings.HasPrefix(strings.ToLower(trimmed), "file:") {
+		if parsed, err := url.Parse(trimmed); err == nil {
+			trimmed = parsed.Path
+			if parsed.Opaque != "" {
+				trimmed = parsed.Opaque
+			}
+		}
+	} else if decoded, err := url.PathUnescape(trimmed); err == nil {
+		trimmed = decoded
+	}
+	return strings.ReplaceAll(trimmed, "\\", "/")
+}
@@ -0,0 +1,110 @@
+package sarif
+
+import "testing"
+
+const sampleLog = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "CodeQL", "rules": [
+      {"id": "go/sql-injection", "shortDescription": {"text": "Database query built from user-controlled sources"}, "helpUri": "https://codeql.github.com/sql", "properties": {"security-severity": "8.8"}},
+      {"id": "go/unused", "defaultConfiguration": {"level": "note"}}
+    ]}},
+    "results": [
+      {"ruleId": "go/sql-injection", "ruleIndex": 0, "message": {"text": "query depends on input"}, "partialFingerprints": {"primaryLocationLineHash": "abc:1"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/db.go"}, "region": {"startLine": 12}}}]},
+      {"rule": {"id": "go/unused"}, "message": {"text": "unused variable"}, "suppressions": [{"kind": "inSource"}]}
+    ]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	log, err := Parse([]byte("\xef\xbb\xbf" + sampleLog))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected runs: %+v", log.Runs)
+	}
+
+	if _, err := Parse([]byte(`{"version": "2.0.0", "runs": []}`)); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+	if _, err := Parse([]byte("  ")); err == nil {
+		t.Fatal("expected an error for an empty file")
+	}
+}
+
+func TestRunResultHelpers(t *testing.T) {
+	log, err := Parse([]byte(sampleLog))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := log.Runs[0]
+	first, second := run.Results[0], run.Results[1]
+
+	if rule := run.RuleFor(second); rule == nil || rule.ID != "go/unused" {
+		t.Fatalf("expected rule lookup by rule.id, got %+v", rule)
+	}
+	if second.EffectiveRuleID() != "go/unused" {
+		t.Fatalf("unexpected rule id %q", second.EffectiveRuleID())
+	}
+	if level := run.EffectiveLevel(second); level != LevelNote {
+		t.Fatalf("expected the rule's default level, got %q", level)
+	}
+	if level := run.EffectiveLevel(first); level != LevelWarning {
+		t.Fatalf("expected warning by default, got %q", level)
+	}
+	if score, ok := run.SecuritySeverity(first); !ok || score != 8.8 {
+		t.Fatalf("expected security severity 8.8, got %v %v", score, ok)
+	}
+	if first.IsSuppressed() || !second.IsSuppressed() {
+		t.Fatal("expected only the second result to be suppressed")
+	}
+	if key := first.StableKey(); key != "primaryLocationLineHash=abc:1" {
+		t.Fatalf("unexpected stable key %q", key)
+	}
+	if key := second.StableKey(); key != "unused variable" {
+		t.Fatalf("expected the message as stable key, got %q", key)
+	}
+	if location := first.PrimaryLocation(); location == nil || location.Region.StartLine != 12 {
+		t.Fatalf("unexpected primary location %+v", location)
+	}
+}
+
+func TestURIPath(t *testing.T) {
+	tests := map[string]string{
+		"src/app.go":                          "src/app.go",
+		"src/my%20file.go":                    "src/my file.go",
+		"file:///home/agent/_work/1/s/app.go": "/home/agent/_work/1/s/app.go",
+		"file:///C:/agent/_work/1/s/App.cs":   "/C:/agent/_work/1/s/App.cs",
+		`src\Windows\Path.cs`:                 "src/Windows/Path.cs",
+	}
+	for uri, want := range tests {
+		if got := URIPath(uri); got != want {
+			t.Errorf("URIPath(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestRunResolveURI(t *testing.T) {
+	run := Run{OriginalURIBaseIDs: map[string]ArtifactLocation{
+		"AGENT":   {URI: "file:///home/agent/_work/"},
+		"SRCROOT": {URI: "1/s", URIBaseID: "AGENT"},
+		"LOOP":    {URI: "x/", URIBaseID: "LOOP"},
+	}}
+	tests := []struct {
+		location ArtifactLocation
+		want     string
+	}{
+		{location: ArtifactLocation{URI: "src/app.go"}, want: "src/app.go"},
+		{location: ArtifactLocation{URI: "./src/app.go", URIBaseID: "SRCROOT"}, want: "file:///home/agent/_work/1/s/src/app.go"},
+		{location: ArtifactLocation{URI: "src/app.go", URIBaseID: "%SRCROOT%"}, want: "src/app.go"},
+		{location: ArtifactLocation{URI: "file:///tmp/app.go", URIBaseID: "SRCROOT"}, want: "file:///tmp/app.go"},
+		{location: ArtifactLocation{URI: "app.go", URIBaseID: "LOOP"}, want: "x/app.go"},
+	}
+	for _, testCase := range tests {
+		if got := run.ResolveURI(testCase.location); got != testCase.want {
+			t.Errorf("ResolveURI(%+v) = %q, want %q", testCase.location, got, testCase.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"ado-reviewer/.github/tools/skills-go/internal/ado"
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+const (
+	DefaultTop          = 25
+	MaxTop              = 1000
+	DefaultContextLines = 2
+
+	maxParallelSnippetRequests = 6
+)
+
+// CodeOptions narrows a code search. Repositories, Paths and Branches accept several values;
+// Repositories and Branches require Project to be set, as the Search API does.
+type CodeOptions struct {
+	Project      string
+	Repositories []string
+	Paths        []string
+	Branches     []string
+	Skip         int
+	Top          int
+	// ContextLines is the number of lines shown around each match; negative disables snippets.
+	ContextLines int
+}
+
+type codeSearchResponse struct {
+	Count   int              `json:"count"`
+	Results []map[string]any `json:"results"`
+}
+
+// Code runs an Azure DevOps code search and returns one result per matching file. When snippets are
+// enabled each file is fetched once at the indexed version and matches are shown as numbered lines.
+func Code(organization, searchText string, options CodeOptions) (map[string]any, error) {
+	client, err := ado.NewClient(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(searchText)
+	if query == "" {
+		return nil, fmt.Errorf("searchText is required")
+	}
+	project := strings.TrimSpace(options.Project)
+	if project == "" && (len(options.Repositories) > 0 || len(options.Branches) > 0) {
+		return nil, fmt.Errorf("project is required when filtering by repository or branch")
+	}
+	if options.Skip < 0 {
+		return nil, fmt.Errorf("skip must be a non-negative integer")
+	}
+	if options.Top == 0 {
+		options.Top = DefaultTop
+	}
+	if options.Top < 0 || options.Top > MaxTop {
+		return nil, fmt.Errorf("top must be between 1 and %d", MaxTop)
+	}
+
+	filters := map[string][]string{}
+	if project != "" {
+		filters["Project"] = []string{project}
+	}
+	if len(options.Repositories) > 0 {
+		filters["Repository"] = options.Repositories
+	}
+	if len(options.Paths) > 0 {
+		filters["Path"] = options.Paths
+	}
+	if len(options.Branches) > 0 {
+		filters["Branch"] = options.Branches
+	}
+
+	body := map[string]any{
+		"searchText":    query,
+		"$skip":         options.Skip,
+		"$top":          options.Top,
+		"includeFacets": false,
+	}
+	if len(filters) > 0 {
+		body["filters"] = filters
+	}
+
+	// Code search is served from almsearch.dev.azure.com and is not available in 7.2-preview.
+	apiURL := fmt.Sprintf("https://almsearch.dev.azure.com/%s/_apis/search/codesearchresults?api-version=7.1", client.EncodedOrg)
+	if project != "" {
+		apiURL = fmt.Sprintf("https://almsearch.dev.azure.com/%s/%s/_apis/search/codesearchresults?api-version=7.1", client.EncodedOrg, url.PathEscape(project))
+	}
+
+	var response codeSearchResponse
+	if err := client.PostJSON(apiURL, body, &response); err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, len(response.Results))
+	for index, raw := range response.Results {
+		results[index] = projectCodeResult(raw)
+	}
+	if options.ContextLines >= 0 {
+		attachSnippets(organization, results, response.Results, options.ContextLines)
+	}
+
+	output := map[string]any{
+		"searchText": query,
+		"filters":    filters,
+		"skip":       options.Skip,
+		"top":        options.Top,
+		"count":      response.Count,
+		"returned":   len(results),
+		"results":    results,
+	}
+	if next := options.Skip + len(results); len(results) > 0 && next < response.Count {
+		output["nextSkip"] = next
+	}
+	return output, nil
+}
+
+func projectCodeResult(raw map[string]any) map[string]any {
+	result := map[string]any{
+		"path":       shared.TrimmedString(raw["path"]),
+		"fileName":   shared.TrimmedString(raw["fileName"]),
+		"matchCount": len(contentMatches(raw)),
+	}
+	if project, ok := raw["project"].(map[string]any); ok {
+		result["project"] = shared.TrimmedString(project["name"])
+	}
+	if repository, ok := raw["repository"].(map[string]any); ok {
+		result["repository"] = shared.TrimmedString(repository["name"])
+		result["repositoryId"] = shared.TrimmedString(repository["id"])
+	}
+	if versions, ok := raw["versions"].([]any); ok && len(versions) > 0 {
+		if version, ok := versions[0].(map[string]any); ok {
+			result["branch"] = shared.TrimmedString(version["branchName"])
+			result["commitId"] = shared.TrimmedString(version["changeId"])
+		}
+	}
+	return result
+}
+
+// contentMatches returns the character offsets of matches in file content (not in the file name).
+func contentMatches(raw map[string]any) []int {
+	matches, _ := raw["matches"].(map[string]any)
+	hits, _ := matches["content"].([]any)
+	offsets := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		entry, ok := hit.(map[string]any)
+		if !ok {
+			continue
+		}
+		if offset, ok := entry["charOffset"].(float64); ok && offset >= 0 {
+			offsets = append(offsets, int(offset))
+		}
+	}
+	return offsets
+}
+
+func attachSnippets(organization string, results []map[string]any, raws []map[string]any, contextLines int) {
+	semaphore := make(chan struct{}, maxParallelSnippetRequests)
+	var wg sync.WaitGroup
+
+	for index := range results {
+		offsets := contentMatches(raws[index])
+		if len(offsets) == 0 {
+			continue
+		}
+		result := results[index]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			version, versionType := shared.TrimmedString(result["commitId"]), "commit"
+			if version == "" {
+				version, versionType = strings.TrimPrefix(shared.TrimmedString(result["branch"]), "refs/heads/"), "branch"
+			}
+			content, err := files.GetContentLimited(organization, shared.TrimmedString(result["project"]), shared.TrimmedString(result["repositoryId"]), shared.TrimmedString(result["path"]), version, versionType, 0)
+			if err != nil {
+				result["snippetError"] = err.Error()
+				return
+			}
+			text, _ := content["content"].(string)
+			ranges := matchLineRanges(text, offsets, contextLines)
+			entry := map[string]any{"content": text, "isBinary": content["isBinary"]}
+			files.ApplySnippets(entry, ranges)
+			if snippets, ok := entry["snippets"]; ok {
+				result["matchLines"] = matchLines(text, offsets)
+				result["snippets"] = snippets
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func matchLineRanges(text string, offsets []int, contextLines int) []files.LineRange {
+	lines := matchLines(text, offsets)
+	if len(lines) == 0 {
+		return nil
+	}
+	ranges := make([]files.LineRange, 0, len(lines))
+	for _, line := range lines {
+		start := line - contextLines
+		if start < 1 {
+			start = 1
+		}
+		ranges = append(ranges, files.LineRange{Start: start, End: line + contextLines})
+	}
+	return files.MergeLineRanges(ranges)
+}
+
+// matchLines converts character offsets to distinct 1-based line numbers, in ascending order.
+func matchLines(text string, offsets []int) []int {
+	seen := map[int]bool{}
+	lines := make([]int, 0, len(offsets))
+	for _, offset := range offsets {
+		line := lineAtCharOffset(text, offset)
+		if line == 0 || seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// lineAtCharOffset returns the 1-based line holding the character at offset, or 0 when the offset is
+// past the end of text. Offsets count characters, not bytes.
+func lineAtCharOffset(text string, offset int) int {
+	line := 1
+	for position := 0; position < offset; position++ {
+		if text == "" {
+			return 0
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		if r == '\n' {
+			line++
+		}
+		text = text[size:]
+	}
+	if text == "" {
+		return 0
+	}
+	return line
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+)
+
+func TestCode_Validation(t *testing.T) {
+	t.Setenv("ADO_PAT_testorg", "token")
+
+	if _, err := Code("testorg", " ", CodeOptions{}); err == nil || err.Error() != "searchText is required" {
+		t.Fatalf("expected searchText validation error, got: %v", err)
+	}
+	if _, err := Code("testorg", "Load", CodeOptions{Repositories: []string{"repo"}}); err == nil || err.Error() != "project is required when filtering by repository or branch" {
+		t.Fatalf("expected project validation error, got: %v", err)
+	}
+	if _, err := Code("testorg", "Load", CodeOptions{Top: MaxTop + 1}); err == nil {
+		t.Fatalf("expected top validation error")
+	}
+}
+
+func TestProjectCodeResult(t *testing.T) {
+	raw := map[string]any{
+		"fileName":   "client.go",
+		"path":       "/pkg/client.go",
+		"project":    map[string]any{"name": "Platform", "id": "p1"},
+		"repository": map[string]any{"name": "api", "id": "r1", "type": "git"},
+		"versions":   []any{map[string]any{"branchName": "main", "changeId": "abc123"}},
+		"matches": map[string]any{
+			"content":  []any{map[string]any{"charOffset": float64(10), "length": float64(4)}, map[string]any{"charOffset": float64(40), "length": float64(4)}},
+			"fileName": []any{map[string]any{"charOffset": float64(0), "length": float64(6)}},
+		},
+	}
+
+	got := projectCodeResult(raw)
+	want := map[string]any{
+		"path":         "/pkg/client.go",
+		"fileName":     "client.go",
+		"matchCount":   2,
+		"project":      "Platform",
+		"repository":   "api",
+		"repositoryId": "r1",
+		"branch":       "main",
+		"commitId":     "abc123",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected projection:\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	text := "package a\n\nfunc Load() {}\n// é Load\nvar x = Load()\n"
+
+	if line := lineAtCharOffset(text, 0); line != 1 {
+		t.Fatalf("expected offset 0 on line 1, got %d", line)
+	}
+	if line := lineAtCharOffset(text, 16); line != 3 {
+		t.Fatalf("expected offset 16 on line 3, got %d", line)
+	}
+	if line := lineAtCharOffset(text, 31); line != 4 {
+		t.Fatalf("expected character offsets to count runes, got line %d", line)
+	}
+	if line := lineAtCharOffset(text, 500); line != 0 {
+		t.Fatalf("expected offset past the end to return 0, got %d", line)
+	}
+
+	lines := matchLines(text, []int{44, 16, 31, 17, 500})
+	if !reflect.DeepEqual(lines, []int{3, 4, 5}) {
+		t.Fatalf("unexpected match lines: %v", lines)
+	}
+
+	ranges := matchLineRanges(text, []int{16, 44}, 1)
+	if !reflect.DeepEqual(ranges, []files.LineRange{{Start: 2, End: 6}}) {
+		t.Fatalf("expected overlapping context to merge, got %#v", ranges)
+	}
+}
@@ -0,0 +1,152 @@
+package structdiff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+
+	OpAdded   = "added"
+	OpRemoved = "removed"
+	OpChanged = "changed"
+)
+
+type Change struct {
+	Path string `json:"path"`
+	Op   string `json:"op"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// FormatOf returns the structured format of a file path, or "" when the file is neither JSON nor YAML.
+func FormatOf(filePath string) string {
+	switch fileclass.ClassifyPath(filePath).Language {
+	case "json":
+		return FormatJSON
+	case "yaml":
+		return FormatYAML
+	default:
+		return ""
+	}
+}
+
+func Parse(format, content string) (any, error) {
+	switch format {
+	case FormatJSON:
+		return ParseJSON(content)
+	case FormatYAML:
+		return ParseYAML(content)
+	default:
+		return nil, fmt.Errorf("unsupported structured format '%s'", format)
+	}
+}
+
+// CompareContent parses both versions and returns their key-path changes.
+// A missing version (empty content) is treated as an absent document.
+func CompareContent(format, baseContent, prContent string) ([]Change, error) {
+	var base, pr any
+	if strings.TrimSpace(baseContent) != "" {
+		parsed, err := Parse(format, baseContent)
+		if err != nil {
+			return nil, fmt.Errorf("base version: %w", err)
+		}
+		base = parsed
+	}
+	if strings.TrimSpace(prContent) != "" {
+		parsed, err := Parse(format, prContent)
+		if err != nil {
+			return nil, fmt.Errorf("pr version: %w", err)
+		}
+		pr = parsed
+	}
+	return Compare(base, pr), nil
+}
+
+// Compare walks two decoded documents and reports added, removed and changed values keyed by JSON pointer.
+// Objects are compared by key, so reordering keys produces no changes; arrays are compared by index.
+func Compare(base, pr any) []Change {
+	changes := make([]Change, 0)
+	compareValues("", base, pr, &changes)
+	return changes
+}
+
+func compareValues(pointer string, base, pr any, changes *[]Change) {
+	baseMap, baseIsMap := base.(map[string]any)
+	prMap, prIsMap := pr.(map[string]any)
+	if baseIsMap && prIsMap {
+		keys := make([]string, 0, len(baseMap)+len(prMap))
+		for key := range baseMap {
+			keys = append(keys, key)
+		}
+		for key := range prMap {
+			if _, exists := baseMap[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := pointer + "/" + escapePointer(key)
+			baseValue, inBase := baseMap[key]
+			prValue, inPR := prMap[key]
+			switch {
+			case !inPR:
+				*changes = append(*changes, Change{Path: child, Op: OpRemoved, Old: baseValue})
+			case !inBase:
+				*changes = append(*changes, Change{Path: child, Op: OpAdded, New: prValue})
+			default:
+				compareValues(child, baseValue, prValue, changes)
+			}
+		}
+		return
+	}
+
+	baseList, baseIsList := base.([]any)
+	prList, prIsList := pr.([]any)
+	if baseIsList && prIsList {
+		for index := 0; index < len(baseList) || index < len(prList); index++ {
+			child := pointer + "/" + strconv.Itoa(index)
+			switch {
+			case index >= len(prList):
+				*changes = append(*changes, Change{Path: child, Op: OpRemoved, Old: baseList[index]})
+			case index >= len(baseList):
+				*changes = append(*changes, Change{Path: child, Op: OpAdded, New: prList[index]})
+			default:
+				compareValues(child, baseList[index], prList[index], changes)
+			}
+		}
+		return
+	}
+
+	switch {
+	case base == nil && pr == nil:
+	case base == nil && pointer == "":
+		*changes = append(*changes, Change{Path: pointer, Op: OpAdded, New: pr})
+	case pr == nil && pointer == "":
+		*changes = append(*changes, Change{Path: pointer, Op: OpRemoved, Old: base})
+	case !reflect.DeepEqual(base, pr):
+		*changes = append(*changes, Change{Path: pointer, Op: OpChanged, Old: base, New: pr})
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func (c Change) ToMap() map[string]any {
+	entry := map[string]any{"path": c.Path, "op": c.Op}
+	if c.Op != OpAdded {
+		entry["old"] = c.Old
+	}
+	if c.Op != OpRemoved {
+		entry["new"] = c.New
+	}
+	return entry
+}
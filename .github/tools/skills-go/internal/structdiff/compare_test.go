@@ -0,0 +1,53 @@
+package structdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompareContent_JSONIgnoresKeyOrder(t *testing.T) {
+	base := `{
+  // comment allowed in appsettings.json
+  "Logging": {"Level": "Information"},
+  "Http": {"Timeout": 30, "Retries": [1, 2]},
+  "Old/Key": true,
+}`
+	pr := `{"Http": {"Retries": [1, 2, 3], "Timeout": 300}, "Logging": {"Level": "Information"}, "Feature": "on"}`
+
+	changes, err := CompareContent(FormatJSON, base, pr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Change{
+		{Path: "/Feature", Op: OpAdded, New: "on"},
+		{Path: "/Http/Retries/2", Op: OpAdded, New: json.Number("3")},
+		{Path: "/Http/Timeout", Op: OpChanged, Old: json.Number("30"), New: json.Number("300")},
+		{Path: "/Old~1Key", Op: OpRemoved, Old: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes:\n got %#v\nwant %#v", changes, want)
+	}
+}
+
+func TestCompareContent_ParseError(t *testing.T) {
+	if _, err := CompareContent(FormatJSON, `{"a": 1}`, `{"a": `); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestCompare_RootAddedWhenBaseMissing(t *testing.T) {
+	changes := Compare(nil, map[string]any{"a": "b"})
+	if len(changes) != 1 || changes[0].Path != "" || changes[0].Op != OpAdded {
+		t.Fatalf("unexpected changes: %#v", changes)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{"/appsettings.json": FormatJSON, "/charts/values.yaml": FormatYAML, "/azure-pipelines.yml": FormatYAML, "/main.go": ""}
+	for path, want := range tests {
+		if got := FormatOf(path); got != want {
+			t.Fatalf("FormatOf(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package structdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseJSON decodes a JSON document, keeping numbers as json.Number.
+// Comments and trailing commas (as found in appsettings.json and tsconfig.json) are removed first.
+func ParseJSON(content string) (any, error) {
+	cleaned := removeTrailingCommas(stripJSONComments(strings.TrimPrefix(content, "\ufeff")))
+	decoder := json.NewDecoder(bytes.NewReader([]byte(cleaned)))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected content after JSON document")
+	}
+	return value, nil
+}
+
+// scanJSON copies string literals verbatim and calls emit for every byte outside them.
+// emit writes its own output and returns how many extra bytes it consumed.
+func scanJSON(content string, emit func(index int, builder *strings.Builder) int) string {
+	var builder strings.Builder
+	builder.Grow(len(content))
+	inString := false
+	for index := 0; index < len(content); index++ {
+		char := content[index]
+		if inString {
+			builder.WriteByte(char)
+			if char == '\\' && index+1 < len(content) {
+				index++
+				builder.WriteByte(content[index])
+			} else if char == '"' {
+				inString = false
+			}
+			continue
+		}
+		if char == '"' {
+			inString = true
+			builder.WriteByte(char)
+			continue
+		}
+		index += emit(index, &builder)
+	}
+	return builder.String()
+}
+
+func stripJSONComments(content string) string {
+	return scanJSON(content, func(index int, builder *strings.Builder) int {
+		rest := content[index:]
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return len(rest) - 1
+			}
+			return end - 1
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return len(rest) - 1
+			}
+			builder.WriteByte(' ')
+			return end + 3
+		default:
+			builder.WriteByte(content[index])
+			return 0
+		}
+	})
+}
+
+func removeTrailingCommas(content string) string {
+	return scanJSON(content, func(index int, builder *strings.Builder) int {
+		if content[index] == ',' {
+			next := strings.TrimLeft(content[index+1:], " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				return 0
+			}
+		}
+		builder.WriteByte(content[index])
+		return 0
+	})
+}
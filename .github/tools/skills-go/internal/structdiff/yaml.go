@@ -0,0 +1,563 @@
+package structdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML reader covers the subset found in configuration files: block mappings and sequences,
+// flow collections, plain and quoted scalars, literal and folded block scalars, comments and
+// multiple documents. Anchors, aliases, merge keys and complex keys are reported as errors.
+
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlHexPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlOctalPattern = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+type yamlLine struct {
+	number int
+	indent int
+	raw    string
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// ParseYAML decodes a YAML document into maps, slices and scalars (numbers as json.Number).
+// A stream with several documents decodes to a slice with one element per document.
+func ParseYAML(content string) (any, error) {
+	documents := make([]any, 0, 1)
+	for _, lines := range splitYAMLDocuments(content) {
+		parser := &yamlParser{lines: lines}
+		parser.skipBlank()
+		if parser.pos >= len(lines) {
+			continue
+		}
+		value, err := parser.parseBlock(parser.lines[parser.pos].indent)
+		if err != nil {
+			return nil, err
+		}
+		parser.skipBlank()
+		if parser.pos < len(lines) {
+			return nil, fmt.Errorf("unexpected content at line %d", lines[parser.pos].number)
+		}
+		documents = append(documents, value)
+	}
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
+	default:
+		return documents, nil
+	}
+}
+
+func splitYAMLDocuments(content string) [][]yamlLine {
+	content = strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\ufeff")
+	documents := make([][]yamlLine, 0, 1)
+	current := make([]yamlLine, 0)
+	for index, raw := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if raw == "---" || strings.HasPrefix(raw, "--- ") || raw == "..." {
+			if len(current) > 0 {
+				documents = append(documents, current)
+			}
+			current = make([]yamlLine, 0)
+			continue
+		}
+		if strings.HasPrefix(raw, "%") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		current = append(current, yamlLine{
+			number: index + 1,
+			indent: indent,
+			raw:    raw,
+			text:   strings.TrimSpace(stripYAMLComment(trimmed)),
+		})
+	}
+	if len(current) > 0 {
+		documents = append(documents, current)
+	}
+	return documents
+}
+
+func stripYAMLComment(text string) string {
+	var quote byte
+	for index := 0; index < len(text); index++ {
+		char := text[index]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			if index == 0 || strings.ContainsRune(" \t[{,:-", rune(text[index-1])) {
+				quote = char
+			}
+		case char == '#':
+			if index == 0 || text[index-1] == ' ' || text[index-1] == '\t' {
+				return text[:index]
+			}
+		}
+	}
+	return text
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+		return nil, nil
+	}
+	line := p.lines[p.pos]
+	if isSequenceItem(line.text) {
+		return p.parseSequence(line.indent)
+	}
+	if _, _, ok, err := splitMappingEntry(line.text); err != nil {
+		return nil, fmt.Errorf("line %d: %w", line.number, err)
+	} else if ok {
+		return p.parseMapping(line.indent)
+	}
+	p.pos++
+	return p.parseInlineValue(line.text, line.indent-1, line.number)
+}
+
+func (p *yamlParser) parseSequence(indent int) (any, error) {
+	items := make([]any, 0)
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			break
+		}
+		line := p.lines[p.pos]
+		if line.indent != indent || !isSequenceItem(line.text) {
+			break
+		}
+		rest := strings.TrimLeft(line.text[1:], " ")
+		column := indent + len(line.text) - len(rest)
+
+		var value any
+		var err error
+		switch {
+		case rest == "":
+			p.pos++
+			value, err = p.parseBlock(indent + 1)
+		case isSequenceItem(rest) || isMappingLine(rest):
+			p.lines[p.pos].indent = column
+			p.lines[p.pos].text = rest
+			value, err = p.parseBlock(column)
+		default:
+			p.pos++
+			value, err = p.parseInlineValue(rest, indent, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (any, error) {
+	result := map[string]any{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			break
+		}
+		line := p.lines[p.pos]
+		if line.indent != indent || isSequenceItem(line.text) {
+			break
+		}
+		key, rest, ok, err := splitMappingEntry(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a mapping key", line.number)
+		}
+		if key == "<<" {
+			return nil, fmt.Errorf("line %d: merge keys are not supported", line.number)
+		}
+		if _, duplicate := result[key]; duplicate {
+			return nil, fmt.Errorf("line %d: duplicate key '%s'", line.number, key)
+		}
+		p.pos++
+
+		var value any
+		if rest == "" {
+			p.skipBlank()
+			if p.pos < len(p.lines) {
+				next := p.lines[p.pos]
+				if next.indent > indent {
+					value, err = p.parseBlock(next.indent)
+				} else if next.indent == indent && isSequenceItem(next.text) {
+					value, err = p.parseSequence(indent)
+				}
+			}
+		} else {
+			value, err = p.parseInlineValue(rest, indent, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseInlineValue decodes the value written after a key or sequence dash. Block scalars, flow
+// collections and plain scalars may continue on following lines indented deeper than parentIndent.
+func (p *yamlParser) parseInlineValue(text string, parentIndent, lineNumber int) (any, error) {
+	if strings.HasPrefix(text, "!") {
+		_, rest, _ := strings.Cut(text, " ")
+		text = strings.TrimSpace(rest)
+		if text == "" {
+			return p.parseBlock(parentIndent + 1)
+		}
+	}
+	switch {
+	case strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*"):
+		return nil, fmt.Errorf("line %d: anchors and aliases are not supported", lineNumber)
+	case strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">"):
+		return p.parseBlockScalar(text, parentIndent), nil
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{"):
+		for !flowBalanced(text) && p.pos < len(p.lines) {
+			text += " " + p.lines[p.pos].text
+			p.pos++
+		}
+		value, err := parseFlow(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'"):
+		return parseYAMLScalar(text), nil
+	}
+
+	for p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.text == "" || next.indent <= parentIndent || isSequenceItem(next.text) || isMappingLine(next.text) {
+			break
+		}
+		text += " " + next.text
+		p.pos++
+	}
+	return parseYAMLScalar(text), nil
+}
+
+func (p *yamlParser) parseBlockScalar(indicator string, parentIndent int) string {
+	folded := strings.HasPrefix(indicator, ">")
+	chomping := ""
+	if strings.Contains(indicator, "-") {
+		chomping = "-"
+	} else if strings.Contains(indicator, "+") {
+		chomping = "+"
+	}
+
+	lines := make([]string, 0)
+	contentIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		blank := strings.TrimSpace(line.raw) == ""
+		if !blank && line.indent <= parentIndent {
+			break
+		}
+		if !blank && contentIndent < 0 {
+			contentIndent = line.indent
+		}
+		if blank {
+			lines = append(lines, "")
+		} else if line.indent >= contentIndent {
+			lines = append(lines, line.raw[contentIndent:])
+		} else {
+			lines = append(lines, strings.TrimLeft(line.raw, " "))
+		}
+		p.pos++
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	body := lines[:len(lines)-trailing]
+
+	var builder strings.Builder
+	for index, line := range body {
+		if index > 0 {
+			if folded && line != "" && body[index-1] != "" && !strings.HasPrefix(line, " ") {
+				builder.WriteByte(' ')
+			} else {
+				builder.WriteByte('\n')
+			}
+		}
+		builder.WriteString(line)
+	}
+	value := builder.String()
+	switch chomping {
+	case "-":
+		return value
+	case "+":
+		return value + "\n" + strings.Repeat("\n", trailing)
+	default:
+		if value == "" {
+			return ""
+		}
+		return value + "\n"
+	}
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMappingLine(text string) bool {
+	_, _, ok, err := splitMappingEntry(text)
+	return ok && err == nil
+}
+
+// splitMappingEntry splits "key: value" outside quotes and flow brackets.
+func splitMappingEntry(text string) (string, string, bool, error) {
+	if strings.HasPrefix(text, "? ") {
+		return "", "", false, fmt.Errorf("complex mapping keys are not supported")
+	}
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", false, nil
+		}
+		rest := text[end+1:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") && !strings.HasPrefix(rest, ":\t") {
+			return "", "", false, nil
+		}
+		key, _ := parseYAMLScalar(text[:end+1]).(string)
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false, nil
+	}
+	for index := 0; index < len(text); index++ {
+		if text[index] != ':' {
+			continue
+		}
+		if index+1 == len(text) || text[index+1] == ' ' || text[index+1] == '\t' {
+			key := strings.TrimSpace(text[:index])
+			if key == "" {
+				return "", "", false, nil
+			}
+			return key, strings.TrimSpace(text[index+1:]), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+func closingQuote(text string) int {
+	quote := text[0]
+	for index := 1; index < len(text); index++ {
+		switch {
+		case quote == '"' && text[index] == '\\':
+			index++
+		case quote == '\'' && text[index] == '\'' && index+1 < len(text) && text[index+1] == '\'':
+			index++
+		case text[index] == quote:
+			return index
+		}
+	}
+	return -1
+}
+
+func parseYAMLScalar(text string) any {
+	text = strings.TrimSpace(text)
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			return unquoted
+		}
+		return text[1 : len(text)-1]
+	}
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	switch {
+	case yamlIntPattern.MatchString(text):
+		if parsed, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(parsed, 10))
+		}
+	case yamlHexPattern.MatchString(text):
+		if parsed, err := strconv.ParseInt(text[2:], 16, 64); err == nil {
+			return json.Number(strconv.FormatInt(parsed, 10))
+		}
+	case yamlOctalPattern.MatchString(text):
+		if parsed, err := strconv.ParseInt(text[2:], 8, 64); err == nil {
+			return json.Number(strconv.FormatInt(parsed, 10))
+		}
+	case yamlFloatPattern.MatchString(text):
+		if parsed, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(strconv.FormatFloat(parsed, 'g', -1, 64))
+		}
+	}
+	return text
+}
+
+func flowBalanced(text string) bool {
+	depth := 0
+	var quote byte
+	for index := 0; index < len(text); index++ {
+		char := text[index]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func parseFlow(text string) (any, error) {
+	reader := &flowReader{text: text}
+	value, err := reader.value()
+	if err != nil {
+		return nil, err
+	}
+	reader.skipSpaces()
+	if reader.pos < len(reader.text) {
+		return nil, fmt.Errorf("unexpected '%s' after flow collection", reader.text[reader.pos:])
+	}
+	return value, nil
+}
+
+type flowReader struct {
+	text string
+	pos  int
+}
+
+func (r *flowReader) skipSpaces() {
+	for r.pos < len(r.text) && (r.text[r.pos] == ' ' || r.text[r.pos] == '\t') {
+		r.pos++
+	}
+}
+
+func (r *flowReader) value() (any, error) {
+	r.skipSpaces()
+	if r.pos >= len(r.text) {
+		return nil, fmt.Errorf("unterminated flow collection")
+	}
+	switch r.text[r.pos] {
+	case '[':
+		r.pos++
+		items := make([]any, 0)
+		for {
+			r.skipSpaces()
+			if r.pos < len(r.text) && r.text[r.pos] == ']' {
+				r.pos++
+				return items, nil
+			}
+			item, err := r.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if err := r.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		r.pos++
+		result := map[string]any{}
+		for {
+			r.skipSpaces()
+			if r.pos < len(r.text) && r.text[r.pos] == '}' {
+				r.pos++
+				return result, nil
+			}
+			keyValue := parseYAMLScalar(r.token(":,}"))
+			key := fmt.Sprint(keyValue)
+			if keyValue == nil {
+				key = ""
+			}
+			r.skipSpaces()
+			var value any
+			if r.pos < len(r.text) && r.text[r.pos] == ':' {
+				r.pos++
+				parsed, err := r.value()
+				if err != nil {
+					return nil, err
+				}
+				value = parsed
+			}
+			result[key] = value
+			if err := r.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return parseYAMLScalar(r.token(",]}")), nil
+	}
+}
+
+// token reads a quoted scalar or a plain scalar up to one of the stop characters.
+func (r *flowReader) token(stops string) string {
+	r.skipSpaces()
+	start := r.pos
+	if r.pos < len(r.text) && (r.text[r.pos] == '"' || r.text[r.pos] == '\'') {
+		if end := closingQuote(r.text[r.pos:]); end >= 0 {
+			r.pos += end + 1
+			return r.text[start:r.pos]
+		}
+	}
+	for ; r.pos < len(r.text); r.pos++ {
+		char := r.text[r.pos]
+		if char == ':' && r.pos+1 < len(r.text) && !strings.ContainsRune(" \t,]}", rune(r.text[r.pos+1])) {
+			continue
+		}
+		if strings.ContainsRune(stops, rune(char)) {
+			break
+		}
+	}
+	return strings.TrimSpace(r.text[start:r.pos])
+}
+
+func (r *flowReader) separator(closing byte) error {
+	r.skipSpaces()
+	if r.pos >= len(r.text) {
+		return fmt.Errorf("unterminated flow collection")
+	}
+	switch r.text[r.pos] {
+	case ',':
+		r.pos++
+		return nil
+	case closing:
+		return nil
+	default:
+		return fmt.Errorf("unexpected '%c' in flow collection", r.text[r.pos])
+	}
+}
@@ -0,0 +1,99 @@
+package structdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML_BlockStructures(t *testing.T) {
+	content := `# Helm values
+replicaCount: 3
+image:
+  repository: "nginx"   # quoted
+  tag: '1.25'
+  pullPolicy: IfNotPresent
+resources: {limits: {cpu: 500m, memory: 1Gi}}
+env:
+  - name: MODE
+    value: production
+  - plain item
+ports: [80, 443]
+enabled: true
+empty:
+script: |
+  echo one
+  echo two
+summary: >-
+  folded
+  text
+url: http://example.com:8080/path
+`
+	value, err := ParseYAML(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"replicaCount": json.Number("3"),
+		"image":        map[string]any{"repository": "nginx", "tag": "1.25", "pullPolicy": "IfNotPresent"},
+		"resources":    map[string]any{"limits": map[string]any{"cpu": "500m", "memory": "1Gi"}},
+		"env": []any{
+			map[string]any{"name": "MODE", "value": "production"},
+			"plain item",
+		},
+		"ports":   []any{json.Number("80"), json.Number("443")},
+		"enabled": true,
+		"empty":   nil,
+		"script":  "echo one\necho two\n",
+		"summary": "folded text",
+		"url":     "http://example.com:8080/path",
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Fatalf("unexpected value:\n got %#v\nwant %#v", value, want)
+	}
+}
+
+func TestParseYAML_PipelineSequencesAtKeyIndent(t *testing.T) {
+	content := "trigger:\n- main\nsteps:\n- script: go test ./...\n  displayName: Test\n- task: PublishBuildArtifacts@1\n  inputs:\n    pathToPublish: out\n"
+	value, err := ParseYAML(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"trigger": []any{"main"},
+		"steps": []any{
+			map[string]any{"script": "go test ./...", "displayName": "Test"},
+			map[string]any{"task": "PublishBuildArtifacts@1", "inputs": map[string]any{"pathToPublish": "out"}},
+		},
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Fatalf("unexpected value:\n got %#v\nwant %#v", value, want)
+	}
+}
+
+func TestParseYAML_MultipleDocuments(t *testing.T) {
+	value, err := ParseYAML("---\na: 1\n---\nb: 2\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	documents, ok := value.([]any)
+	if !ok || len(documents) != 2 {
+		t.Fatalf("expected two documents, got %#v", value)
+	}
+}
+
+func TestParseYAML_RejectsAnchors(t *testing.T) {
+	if _, err := ParseYAML("base: &base\n  a: 1\nother: *base\n"); err == nil {
+		t.Fatalf("expected anchors to be rejected")
+	}
+}
+
+func TestCompareContent_YAMLTimeoutChange(t *testing.T) {
+	changes, err := CompareContent(FormatYAML, "server:\n  timeout: 30\n  host: a\n", "server:\n  host: a\n  timeout: 300\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/server/timeout" || changes[0].Old != json.Number("30") || changes[0].New != json.Number("300") {
+		t.Fatalf("unexpected changes: %#v", changes)
+	}
+}
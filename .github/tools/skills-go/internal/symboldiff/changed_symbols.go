@@ -0,0 +1,137 @@
+package symboldiff
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+	"ado-reviewer/.github/tools/skills-go/internal/files"
+	"ado-reviewer/.github/tools/skills-go/internal/pullrequests"
+	"ado-reviewer/.github/tools/skills-go/internal/shared"
+)
+
+func GetPRChangedSymbols(organization, project, repositoryID, pullRequestID, iterationID string) (map[string]any, error) {
+	prDetails, err := pullrequests.GetDetails(organization, project, repositoryID, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	sourceBranch := strings.TrimPrefix(shared.TrimmedString(prDetails["sourceRefName"]), "refs/heads/")
+	targetBranch := strings.TrimPrefix(shared.TrimmedString(prDetails["targetRefName"]), "refs/heads/")
+
+	changes, err := pullrequests.GetChanges(organization, project, repositoryID, pullRequestID, iterationID)
+	if err != nil {
+		return nil, err
+	}
+	projected := pullrequests.ProjectChangedFiles(changes, pullRequestID, iterationID)
+	changedFiles, _ := projected["files"].([]map[string]any)
+
+	goFiles := make([]map[string]any, 0, len(changedFiles))
+	blobs := make([]files.ChangedBlob, 0, len(changedFiles))
+	for _, fileEntry := range changedFiles {
+		if isFolder, _ := fileEntry["isFolder"].(bool); isFolder {
+			continue
+		}
+		path := shared.TrimmedString(fileEntry["path"])
+		if !strings.HasSuffix(path, ".go") || fileclass.ClassifyPath(path).Vendored {
+			continue
+		}
+		goFiles = append(goFiles, fileEntry)
+		blobs = append(blobs, files.ChangedBlobFromEntry(fileEntry))
+	}
+
+	baseByPath := map[string]string{}
+	prByPath := map[string]string{}
+	fetchStats := files.FetchStats{}
+	if len(blobs) > 0 {
+		baseByPath, prByPath, fetchStats = files.GetChangedContents(organization, project, repositoryID, blobs, targetBranch, sourceBranch)
+	}
+
+	// Files are compared per package (directory, with _test.go files apart), so declarations moved
+	// between changed files of the same package are matched.
+	packages := map[string][]SourceFile{}
+	for _, fileEntry := range goFiles {
+		path := shared.TrimmedString(fileEntry["path"])
+		packageKey := packageKeyOf(path)
+		packages[packageKey] = append(packages[packageKey], SourceFile{Path: path, Base: baseByPath[path], PR: prByPath[path]})
+	}
+	changesByPath := map[string][]Change{}
+	parseErrors := map[string]error{}
+	for _, sources := range packages {
+		packageChanges, packageErrors := CompareGoPackage(sources)
+		for _, change := range packageChanges {
+			changesByPath[change.File] = append(changesByPath[change.File], change)
+		}
+		for path, err := range packageErrors {
+			parseErrors[path] = err
+		}
+	}
+
+	totals := map[string]int{ChangeAdded: 0, ChangeRemoved: 0, ChangeModified: 0, "breaking": 0}
+	results := make([]map[string]any, 0, len(goFiles))
+	for _, fileEntry := range goFiles {
+		path := shared.TrimmedString(fileEntry["path"])
+		_, baseExists := baseByPath[path]
+		prContent, prExists := prByPath[path]
+		result := map[string]any{
+			"path":       path,
+			"changeType": fileEntry["changeType"],
+			"baseExists": baseExists,
+			"prExists":   prExists,
+			"generated":  fileclass.Classify(path, prContent).Generated,
+			"testFile":   isTestFile(path),
+		}
+
+		if err := parseErrors[path]; err != nil {
+			result["parseError"] = err.Error()
+			result["symbols"] = []map[string]any{}
+			results = append(results, result)
+			continue
+		}
+		symbolChanges := changesByPath[path]
+		entries := make([]map[string]any, 0, len(symbolChanges))
+		breaking := 0
+		for _, change := range symbolChanges {
+			totals[change.Change]++
+			if change.Breaking {
+				breaking++
+			}
+			entries = append(entries, change.ToMap())
+		}
+		totals["breaking"] += breaking
+		result["symbols"] = entries
+		result["breakingChanges"] = breaking
+		results = append(results, result)
+	}
+
+	return map[string]any{
+		"pullRequestId": pullRequestID,
+		"iterationId":   iterationID,
+		"sourceBranch":  sourceBranch,
+		"targetBranch":  targetBranch,
+		"summary": map[string]any{
+			"files":    len(results),
+			"added":    totals[ChangeAdded],
+			"removed":  totals[ChangeRemoved],
+			"modified": totals[ChangeModified],
+			"breaking": totals["breaking"],
+		},
+		"contentFetch": fetchStats.ToMap(),
+		"files":        results,
+	}, nil
+}
+
+func packageKeyOf(filePath string) string {
+	key := path.Dir(filePath)
+	if isTestFile(filePath) {
+		key += " (test)"
+	}
+	return key
+}
+
+func ValidateInputs(organization, project, repositoryID, pullRequestID, iterationID string) error {
+	if strings.TrimSpace(organization) == "" || strings.TrimSpace(project) == "" || strings.TrimSpace(repositoryID) == "" || strings.TrimSpace(pullRequestID) == "" || strings.TrimSpace(iterationID) == "" {
+		return fmt.Errorf("organization, project, repositoryId, pullRequestId and iterationId are required")
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package symboldiff
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/symbols"
+)
+
+const (
+	KindFunction = "function"
+	KindMethod   = "method"
+	KindType     = "type"
+	KindConst    = "const"
+	KindVar      = "var"
+
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+type Declaration struct {
+	File         string
+	Name         string
+	Kind         string
+	Exported     bool
+	Signature    string
+	APISignature string
+	Source       string
+	StartLine    int
+	EndLine      int
+}
+
+type Change struct {
+	File             string
+	Name             string
+	Kind             string
+	Change           string
+	Exported         bool
+	SignatureChanged bool
+	Breaking         bool
+	Base             *Declaration
+	PR               *Declaration
+}
+
+// SourceFile is one changed file of a package, with its base and PR content ("" when absent).
+type SourceFile struct {
+	Path string
+	Base string
+	PR   string
+}
+
+// ParseGoDeclarations indexes the top-level functions, methods, types, constants and variables of a Go file.
+// Methods are keyed as Receiver.Name; source is kept as a token stream so formatting and comments are ignored.
+// init functions and blank (_) declarations may repeat within a package, so they are keyed by file and position.
+func ParseGoDeclarations(filePath, content string) (map[string]Declaration, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	source := func(node ast.Node) string {
+		return tokenStream(fileSet, []byte(content), node)
+	}
+	declarations := map[string]Declaration{}
+	fileScoped := 0
+	add := func(declaration Declaration) {
+		declaration.File = filePath
+		key := declaration.Kind + ":" + declaration.Name
+		if declaration.Kind == KindFunction && declaration.Name == "init" || declaration.Name == "_" {
+			fileScoped++
+			key = fmt.Sprintf("%s@%s#%d", key, filePath, fileScoped)
+		}
+		declarations[key] = declaration
+	}
+	for _, decl := range file.Decls {
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			declaration := Declaration{
+				Name:         typed.Name.Name,
+				Kind:         KindFunction,
+				Exported:     ast.IsExported(typed.Name.Name),
+				Signature:    funcSignature(fileSet, typed, true),
+				APISignature: funcSignature(fileSet, typed, false),
+				Source:       source(typed),
+				StartLine:    fileSet.Position(typed.Pos()).Line,
+				EndLine:      fileSet.Position(typed.End()).Line,
+			}
+			if receiver := symbols.ReceiverTypeName(typed); receiver != "" {
+				declaration.Name = receiver + "." + typed.Name.Name
+				declaration.Kind = KindMethod
+				declaration.Exported = declaration.Exported && ast.IsExported(receiver)
+			}
+			add(declaration)
+		case *ast.GenDecl:
+			for _, spec := range typed.Specs {
+				for _, declaration := range specDeclarations(fileSet, typed, spec, source) {
+					add(declaration)
+				}
+			}
+		}
+	}
+	return declarations, nil
+}
+
+// CompareGo reports declarations added, removed or modified between two versions of a Go file.
+// A change is breaking when an exported declaration is removed or its API signature changes.
+func CompareGo(filePath, baseContent, prContent string) ([]Change, error) {
+	changes, parseErrors := CompareGoPackage([]SourceFile{{Path: filePath, Base: baseContent, PR: prContent}})
+	if err := parseErrors[filePath]; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// CompareGoPackage compares the changed files of one package as a whole, so a declaration moved
+// between two of them is matched by name instead of being reported as removed and added. Files
+// that fail to parse on either side are left out and returned in the error map.
+func CompareGoPackage(sources []SourceFile) ([]Change, map[string]error) {
+	base := map[string]Declaration{}
+	pr := map[string]Declaration{}
+	parseErrors := map[string]error{}
+	for _, source := range sources {
+		baseDecls, err := parseOptional(source.Path, source.Base)
+		if err != nil {
+			parseErrors[source.Path] = err
+			continue
+		}
+		prDecls, err := parseOptional(source.Path, source.PR)
+		if err != nil {
+			parseErrors[source.Path] = err
+			continue
+		}
+		for key, declaration := range baseDecls {
+			base[key] = declaration
+		}
+		for key, declaration := range prDecls {
+			pr[key] = declaration
+		}
+	}
+	return compareDeclarations(base, pr), parseErrors
+}
+
+func parseOptional(filePath, content string) (map[string]Declaration, error) {
+	if content == "" {
+		return map[string]Declaration{}, nil
+	}
+	return ParseGoDeclarations(filePath, content)
+}
+
+func compareDeclarations(base, pr map[string]Declaration) []Change {
+	changes := make([]Change, 0)
+	for key, baseDecl := range base {
+		baseDecl := baseDecl
+		prDecl, exists := pr[key]
+		if !exists {
+			changes = append(changes, Change{
+				File:     baseDecl.File,
+				Name:     baseDecl.Name,
+				Kind:     baseDecl.Kind,
+				Change:   ChangeRemoved,
+				Exported: baseDecl.Exported,
+				Breaking: baseDecl.Exported && !isTestFile(baseDecl.File),
+				Base:     &baseDecl,
+			})
+			continue
+		}
+		if baseDecl.Source == prDecl.Source {
+			continue
+		}
+		signatureChanged := baseDecl.APISignature != prDecl.APISignature
+		exported := baseDecl.Exported || prDecl.Exported
+		changes = append(changes, Change{
+			File:             prDecl.File,
+			Name:             prDecl.Name,
+			Kind:             prDecl.Kind,
+			Change:           ChangeModified,
+			Exported:         exported,
+			SignatureChanged: signatureChanged,
+			Breaking:         exported && signatureChanged && !isTestFile(prDecl.File),
+			Base:             &baseDecl,
+			PR:               &prDecl,
+		})
+	}
+	for key, prDecl := range pr {
+		prDecl := prDecl
+		if _, exists := base[key]; exists {
+			continue
+		}
+		changes = append(changes, Change{File: prDecl.File, Name: prDecl.Name, Kind: prDecl.Kind, Change: ChangeAdded, Exported: prDecl.Exported, PR: &prDecl})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].File < changes[j].File
+	})
+	return changes
+}
+
+func isTestFile(filePath string) bool {
+	return strings.HasSuffix(filePath, "_test.go")
+}
+
+func (c Change) ToMap() map[string]any {
+	entry := map[string]any{
+		"name":             c.Name,
+		"kind":             c.Kind,
+		"change":           c.Change,
+		"exported":         c.Exported,
+		"signatureChanged": c.SignatureChanged,
+		"breaking":         c.Breaking,
+	}
+	if c.Base != nil {
+		entry["baseSignature"] = c.Base.Signature
+		entry["baseLines"] = map[string]any{"start": c.Base.StartLine, "end": c.Base.EndLine}
+	}
+	if c.PR != nil {
+		entry["prSignature"] = c.PR.Signature
+		entry["prLines"] = map[string]any{"start": c.PR.StartLine, "end": c.PR.EndLine}
+	}
+	if c.Base != nil && c.PR != nil && c.Base.File != c.PR.File {
+		entry["movedFrom"] = c.Base.File
+	}
+	return entry
+}
+
+func specDeclarations(fileSet *token.FileSet, decl *ast.GenDecl, spec ast.Spec, source func(ast.Node) string) []Declaration {
+	var node ast.Node = spec
+	if !decl.Lparen.IsValid() {
+		node = decl
+	}
+	startLine := fileSet.Position(node.Pos()).Line
+	endLine := fileSet.Position(node.End()).Line
+
+	switch typed := spec.(type) {
+	case *ast.TypeSpec:
+		return []Declaration{{
+			Name:         typed.Name.Name,
+			Kind:         KindType,
+			Exported:     ast.IsExported(typed.Name.Name),
+			Signature:    "type " + typed.Name.Name + typeParams(fileSet, typed) + " " + typeSummary(typed.Type),
+			APISignature: typeParams(fileSet, typed) + typeAPI(fileSet, typed),
+			Source:       source(typed),
+			StartLine:    startLine,
+			EndLine:      endLine,
+		}}
+	case *ast.ValueSpec:
+		kind := KindVar
+		if decl.Tok == token.CONST {
+			kind = KindConst
+		}
+		valueType := ""
+		if typed.Type != nil {
+			valueType = " " + printNode(fileSet, typed.Type)
+		}
+		valueSource := source(typed)
+		declarations := make([]Declaration, 0, len(typed.Names))
+		for _, name := range typed.Names {
+			if name.Name == "_" {
+				continue
+			}
+			declarations = append(declarations, Declaration{
+				Name:         name.Name,
+				Kind:         kind,
+				Exported:     ast.IsExported(name.Name),
+				Signature:    kind + " " + name.Name + valueType,
+				APISignature: kind + valueType,
+				Source:       valueSource,
+				StartLine:    startLine,
+				EndLine:      endLine,
+			})
+		}
+		return declarations
+	default:
+		return nil
+	}
+}
+
+// funcSignature prints a function header. With names=false parameter and receiver names are dropped,
+// so renaming a parameter does not count as an API change.
+func funcSignature(fileSet *token.FileSet, decl *ast.FuncDecl, names bool) string {
+	var builder strings.Builder
+	builder.WriteString("func ")
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		builder.WriteString("(" + fieldList(fileSet, decl.Recv, names) + ") ")
+	}
+	builder.WriteString(decl.Name.Name)
+	if decl.Type.TypeParams != nil {
+		builder.WriteString("[" + fieldList(fileSet, decl.Type.TypeParams, true) + "]")
+	}
+	builder.WriteString("(" + fieldList(fileSet, decl.Type.Params, names) + ")")
+	if results := decl.Type.Results; results != nil && len(results.List) > 0 {
+		rendered := fieldList(fileSet, results, names)
+		if len(results.List) == 1 && (len(results.List[0].Names) == 0 || !names) && !strings.Contains(rendered, ",") {
+			builder.WriteString(" " + rendered)
+		} else {
+			builder.WriteString(" (" + rendered + ")")
+		}
+	}
+	return builder.String()
+}
+
+func fieldList(fileSet *token.FileSet, fields *ast.FieldList, names bool) string {
+	if fields == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(fields.List))
+	for _, field := range fields.List {
+		fieldType := printNode(fileSet, field.Type)
+		if len(field.Names) == 0 {
+			parts = append(parts, fieldType)
+			continue
+		}
+		if !names {
+			for range field.Names {
+				parts = append(parts, fieldType)
+			}
+			continue
+		}
+		fieldNames := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			fieldNames = append(fieldNames, name.Name)
+		}
+		parts = append(parts, strings.Join(fieldNames, ", ")+" "+fieldType)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func typeParams(fileSet *token.FileSet, spec *ast.TypeSpec) string {
+	if spec.TypeParams == nil {
+		return ""
+	}
+	return "[" + fieldList(fileSet, spec.TypeParams, true) + "]"
+}
+
+func typeSummary(expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	default:
+		return ""
+	}
+}
+
+// typeAPI describes what other packages can observe of a type: exported struct fields,
+// interface methods, or the full type expression for anything else.
+func typeAPI(fileSet *token.FileSet, spec *ast.TypeSpec) string {
+	prefix := ""
+	if spec.Assign.IsValid() {
+		prefix = "= "
+	}
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return prefix + printNode(fileSet, spec.Type)
+	}
+	fields := make([]string, 0)
+	for _, field := range structType.Fields.List {
+		fieldType := printNode(fileSet, field.Type)
+		if len(field.Names) == 0 {
+			fields = append(fields, "embedded "+fieldType)
+			continue
+		}
+		for _, name := range field.Names {
+			if ast.IsExported(name.Name) {
+				fields = append(fields, name.Name+" "+fieldType)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return prefix + "struct{" + strings.Join(fields, "; ") + "}"
+}
+
+func printNode(fileSet *token.FileSet, node any) string {
+	var buffer bytes.Buffer
+	if err := printer.Fprint(&buffer, fileSet, node); err != nil {
+		return ""
+	}
+	return buffer.String()
+}
+
+// tokenStream renders the tokens of a node separated by single spaces, skipping comments and automatic semicolons.
+func tokenStream(fileSet *token.FileSet, content []byte, node ast.Node) string {
+	start := fileSet.Position(node.Pos()).Offset
+	end := fileSet.Position(node.End()).Offset
+	if start < 0 || end > len(content) || start > end {
+		return ""
+	}
+	segment := content[start:end]
+	segmentSet := token.NewFileSet()
+	var tokenScanner scanner.Scanner
+	tokenScanner.Init(segmentSet.AddFile("", -1, len(segment)), segment, nil, 0)
+
+	parts := make([]string, 0)
+	for {
+		_, tok, literal := tokenScanner.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && literal == "\n" {
+			continue
+		}
+		if literal != "" {
+			parts = append(parts, literal)
+		} else {
+			parts = append(parts, tok.String())
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,173 @@
+package symboldiff
+
+import "testing"
+
+func changeByName(changes []Change, name string) (Change, bool) {
+	for _, change := range changes {
+		if change.Name == name {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+func TestCompareGo_ReportsAddedRemovedModified(t *testing.T) {
+	base := `package store
+
+type Store struct {
+	Items map[string]int
+	hits  int
+}
+
+func (s *Store) Get(key string) int {
+	return s.Items[key]
+}
+
+func Remove(key string) {}
+
+func helper() int { return 1 }
+`
+	pr := `package store
+
+type Store struct {
+	Items map[string]int
+	hits  int64
+}
+
+func (s *Store) Get(key string, fallback int) int {
+	if v, ok := s.Items[key]; ok {
+		return v
+	}
+	return fallback
+}
+
+func helper() int { return 2 }
+
+func New() *Store { return &Store{} }
+`
+	changes, err := CompareGo("/store/store.go", base, pr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	get, ok := changeByName(changes, "Store.Get")
+	if !ok || get.Change != ChangeModified || get.Kind != KindMethod || !get.SignatureChanged || !get.Breaking {
+		t.Fatalf("unexpected Store.Get change: %+v", get)
+	}
+	if removed, ok := changeByName(changes, "Remove"); !ok || removed.Change != ChangeRemoved || !removed.Breaking {
+		t.Fatalf("unexpected Remove change: %+v", removed)
+	}
+	if added, ok := changeByName(changes, "New"); !ok || added.Change != ChangeAdded || added.Breaking || !added.Exported {
+		t.Fatalf("unexpected New change: %+v", added)
+	}
+	if helper, ok := changeByName(changes, "helper"); !ok || helper.Change != ChangeModified || helper.Breaking || helper.SignatureChanged {
+		t.Fatalf("unexpected helper change: %+v", helper)
+	}
+	if store, ok := changeByName(changes, "Store"); !ok || store.Change != ChangeModified || store.SignatureChanged || store.Breaking {
+		t.Fatalf("expected unexported field change to leave the API intact: %+v", store)
+	}
+}
+
+func TestCompareGo_IgnoresFormattingAndParameterRenames(t *testing.T) {
+	base := "package p\n\nfunc Sum(a, b int) int { return a + b }\n"
+	pr := "package p\n\n// Sum adds.\nfunc Sum(x, y int) int {\n\treturn x + y\n}\n"
+	changes, err := CompareGo("/p/sum.go", base, pr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].SignatureChanged || changes[0].Breaking {
+		t.Fatalf("expected a non-breaking body change, got %+v", changes)
+	}
+
+	changes, _ = CompareGo("/p/sum.go", base, "package p\n\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n")
+	if len(changes) != 0 {
+		t.Fatalf("expected formatting-only change to be ignored, got %+v", changes)
+	}
+}
+
+func TestCompareGo_ParseError(t *testing.T) {
+	if _, err := CompareGo("/p/bad.go", "package p\n", "package p\nfunc {"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestParseGoDeclarations_SignatureText(t *testing.T) {
+	declarations, err := ParseGoDeclarations("/p/x.go", "package p\n\nfunc (c *client) Do(ctx context.Context, n int) (string, error) { return \"\", nil }\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	do := declarations["method:client.Do"]
+	if do.Signature != "func (c *client) Do(ctx context.Context, n int) (string, error)" || do.Exported {
+		t.Fatalf("unexpected declaration: %+v", do)
+	}
+	if do.APISignature != "func (*client) Do(context.Context, int) (string, error)" {
+		t.Fatalf("unexpected API signature: %q", do.APISignature)
+	}
+}
+
+func TestCompareGoPackage_MatchesDeclarationsMovedBetweenFiles(t *testing.T) {
+	sources := []SourceFile{
+		{
+			Path: "/store/store.go",
+			Base: "package store\n\nfunc Open() error { return nil }\n\nfunc Close() {}\n\nfunc init() { register(\"a\") }\n",
+			PR:   "package store\n\nfunc Close() {}\n\nfunc init() { register(\"b\") }\n",
+		},
+		{
+			Path: "/store/open.go",
+			Base: "package store\n\nfunc init() { register(\"c\") }\n",
+			PR:   "package store\n\nfunc Open() error { return nil }\n\nfunc Reopen(name string) error { return nil }\n\nfunc init() { register(\"c\") }\n",
+		},
+	}
+	changes, parseErrors := CompareGoPackage(sources)
+	if len(parseErrors) != 0 {
+		t.Fatalf("unexpected parse errors: %v", parseErrors)
+	}
+	if _, ok := changeByName(changes, "Open"); ok {
+		t.Fatalf("expected a moved, unchanged declaration not to be reported: %+v", changes)
+	}
+	if reopen, ok := changeByName(changes, "Reopen"); !ok || reopen.Change != ChangeAdded || reopen.File != "/store/open.go" {
+		t.Fatalf("unexpected Reopen change: %+v", reopen)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected Reopen and one init change, got %+v", changes)
+	}
+	if initChange, ok := changeByName(changes, "init"); !ok || initChange.Change != ChangeModified || initChange.File != "/store/store.go" {
+		t.Fatalf("expected init functions to be compared per file, got %+v", initChange)
+	}
+}
+
+func TestCompareGoPackage_ReportsMovedModifiedDeclaration(t *testing.T) {
+	sources := []SourceFile{
+		{Path: "/p/a.go", Base: "package p\n\nfunc Run(n int) {}\n", PR: "package p\n"},
+		{Path: "/p/b.go", Base: "package p\n", PR: "package p\n\nfunc Run(n int64) {}\n"},
+		{Path: "/p/bad.go", Base: "package p\n", PR: "package p\nfunc {"},
+	}
+	changes, parseErrors := CompareGoPackage(sources)
+	if parseErrors["/p/bad.go"] == nil || len(parseErrors) != 1 {
+		t.Fatalf("expected a parse error for bad.go only, got %v", parseErrors)
+	}
+	if len(changes) != 1 || changes[0].Change != ChangeModified || !changes[0].Breaking || changes[0].File != "/p/b.go" {
+		t.Fatalf("expected one breaking modification in b.go, got %+v", changes)
+	}
+	if entry := changes[0].ToMap(); entry["movedFrom"] != "/p/a.go" {
+		t.Fatalf("expected movedFrom in the change entry, got %v", entry)
+	}
+}
+
+func TestParseGoDeclarations_KeysInitAndBlankPerFile(t *testing.T) {
+	declarations, err := ParseGoDeclarations("/p/x.go", "package p\n\nfunc init() {}\n\nfunc init() {}\n\nfunc _() {}\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, key := range []string{"function:init@/p/x.go#1", "function:init@/p/x.go#2", "function:_@/p/x.go#3"} {
+		if _, ok := declarations[key]; !ok {
+			t.Fatalf("expected key %q in %v", key, declarations)
+		}
+	}
+}
+
+func TestPackageKeyOf_SeparatesTestFiles(t *testing.T) {
+	if packageKeyOf("/p/a.go") != packageKeyOf("/p/b.go") || packageKeyOf("/p/a.go") == packageKeyOf("/p/a_test.go") || packageKeyOf("/p/a.go") == packageKeyOf("/q/a.go") {
+		t.Fatal("expected files to be grouped by directory, with test files apart")
+	}
+}
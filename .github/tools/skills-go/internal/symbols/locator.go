@@ -0,0 +1,52 @@
+package symbols
+
+import "ado-reviewer/.github/tools/skills-go/internal/linediff"
+
+const (
+	SideBase = "base"
+	SidePR   = "pr"
+)
+
+type Locator struct {
+	filePath   string
+	oldContent string
+	newContent string
+	oldOutline []Symbol
+	newOutline []Symbol
+}
+
+func NewLocator(filePath, oldContent, newContent string) *Locator {
+	return &Locator{filePath: filePath, oldContent: oldContent, newContent: newContent}
+}
+
+// Locate resolves the symbol around the first added line of a hunk in the PR version,
+// or around the first deleted line in the base version for deletion-only hunks.
+func (l *Locator) Locate(hunk linediff.Hunk) (Symbol, string, bool) {
+	for _, line := range hunk.Lines {
+		if line.Kind == linediff.KindAdded {
+			if l.newOutline == nil {
+				l.newOutline = Outline(l.filePath, l.newContent)
+			}
+			symbol, ok := Enclosing(l.newOutline, line.NewLine)
+			return symbol, SidePR, ok
+		}
+	}
+	for _, line := range hunk.Lines {
+		if line.Kind == linediff.KindDeleted {
+			if l.oldOutline == nil {
+				l.oldOutline = Outline(l.filePath, l.oldContent)
+			}
+			symbol, ok := Enclosing(l.oldOutline, line.OldLine)
+			return symbol, SideBase, ok
+		}
+	}
+	return Symbol{}, "", false
+}
+
+// Content returns the file version a side returned by Locate refers to.
+func (l *Locator) Content(side string) string {
+	if side == SideBase {
+		return l.oldContent
+	}
+	return l.newContent
+}
@@ -0,0 +1,359 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"ado-reviewer/.github/tools/skills-go/internal/fileclass"
+)
+
+const (
+	KindFunction  = "function"
+	KindMethod    = "method"
+	KindType      = "type"
+	KindClass     = "class"
+	KindInterface = "interface"
+	KindStruct    = "struct"
+	KindEnum      = "enum"
+	KindRecord    = "record"
+	KindNamespace = "namespace"
+
+	maxBraceScanLines = 400
+)
+
+type Symbol struct {
+	Name      string
+	Kind      string
+	StartLine int
+	EndLine   int
+}
+
+var (
+	pythonDefPattern     = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+([A-Za-z_]\w*)`)
+	pythonClassPattern   = regexp.MustCompile(`^(\s*)class\s+([A-Za-z_]\w*)`)
+	braceTypePattern     = regexp.MustCompile(`\b(class|interface|struct|enum|record|namespace)\s+([A-Za-z_$][\w$.]*)`)
+	braceFunctionPattern = regexp.MustCompile(`\bfunction\s*\*?\s*([A-Za-z_$][\w$]*)\s*[<(]`)
+	braceArrowPattern    = regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=>`)
+	braceMethodPattern   = regexp.MustCompile(`^\s*((?:[\w$\[\]<>?,.@]+\s+)*?)([A-Za-z_$][\w$]*)\s*(?:<[^()]*>)?\s*\(`)
+)
+
+var nonDeclarationWords = map[string]bool{
+	"if": true, "for": true, "foreach": true, "while": true, "switch": true, "catch": true, "using": true, "lock": true,
+	"return": true, "new": true, "await": true, "throw": true, "else": true, "case": true, "yield": true, "typeof": true,
+	"sizeof": true, "nameof": true, "do": true, "try": true, "fixed": true, "synchronized": true, "when": true, "super": true, "this": true,
+}
+
+// Outline lists the functions, methods and types declared in content.
+// Go files are parsed with go/parser; C#, Java, JavaScript and TypeScript use brace matching and Python uses indentation.
+func Outline(filePath, content string) []Symbol {
+	if content == "" {
+		return []Symbol{}
+	}
+	switch fileclass.ClassifyPath(filePath).Language {
+	case "go":
+		if outline, ok := goOutline(filePath, content); ok {
+			return outline
+		}
+		return braceOutline(content)
+	case "python":
+		return pythonOutline(content)
+	case "csharp", "java", "javascript", "typescript", "kotlin":
+		return braceOutline(content)
+	default:
+		return []Symbol{}
+	}
+}
+
+// Enclosing returns the innermost symbol whose range contains line.
+func Enclosing(outline []Symbol, line int) (Symbol, bool) {
+	best := Symbol{}
+	found := false
+	for _, symbol := range outline {
+		if line < symbol.StartLine || line > symbol.EndLine {
+			continue
+		}
+		if !found || symbol.EndLine-symbol.StartLine < best.EndLine-best.StartLine {
+			best = symbol
+			found = true
+		}
+	}
+	return best, found
+}
+
+func Body(content string, symbol Symbol) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if symbol.StartLine < 1 || symbol.StartLine > len(lines) {
+		return ""
+	}
+	end := symbol.EndLine
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[symbol.StartLine-1:end], "\n") + "\n"
+}
+
+func (s Symbol) ToMap() map[string]any {
+	return map[string]any{
+		"name":      s.Name,
+		"kind":      s.Kind,
+		"startLine": s.StartLine,
+		"endLine":   s.EndLine,
+	}
+}
+
+func goOutline(filePath, content string) ([]Symbol, bool) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+
+	outline := make([]Symbol, 0)
+	for _, decl := range file.Decls {
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := Symbol{
+				Name:      typed.Name.Name,
+				Kind:      KindFunction,
+				StartLine: fileSet.Position(typed.Pos()).Line,
+				EndLine:   fileSet.Position(typed.End()).Line,
+			}
+			if receiver := ReceiverTypeName(typed); receiver != "" {
+				symbol.Name = receiver + "." + typed.Name.Name
+				symbol.Kind = KindMethod
+			}
+			outline = append(outline, symbol)
+		case *ast.GenDecl:
+			if typed.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range typed.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				var node ast.Node = typeSpec
+				if !typed.Lparen.IsValid() {
+					node = typed
+				}
+				outline = append(outline, Symbol{
+					Name:      typeSpec.Name.Name,
+					Kind:      KindType,
+					StartLine: fileSet.Position(node.Pos()).Line,
+					EndLine:   fileSet.Position(node.End()).Line,
+				})
+			}
+		}
+	}
+	return outline, true
+}
+
+// ReceiverTypeName returns the receiver type of a method without pointer or type parameters, or "" for plain functions.
+func ReceiverTypeName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	expr := decl.Recv.List[0].Type
+	for {
+		switch typed := expr.(type) {
+		case *ast.StarExpr:
+			expr = typed.X
+		case *ast.IndexExpr:
+			expr = typed.X
+		case *ast.IndexListExpr:
+			expr = typed.X
+		case *ast.ParenExpr:
+			expr = typed.X
+		case *ast.Ident:
+			return typed.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func pythonOutline(content string) []Symbol {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	outline := make([]Symbol, 0)
+	for index, line := range lines {
+		kind := KindFunction
+		match := pythonDefPattern.FindStringSubmatch(line)
+		if match == nil {
+			kind = KindClass
+			match = pythonClassPattern.FindStringSubmatch(line)
+		}
+		if match == nil {
+			continue
+		}
+		indent := indentWidth(match[1])
+		end := index
+		for next := index + 1; next < len(lines); next++ {
+			trimmed := strings.TrimSpace(lines[next])
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if indentWidth(lines[next]) <= indent && !strings.HasPrefix(trimmed, ")") {
+				break
+			}
+			end = next
+		}
+		outline = append(outline, Symbol{Name: match[2], Kind: kind, StartLine: index + 1, EndLine: end + 1})
+	}
+	return qualifyMembers(outline)
+}
+
+func indentWidth(line string) int {
+	width := 0
+	for _, char := range line {
+		switch char {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+func braceOutline(content string) []Symbol {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	outline := make([]Symbol, 0)
+	for index, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*") {
+			continue
+		}
+
+		name, kind, column := braceDeclaration(line)
+		if name == "" {
+			continue
+		}
+		end, ok := matchBraces(lines, index, column)
+		if !ok {
+			continue
+		}
+		outline = append(outline, Symbol{Name: name, Kind: kind, StartLine: index + 1, EndLine: end + 1})
+	}
+	return qualifyMembers(outline)
+}
+
+func braceDeclaration(line string) (string, string, int) {
+	if match := braceTypePattern.FindStringSubmatchIndex(line); match != nil {
+		return line[match[4]:match[5]], line[match[2]:match[3]], match[1]
+	}
+	if match := braceFunctionPattern.FindStringSubmatchIndex(line); match != nil {
+		return line[match[2]:match[3]], KindFunction, match[1]
+	}
+	if match := braceArrowPattern.FindStringSubmatchIndex(line); match != nil {
+		return line[match[2]:match[3]], KindFunction, match[1]
+	}
+	if match := braceMethodPattern.FindStringSubmatchIndex(line); match != nil {
+		name := line[match[4]:match[5]]
+		if nonDeclarationWords[name] || (match[4] > 0 && line[match[4]-1] == '@') {
+			return "", "", 0
+		}
+		for _, word := range strings.Fields(line[match[2]:match[3]]) {
+			if nonDeclarationWords[word] {
+				return "", "", 0
+			}
+		}
+		return name, KindFunction, match[1]
+	}
+	return "", "", 0
+}
+
+// matchBraces finds the line closing the first brace block that starts at or after column on line start.
+// A ';' before the opening brace means the match was a statement or abstract declaration, not a body.
+func matchBraces(lines []string, start, column int) (int, bool) {
+	depth := 0
+	opened := false
+	inBlockComment := false
+	last := start + maxBraceScanLines
+	if last > len(lines) {
+		last = len(lines)
+	}
+	for index := start; index < last; index++ {
+		line := lines[index]
+		position := 0
+		if index == start {
+			position = column
+		}
+		var quote byte
+		for ; position < len(line); position++ {
+			char := line[position]
+			if inBlockComment {
+				if char == '*' && position+1 < len(line) && line[position+1] == '/' {
+					inBlockComment = false
+					position++
+				}
+				continue
+			}
+			if quote != 0 {
+				if char == '\\' {
+					position++
+				} else if char == quote {
+					quote = 0
+				}
+				continue
+			}
+			switch char {
+			case '"', '\'', '`':
+				quote = char
+			case '/':
+				if position+1 < len(line) && line[position+1] == '/' {
+					position = len(line)
+				} else if position+1 < len(line) && line[position+1] == '*' {
+					inBlockComment = true
+					position++
+				}
+			case ';':
+				if !opened {
+					return 0, false
+				}
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+				if opened && depth == 0 {
+					return index, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// qualifyMembers prefixes each symbol with the names of the types that contain it, so a method of
+// a nested class becomes Outer.Inner.Method. Names are built from the original outline into a new
+// slice, so the result does not depend on the order of the symbols.
+func qualifyMembers(outline []Symbol) []Symbol {
+	containers := make([]int, len(outline))
+	for index, symbol := range outline {
+		containers[index] = -1
+		for candidate, other := range outline {
+			if candidate == index || other.Kind == KindFunction || other.Kind == KindMethod || other.Kind == KindNamespace {
+				continue
+			}
+			if other.StartLine <= symbol.StartLine && other.EndLine >= symbol.EndLine && (other.StartLine != symbol.StartLine || other.EndLine != symbol.EndLine) {
+				if current := containers[index]; current < 0 || other.EndLine-other.StartLine < outline[current].EndLine-outline[current].StartLine {
+					containers[index] = candidate
+				}
+			}
+		}
+	}
+
+	qualified := make([]Symbol, len(outline))
+	for index, symbol := range outline {
+		if containers[index] >= 0 && symbol.Kind == KindFunction {
+			symbol.Kind = KindMethod
+		}
+		for container := containers[index]; container >= 0; container = containers[container] {
+			symbol.Name = outline[container].Name + "." + symbol.Name
+		}
+		qualified[index] = symbol
+	}
+	return qualified
+}
@@ -0,0 +1,118 @@
+package symbols
+
+import "testing"
+
+func findSymbol(t *testing.T, outline []Symbol, line int) Symbol {
+	t.Helper()
+	symbol, ok := Enclosing(outline, line)
+	if !ok {
+		t.Fatalf("expected a symbol enclosing line %d in %#v", line, outline)
+	}
+	return symbol
+}
+
+func TestOutline_Go(t *testing.T) {
+	content := `package sample
+
+type Store struct {
+	items map[string]int
+}
+
+func (s *Store) Get(key string) int {
+	return s.items[key]
+}
+
+func helper() {
+}
+`
+	outline := Outline("/pkg/store.go", content)
+	if symbol := findSymbol(t, outline, 8); symbol.Name != "Store.Get" || symbol.Kind != KindMethod || symbol.StartLine != 7 || symbol.EndLine != 9 {
+		t.Fatalf("unexpected method symbol: %+v", symbol)
+	}
+	if symbol := findSymbol(t, outline, 4); symbol.Name != "Store" || symbol.Kind != KindType {
+		t.Fatalf("unexpected type symbol: %+v", symbol)
+	}
+	if _, ok := Enclosing(outline, 10); ok {
+		t.Fatalf("expected no symbol between declarations")
+	}
+}
+
+func TestOutline_CSharp(t *testing.T) {
+	content := `namespace Billing
+{
+    public class InvoiceService
+    {
+        [HttpGet("x")]
+        public async Task<int> Total(int id)
+        {
+            if (id > 0)
+            {
+                return Compute(id);
+            }
+            return 0;
+        }
+
+        public abstract void Skip();
+    }
+}
+`
+	outline := Outline("/src/InvoiceService.cs", content)
+	if symbol := findSymbol(t, outline, 10); symbol.Name != "InvoiceService.Total" || symbol.Kind != KindMethod || symbol.StartLine != 6 || symbol.EndLine != 13 {
+		t.Fatalf("unexpected method symbol: %+v", symbol)
+	}
+	if symbol := findSymbol(t, outline, 15); symbol.Name != "InvoiceService" || symbol.Kind != KindClass {
+		t.Fatalf("expected abstract member line to resolve to the class: %+v", symbol)
+	}
+}
+
+func TestOutline_TypeScript(t *testing.T) {
+	content := "export class Cart {\n  add(item: Item): void {\n    const label = \"{\";\n    this.items.push(item);\n  }\n}\n\nexport const total = (items: Item[]) => {\n  return items.length;\n};\n"
+	outline := Outline("/src/cart.ts", content)
+	if symbol := findSymbol(t, outline, 4); symbol.Name != "Cart.add" || symbol.EndLine != 5 {
+		t.Fatalf("unexpected method symbol: %+v", symbol)
+	}
+	if symbol := findSymbol(t, outline, 9); symbol.Name != "total" || symbol.Kind != KindFunction {
+		t.Fatalf("unexpected arrow function symbol: %+v", symbol)
+	}
+}
+
+func TestOutline_Python(t *testing.T) {
+	content := "class Repo:\n    def load(self, key):\n        value = self.cache.get(key)\n\n        return value\n\n    def save(self):\n        pass\n\ndef main():\n    Repo().load(1)\n"
+	outline := Outline("/app/repo.py", content)
+	if symbol := findSymbol(t, outline, 5); symbol.Name != "Repo.load" || symbol.Kind != KindMethod || symbol.EndLine != 5 {
+		t.Fatalf("unexpected method symbol: %+v", symbol)
+	}
+	if symbol := findSymbol(t, outline, 11); symbol.Name != "main" || symbol.Kind != KindFunction {
+		t.Fatalf("unexpected function symbol: %+v", symbol)
+	}
+}
+
+func TestQualifyMembers_NestedTypesInAnyOrder(t *testing.T) {
+	outer := Symbol{Name: "Outer", Kind: KindClass, StartLine: 1, EndLine: 20}
+	inner := Symbol{Name: "Inner", Kind: KindClass, StartLine: 3, EndLine: 10}
+	method := Symbol{Name: "Run", Kind: KindFunction, StartLine: 4, EndLine: 6}
+	want := map[int]string{1: "Outer", 3: "Outer.Inner", 4: "Outer.Inner.Run"}
+
+	for _, outline := range [][]Symbol{{outer, inner, method}, {method, inner, outer}, {inner, method, outer}} {
+		original := append([]Symbol(nil), outline...)
+		for _, symbol := range qualifyMembers(outline) {
+			if symbol.Name != want[symbol.StartLine] {
+				t.Fatalf("expected %q, got %q for outline %v", want[symbol.StartLine], symbol.Name, original)
+			}
+			if symbol.StartLine == 4 && symbol.Kind != KindMethod {
+				t.Fatalf("expected the nested function to become a method, got %+v", symbol)
+			}
+		}
+		for index := range outline {
+			if outline[index] != original[index] {
+				t.Fatalf("expected the input outline to stay unchanged, got %v", outline)
+			}
+		}
+	}
+}
+
+func TestBody_ReturnsSymbolLines(t *testing.T) {
+	if body := Body("a\nb\nc\n", Symbol{StartLine: 2, EndLine: 3}); body != "b\nc\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}